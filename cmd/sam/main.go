@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 
 	"github.com/sergekukharev/agent-samwise/internal/capability"
@@ -18,29 +19,395 @@ func main() {
 func capabilities() []cli.Capability {
 	return []cli.Capability{
 		calendarSync(),
+		taskDigest(),
+		capacityCheck(),
+		weeklyReview(),
+		dailyRecap(),
+		emailTriage(),
+		emailTasks(),
+		followUps(),
+		invitations(),
+		newsletterDigest(),
 	}
 }
 
+// newTaskCreator returns the named task backend, typically
+// cfg.Tasks.BackendName() or cfg.Tasks.CalendarBackendName().
+func newTaskCreator(cfg config.Config, secrets config.Secrets, backend string) (platform.TaskCreator, error) {
+	switch backend {
+	case config.TaskBackendFile:
+		return platform.NewFileTaskList(cfg.Tasks.File.Path, cfg.Tasks.File.Format)
+	case config.TaskBackendGitHub:
+		return platform.NewGitHubIssuesClient(secrets.GitHubToken, cfg.Tasks.GitHub.Repo, cfg.Tasks.GitHub.Labels), nil
+	case config.TaskBackendCalDAV:
+		return platform.NewCalDAVTaskClient(cfg.Tasks.CalDAV.URL, secrets.CalDAVUsername, secrets.CalDAVPassword), nil
+	case config.TaskBackendJira:
+		return newJiraClient(cfg, secrets), nil
+	case config.TaskBackendLinear:
+		return platform.NewLinearClient(secrets.LinearAPIKey, cfg.Tasks.Linear.TeamID), nil
+	default:
+		return platform.NewTodoistClient(secrets.TodoistAPIToken), nil
+	}
+}
+
+// newIssueReaders returns a reader for each work tracker in tasks.issues.
+func newIssueReaders(cfg config.Config, secrets config.Secrets) []platform.IssueReader {
+	var readers []platform.IssueReader
+	for _, source := range cfg.Tasks.Issues {
+		switch source {
+		case config.TaskBackendJira:
+			readers = append(readers, newJiraClient(cfg, secrets))
+		case config.TaskBackendLinear:
+			readers = append(readers, platform.NewLinearClient(secrets.LinearAPIKey, cfg.Tasks.Linear.TeamID))
+		}
+	}
+	return readers
+}
+
+func newJiraClient(cfg config.Config, secrets config.Secrets) *platform.JiraClient {
+	jira := cfg.Tasks.Jira
+	return platform.NewJiraClient(jira.Site, secrets.JiraEmail, secrets.JiraAPIToken, jira.ProjectKey, jira.IssueType)
+}
+
+// mailBackend is implemented by every mail backend.
+type mailBackend interface {
+	platform.MailReader
+	platform.InvitationReader
+	platform.MessageBodyReader
+}
+
+// newMailReader returns the configured mail backend. modify requests the
+// gmail.modify scope; only the Gmail backend can change threads, so the
+// modifier is nil for IMAP.
+func newMailReader(cfg config.Config, secrets config.Secrets, modify bool) (mailBackend, platform.GmailModifier, error) {
+	if cfg.Mail.BackendName() == config.MailBackendIMAP {
+		imap := cfg.Mail.IMAP
+		return platform.NewIMAPClient(imap.Host, imap.Port, secrets.IMAPUsername, secrets.IMAPPassword, imap.SentFolder), nil, nil
+	}
+
+	newGmail := platform.NewGmailClient
+	if modify {
+		newGmail = platform.NewGmailModifier
+	}
+	gmailClient, err := newGmail(secrets.GoogleCredentials, cfg.Gmail.User)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gmailClient, gmailClient, nil
+}
+
 func calendarSync() cli.Capability {
 	return cli.Capability{
 		Name:           "calendar-sync",
 		Description:    "Sync today's calendar events to Todoist",
-		RequiredConfig: []string{"calendar", "todoist"},
-		RequiredEnv:    []string{"calendar", "todoist"},
+		RequiredConfig: []string{"calendar", "calendar-tasks"},
+		RequiredEnv:    []string{"calendar", "calendar-tasks"},
 		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
 			calendarClient, err := platform.NewGoogleCalendarClient(secrets.GoogleCredentials)
 			if err != nil {
 				return err
 			}
 
-			todoistClient := platform.NewTodoistClient(secrets.TodoistAPIToken)
+			taskCreator, err := newTaskCreator(cfg, secrets, cfg.Tasks.CalendarBackendName())
+			if err != nil {
+				return err
+			}
 
 			cs := &capability.CalendarSync{
 				Calendar: calendarClient,
-				Todoist:  todoistClient,
+				Todoist:  taskCreator,
+			}
+			// Event comments are a Todoist feature.
+			if todoistClient, ok := taskCreator.(*platform.TodoistClient); ok && cfg.Calendar.EventComments {
+				cs.Comments = todoistClient
+				cs.Tasks = todoistClient
 			}
 
 			return cs.Run(cfg, secrets, out)
 		},
 	}
 }
+
+func taskDigest() cli.Capability {
+	return cli.Capability{
+		Name:           "task-digest",
+		Description:    "Summarise overdue, due-today and high-priority Todoist tasks",
+		RequiredConfig: []string{"issues"},
+		RequiredEnv:    []string{"todoist", "issues"},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			todoistClient := platform.NewTodoistClient(secrets.TodoistAPIToken)
+
+			td := &capability.TaskDigest{
+				Todoist:  todoistClient,
+				Activity: todoistClient,
+				Issues:   newIssueReaders(cfg, secrets),
+			}
+
+			return td.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func capacityCheck() cli.Capability {
+	var apply bool
+
+	return cli.Capability{
+		Name:           "capacity-check",
+		Description:    "Compare today's task estimates with free calendar time",
+		RequiredConfig: []string{"calendar", "capacity"},
+		RequiredEnv:    []string{"calendar", "todoist"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&apply, "apply", false, "move suggested tasks to the next day with free capacity")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			calendarClient, err := platform.NewGoogleCalendarClient(secrets.GoogleCredentials)
+			if err != nil {
+				return err
+			}
+
+			todoistClient := platform.NewTodoistClient(secrets.TodoistAPIToken)
+
+			cc := &capability.CapacityCheck{
+				Calendar:    calendarClient,
+				Todoist:     todoistClient,
+				Rescheduler: todoistClient,
+				Apply:       apply,
+			}
+
+			return cc.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func weeklyReview() cli.Capability {
+	var createTask bool
+
+	return cli.Capability{
+		Name:           "weekly-review",
+		Description:    "Walk through a GTD-style weekly review checklist",
+		RequiredConfig: []string{"calendar", "tasks", "issues"},
+		RequiredEnv:    []string{"calendar", "todoist", "tasks", "issues"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&createTask, "create-task", false, "add the checklist to a recurring \"Weekly review\" task")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			calendarClient, err := platform.NewGoogleCalendarClient(secrets.GoogleCredentials)
+			if err != nil {
+				return err
+			}
+
+			todoistClient := platform.NewTodoistClient(secrets.TodoistAPIToken)
+
+			taskCreator, err := newTaskCreator(cfg, secrets, cfg.Tasks.BackendName())
+			if err != nil {
+				return err
+			}
+
+			wr := &capability.WeeklyReview{
+				Todoist:    todoistClient,
+				Completed:  todoistClient,
+				Calendar:   calendarClient,
+				Creator:    taskCreator,
+				Issues:     newIssueReaders(cfg, secrets),
+				CreateTask: createTask,
+			}
+			// Only Todoist can be searched for the review task of earlier runs.
+			if todoistCreator, ok := taskCreator.(*platform.TodoistClient); ok {
+				wr.Reviews = todoistCreator
+			}
+
+			return wr.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func dailyRecap() cli.Capability {
+	return cli.Capability{
+		Name:           "daily-recap",
+		Description:    "Recap today's completed tasks, meetings and slipped work",
+		RequiredConfig: []string{"calendar"},
+		RequiredEnv:    []string{"calendar", "todoist"},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			calendarClient, err := platform.NewGoogleCalendarClient(secrets.GoogleCredentials)
+			if err != nil {
+				return err
+			}
+
+			todoistClient := platform.NewTodoistClient(secrets.TodoistAPIToken)
+
+			dr := &capability.DailyRecap{
+				Calendar:  calendarClient,
+				Todoist:   todoistClient,
+				Completed: todoistClient,
+				Closer:    todoistClient,
+			}
+
+			return dr.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func emailTriage() cli.Capability {
+	var dryRun bool
+
+	return cli.Capability{
+		Name:           "email-triage",
+		Description:    "Bucket inbox threads into needs-reply, waiting, FYI, newsletters and notifications",
+		RequiredConfig: []string{"mail", "email-triage"},
+		RequiredEnv:    []string{"mail"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&dryRun, "dry-run", false, "list the threads email_triage.actions would change without changing them")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			mailReader, modifier, err := newMailReader(cfg, secrets, len(cfg.EmailTriage.Actions) > 0 && !dryRun)
+			if err != nil {
+				return err
+			}
+
+			et := &capability.EmailTriage{
+				Mail:     mailReader,
+				Modifier: modifier,
+				DryRun:   dryRun,
+			}
+
+			return et.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func emailTasks() cli.Capability {
+	return cli.Capability{
+		Name:           "email-tasks",
+		Description:    "Create tasks from starred or labelled email threads",
+		RequiredConfig: []string{"mail", "email-tasks", "tasks"},
+		RequiredEnv:    []string{"mail", "tasks"},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			mailReader, modifier, err := newMailReader(cfg, secrets, cfg.EmailTasks.RemoveLabel)
+			if err != nil {
+				return err
+			}
+
+			taskCreator, err := newTaskCreator(cfg, secrets, cfg.Tasks.BackendName())
+			if err != nil {
+				return err
+			}
+
+			et := &capability.EmailTasks{
+				Mail:     mailReader,
+				Creator:  taskCreator,
+				Modifier: modifier,
+			}
+			// Only Todoist can be searched for the tasks of earlier runs.
+			if todoistClient, ok := taskCreator.(*platform.TodoistClient); ok {
+				et.Todoist = todoistClient
+				et.Completed = todoistClient
+			}
+
+			return et.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func followUps() cli.Capability {
+	var createTasks bool
+
+	return cli.Capability{
+		Name:           "follow-ups",
+		Description:    "List sent threads still waiting for a reply",
+		RequiredConfig: []string{"mail", "tasks"},
+		RequiredEnv:    []string{"mail", "tasks"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&createTasks, "create-tasks", false, "add a follow-up task for each waiting thread")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			mailReader, _, err := newMailReader(cfg, secrets, false)
+			if err != nil {
+				return err
+			}
+
+			taskCreator, err := newTaskCreator(cfg, secrets, cfg.Tasks.BackendName())
+			if err != nil {
+				return err
+			}
+
+			fu := &capability.FollowUps{
+				Mail:        mailReader,
+				Creator:     taskCreator,
+				CreateTasks: createTasks,
+			}
+			// Only Todoist can be searched for the tasks of earlier runs.
+			if todoistClient, ok := taskCreator.(*platform.TodoistClient); ok {
+				fu.Todoist = todoistClient
+			}
+
+			return fu.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func invitations() cli.Capability {
+	var rsvp bool
+
+	return cli.Capability{
+		Name:           "invitations",
+		Description:    "List emailed calendar invitations waiting for a response",
+		RequiredConfig: []string{"calendar", "mail", "invitations"},
+		RequiredEnv:    []string{"calendar", "mail"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&rsvp, "rsvp", false, "answer invitations as suggested by invitations.rules")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			mailReader, _, err := newMailReader(cfg, secrets, false)
+			if err != nil {
+				return err
+			}
+
+			newCalendar := platform.NewGoogleCalendarClient
+			if rsvp {
+				newCalendar = platform.NewGoogleCalendarResponder
+			}
+			calendarClient, err := newCalendar(secrets.GoogleCredentials)
+			if err != nil {
+				return err
+			}
+
+			inv := &capability.Invitations{
+				Mail:      mailReader,
+				Calendar:  calendarClient,
+				Responder: calendarClient,
+				RSVP:      rsvp,
+			}
+
+			return inv.Run(cfg, secrets, out)
+		},
+	}
+}
+
+func newsletterDigest() cli.Capability {
+	var archive bool
+
+	return cli.Capability{
+		Name:           "newsletter-digest",
+		Description:    "Digest the headlines of inbox newsletters",
+		RequiredConfig: []string{"mail"},
+		RequiredEnv:    []string{"mail"},
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&archive, "archive", false, "archive the newsletters once digested (gmail backend only)")
+		},
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			mailReader, modifier, err := newMailReader(cfg, secrets, archive)
+			if err != nil {
+				return err
+			}
+
+			nd := &capability.NewsletterDigest{
+				Mail:     mailReader,
+				Bodies:   mailReader,
+				Modifier: modifier,
+				Archive:  archive,
+			}
+
+			return nd.Run(cfg, secrets, out)
+		},
+	}
+}
@@ -3,6 +3,6 @@ module github.com/sergekukharev/agent-samwise
 go 1.25.6
 
 require (
-	github.com/golang-jwt/jwt/v5 v5.3.1 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/golang-jwt/jwt/v5 v5.3.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -9,10 +9,42 @@ import (
 	"github.com/sergekukharev/agent-samwise/internal/platform"
 )
 
+// eventMarker tags the task created for an event, in its description, and
+// the event-details comment, so later runs can find both and update the
+// comment instead of adding another.
+const eventMarker = "sam-event-id: "
+
+// CalendarSyncResult records what calendar-sync did with each of today's
+// events, for machine-readable output.
+type CalendarSyncResult struct {
+	Created []SyncedEvent  `json:"created,omitempty" yaml:"created,omitempty"`
+	Updated []SyncedEvent  `json:"updated,omitempty" yaml:"updated,omitempty"`
+	Skipped []SkippedEvent `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+// SyncedEvent is a calendar event and the task that tracks it.
+type SyncedEvent struct {
+	EventID string `json:"event_id" yaml:"event_id"`
+	TaskID  string `json:"task_id" yaml:"task_id"`
+	Title   string `json:"title" yaml:"title"`
+}
+
+// SkippedEvent is a calendar event no task was created for, and why.
+type SkippedEvent struct {
+	EventID string `json:"event_id" yaml:"event_id"`
+	Title   string `json:"title" yaml:"title"`
+	Reason  string `json:"reason" yaml:"reason"`
+}
+
 // CalendarSync fetches today's calendar events and creates Todoist tasks.
 type CalendarSync struct {
 	Calendar platform.CalendarReader
 	Todoist  platform.TaskCreator
+	// Comments, when set, attaches the event's agenda, attendees, location and
+	// attachments to each task as a comment kept up to date across runs.
+	Comments platform.TaskCommenter
+	// Tasks finds tasks created on earlier runs. Required with Comments.
+	Tasks platform.TaskReader
 }
 
 func (cs *CalendarSync) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
@@ -23,20 +55,59 @@ func (cs *CalendarSync) Run(cfg config.Config, secrets config.Secrets, out outpu
 
 	actionable := filterDeclined(events)
 
+	var results CalendarSyncResult
+	for _, event := range events {
+		if event.RSVP == platform.RSVPDeclined {
+			results.Skipped = append(results.Skipped, SkippedEvent{EventID: event.ID, Title: event.Title, Reason: "declined"})
+		}
+	}
+
 	if len(actionable) == 0 {
 		return out.Present(output.Briefing{
 			Title:    "Calendar Sync",
-			Sections: []output.Section{{Heading: "Result", Body: "No events today"}},
+			Sections: []output.Section{{Heading: "Result", Items: []output.Item{output.Paragraph("No events today")}}},
+			Results:  results,
 		})
 	}
 
-	var created []string
+	synced, err := cs.syncedEvents(cfg.Todoist.ProjectID, actionable)
+	if err != nil {
+		return err
+	}
+
+	var created, updated []string
 	for _, event := range actionable {
 		task := toTodoistTask(event, cfg.Todoist.ProjectID)
-		if err := cs.Todoist.CreateTask(task); err != nil {
+		if cs.Comments != nil && event.ID != "" {
+			task.Description = strings.TrimSpace(task.Description + "\n\n" + eventMarker + event.ID)
+		}
+
+		if existing, ok := synced[event.ID]; ok && event.ID != "" {
+			if err := cs.updateEventComment(existing, event); err != nil {
+				return err
+			}
+			updated = append(updated, task.Title)
+			results.Updated = append(results.Updated, SyncedEvent{EventID: event.ID, TaskID: existing.taskID, Title: task.Title})
+			continue
+		}
+
+		taskID, err := cs.Todoist.CreateTask(task)
+		if err != nil {
 			return fmt.Errorf("creating todoist task %q: %w", task.Title, err)
 		}
 		created = append(created, task.Title)
+		results.Created = append(results.Created, SyncedEvent{EventID: event.ID, TaskID: taskID, Title: task.Title})
+
+		if cs.Comments != nil {
+			if _, err := cs.Comments.AddComment(taskID, eventComment(event)); err != nil {
+				return fmt.Errorf("commenting on todoist task %q: %w", task.Title, err)
+			}
+		}
+	}
+
+	result := fmt.Sprintf("Created %d tasks", len(created))
+	if len(updated) > 0 {
+		result += fmt.Sprintf(", %d already synced", len(updated))
 	}
 
 	return out.Present(output.Briefing{
@@ -44,16 +115,155 @@ func (cs *CalendarSync) Run(cfg config.Config, secrets config.Secrets, out outpu
 		Sections: []output.Section{
 			{
 				Heading: "Result",
-				Body:    fmt.Sprintf("Created %d tasks", len(created)),
+				Items:   []output.Item{output.Paragraph(result)},
 			},
 			{
 				Heading: "Tasks",
-				Body:    formatTaskList(created),
+				Items:   []output.Item{output.List{Entries: append(created, updated...)}},
 			},
 		},
+		Results: results,
 	})
 }
 
+// syncedComment is the event-details comment found on a task from an earlier run.
+type syncedComment struct {
+	taskID  string
+	comment platform.TaskComment
+}
+
+// syncedEvents maps today's event IDs to the tasks earlier runs created for
+// them, found through the marker in each task's description. Comments are
+// fetched only for those tasks, to find the event-details comment.
+func (cs *CalendarSync) syncedEvents(projectID string, events []platform.CalendarEvent) (map[string]syncedComment, error) {
+	synced := make(map[string]syncedComment)
+	if cs.Comments == nil || cs.Tasks == nil {
+		return synced, nil
+	}
+
+	today := make(map[string]bool, len(events))
+	for _, e := range events {
+		today[e.ID] = true
+	}
+
+	tasks, err := cs.Tasks.Tasks("")
+	if err != nil {
+		return nil, fmt.Errorf("fetching existing todoist tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		eventID := markedEventID(task.Description)
+		if task.ProjectID != projectID || eventID == "" || !today[eventID] {
+			continue
+		}
+		comments, err := cs.Comments.Comments(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("fetching comments for %q: %w", task.Title, err)
+		}
+		existing := syncedComment{taskID: task.ID}
+		for _, c := range comments {
+			if markedEventID(c.Content) == eventID {
+				existing.comment = c
+			}
+		}
+		synced[eventID] = existing
+	}
+	return synced, nil
+}
+
+// updateEventComment rewrites the event-details comment when the event has
+// changed, or adds it again when it was deleted.
+func (cs *CalendarSync) updateEventComment(existing syncedComment, event platform.CalendarEvent) error {
+	content := eventComment(event)
+	if existing.comment.ID == "" {
+		if _, err := cs.Comments.AddComment(existing.taskID, content); err != nil {
+			return fmt.Errorf("commenting on todoist task %q: %w", event.Title, err)
+		}
+		return nil
+	}
+	if existing.comment.Content == content {
+		return nil
+	}
+	if err := cs.Comments.UpdateComment(existing.comment.ID, content); err != nil {
+		return fmt.Errorf("updating comment for %q: %w", event.Title, err)
+	}
+	return nil
+}
+
+// eventComment renders the event details as a Markdown comment, ending with
+// the marker that identifies the event on later runs.
+func eventComment(event platform.CalendarEvent) string {
+	var parts []string
+
+	if event.Description != "" {
+		parts = append(parts, "**Agenda**\n"+event.Description)
+	}
+
+	if event.Location != "" {
+		parts = append(parts, "**Location:** "+event.Location)
+	}
+
+	if len(event.Attendees) > 0 {
+		var lines []string
+		for _, a := range event.Attendees {
+			lines = append(lines, formatAttendee(a))
+		}
+		parts = append(parts, "**Attendees**\n"+formatTaskList(lines))
+	}
+
+	if len(event.Attachments) > 0 {
+		var lines []string
+		for _, a := range event.Attachments {
+			lines = append(lines, fmt.Sprintf("[%s](%s)", a.Title, a.URL))
+		}
+		parts = append(parts, "**Attachments**\n"+formatTaskList(lines))
+	}
+
+	parts = append(parts, eventMarker+event.ID)
+	return strings.Join(parts, "\n\n")
+}
+
+func formatAttendee(a platform.Attendee) string {
+	name := a.Email
+	if a.Name != "" {
+		name = fmt.Sprintf("%s <%s>", a.Name, a.Email)
+	}
+
+	var notes []string
+	if a.Organizer {
+		notes = append(notes, "organizer")
+	}
+	if a.Optional {
+		notes = append(notes, "optional")
+	}
+	notes = append(notes, rsvpLabel(a.RSVP))
+
+	return fmt.Sprintf("%s — %s", name, strings.Join(notes, ", "))
+}
+
+func rsvpLabel(rsvp platform.RSVPStatus) string {
+	switch rsvp {
+	case platform.RSVPAccepted:
+		return "accepted"
+	case platform.RSVPDeclined:
+		return "declined"
+	case platform.RSVPTentative:
+		return "maybe"
+	default:
+		return "no response"
+	}
+}
+
+// markedEventID returns the event ID a task description or comment ends
+// with, or "".
+func markedEventID(text string) string {
+	idx := strings.LastIndex(text, eventMarker)
+	if idx == -1 {
+		return ""
+	}
+	return strings.TrimSpace(text[idx+len(eventMarker):])
+}
+
 func filterDeclined(events []platform.CalendarEvent) []platform.CalendarEvent {
 	var result []platform.CalendarEvent
 	for _, e := range events {
@@ -27,12 +27,12 @@ type stubTaskCreator struct {
 	err     error
 }
 
-func (s *stubTaskCreator) CreateTask(task platform.TodoistTask) error {
+func (s *stubTaskCreator) CreateTask(task platform.TodoistTask) (string, error) {
 	if s.err != nil {
-		return s.err
+		return "", s.err
 	}
 	s.created = append(s.created, task)
-	return nil
+	return fmt.Sprintf("task-%d", len(s.created)), nil
 }
 
 func testConfig() config.Config {
@@ -224,3 +224,211 @@ func TestCalendarSync_OutputSummary(t *testing.T) {
 		t.Errorf("output missing task name, got:\n%s", got)
 	}
 }
+
+type stubCommenter struct {
+	existing map[string][]platform.TaskComment
+	fetched  []string
+	added    map[string]string
+	updated  map[string]string
+}
+
+func (s *stubCommenter) Comments(taskID string) ([]platform.TaskComment, error) {
+	s.fetched = append(s.fetched, taskID)
+	return s.existing[taskID], nil
+}
+
+func (s *stubCommenter) AddComment(taskID, content string) (string, error) {
+	if s.added == nil {
+		s.added = make(map[string]string)
+	}
+	s.added[taskID] = content
+	return "new-comment", nil
+}
+
+func (s *stubCommenter) UpdateComment(commentID, content string) error {
+	if s.updated == nil {
+		s.updated = make(map[string]string)
+	}
+	s.updated[commentID] = content
+	return nil
+}
+
+func detailedEvent() platform.CalendarEvent {
+	return platform.CalendarEvent{
+		ID:          "evt-1",
+		Title:       "Design Review",
+		AllDay:      true,
+		RSVP:        platform.RSVPAccepted,
+		Description: "1. API schema",
+		Location:    "Room 4",
+		Attendees: []platform.Attendee{
+			{Name: "Ana", Email: "ana@example.com", Organizer: true, RSVP: platform.RSVPAccepted},
+			{Email: "bo@example.com", RSVP: platform.RSVPNeedsAction},
+		},
+		Attachments: []platform.Attachment{{Title: "Spec", URL: "https://drive.google.com/spec"}},
+	}
+}
+
+func TestCalendarSync_AddsEventDetailsComment(t *testing.T) {
+	todoist := &stubTaskCreator{}
+	comments := &stubCommenter{}
+	var buf bytes.Buffer
+
+	cs := &capability.CalendarSync{
+		Calendar: &stubCalendarReader{events: []platform.CalendarEvent{detailedEvent()}},
+		Todoist:  todoist,
+		Comments: comments,
+		Tasks:    &stubTaskReader{},
+	}
+
+	if err := cs.Run(testConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if desc := todoist.created[0].Description; desc != "sam-event-id: evt-1" {
+		t.Errorf("description = %q, want the event marker", desc)
+	}
+	comment, ok := comments.added["task-1"]
+	if !ok {
+		t.Fatalf("no comment added to created task, added = %v", comments.added)
+	}
+	for _, want := range []string{
+		"**Agenda**\n1. API schema",
+		"**Location:** Room 4",
+		"- Ana <ana@example.com> — organizer, accepted",
+		"- bo@example.com — no response",
+		"- [Spec](https://drive.google.com/spec)",
+		"sam-event-id: evt-1",
+	} {
+		if !strings.Contains(comment, want) {
+			t.Errorf("comment missing %q, got:\n%s", want, comment)
+		}
+	}
+}
+
+func TestCalendarSync_UpdatesCommentOnLaterRun(t *testing.T) {
+	todoist := &stubTaskCreator{}
+	comments := &stubCommenter{
+		existing: map[string][]platform.TaskComment{
+			"old-task": {{ID: "c1", TaskID: "old-task", Content: "**Location:** Room 1\n\nsam-event-id: evt-1"}},
+		},
+	}
+	var buf bytes.Buffer
+
+	cs := &capability.CalendarSync{
+		Calendar: &stubCalendarReader{events: []platform.CalendarEvent{detailedEvent()}},
+		Todoist:  todoist,
+		Comments: comments,
+		Tasks: &stubTaskReader{tasks: []platform.TodoistTask{
+			{ID: "old-task", Title: "Design Review", ProjectID: "test-project", Description: "sam-event-id: evt-1"},
+			{ID: "yesterday", Title: "Retro", ProjectID: "test-project", Description: "sam-event-id: evt-0"},
+			{ID: "chore", Title: "Water plants", ProjectID: "test-project"},
+		}},
+	}
+
+	if err := cs.Run(testConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(todoist.created) != 0 {
+		t.Errorf("created %d tasks, want 0 for an already synced event", len(todoist.created))
+	}
+	if len(comments.added) != 0 {
+		t.Errorf("added comments %v, want the existing one updated", comments.added)
+	}
+	if !strings.Contains(comments.updated["c1"], "**Location:** Room 4") {
+		t.Errorf("comment c1 not updated with new details, updated = %v", comments.updated)
+	}
+	if strings.Join(comments.fetched, ",") != "old-task" {
+		t.Errorf("fetched comments for %v, want only the task marked with today's event", comments.fetched)
+	}
+	if !strings.Contains(buf.String(), "Created 0 tasks, 1 already synced") {
+		t.Errorf("unexpected summary, got:\n%s", buf.String())
+	}
+}
+
+func TestCalendarSync_UnchangedCommentNotRewritten(t *testing.T) {
+	var first bytes.Buffer
+	comments := &stubCommenter{}
+	cs := &capability.CalendarSync{
+		Calendar: &stubCalendarReader{events: []platform.CalendarEvent{detailedEvent()}},
+		Todoist:  &stubTaskCreator{},
+		Comments: comments,
+		Tasks:    &stubTaskReader{},
+	}
+	if err := cs.Run(testConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &first}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rerun := &stubCommenter{
+		existing: map[string][]platform.TaskComment{
+			"task-1": {{ID: "c1", Content: comments.added["task-1"]}},
+		},
+	}
+	cs.Comments = rerun
+	cs.Tasks = &stubTaskReader{tasks: []platform.TodoistTask{{ID: "task-1", ProjectID: "test-project", Description: "sam-event-id: evt-1"}}}
+
+	var second bytes.Buffer
+	if err := cs.Run(testConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &second}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rerun.updated) != 0 || len(rerun.added) != 0 {
+		t.Errorf("unchanged event touched comments: added=%v updated=%v", rerun.added, rerun.updated)
+	}
+}
+
+func TestCalendarSync_ReaddsDeletedComment(t *testing.T) {
+	comments := &stubCommenter{}
+	cs := &capability.CalendarSync{
+		Calendar: &stubCalendarReader{events: []platform.CalendarEvent{detailedEvent()}},
+		Todoist:  &stubTaskCreator{},
+		Comments: comments,
+		Tasks: &stubTaskReader{tasks: []platform.TodoistTask{
+			{ID: "old-task", ProjectID: "test-project", Description: "sam-event-id: evt-1"},
+		}},
+	}
+	if err := cs.Run(testConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(comments.added["old-task"], "sam-event-id: evt-1") {
+		t.Errorf("added = %v, want the event-details comment added back", comments.added)
+	}
+}
+
+// recordingPresenter keeps the last briefing presented.
+type recordingPresenter struct {
+	briefing output.Briefing
+}
+
+func (p *recordingPresenter) Present(briefing output.Briefing) error {
+	p.briefing = briefing
+	return nil
+}
+
+func TestCalendarSync_Results(t *testing.T) {
+	cs := &capability.CalendarSync{
+		Calendar: &stubCalendarReader{
+			events: []platform.CalendarEvent{
+				{ID: "e1", Title: "Standup", RSVP: platform.RSVPAccepted, AllDay: true},
+				{ID: "e2", Title: "Offsite", RSVP: platform.RSVPDeclined, AllDay: true},
+			},
+		},
+		Todoist: &stubTaskCreator{},
+	}
+
+	out := &recordingPresenter{}
+	if err := cs.Run(testConfig(), config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, ok := out.briefing.Results.(capability.CalendarSyncResult)
+	if !ok {
+		t.Fatalf("results = %T, want CalendarSyncResult", out.briefing.Results)
+	}
+	if len(results.Created) != 1 || results.Created[0] != (capability.SyncedEvent{EventID: "e1", TaskID: "task-1", Title: "Standup"}) {
+		t.Errorf("created = %+v, want the standup task", results.Created)
+	}
+	if len(results.Skipped) != 1 || results.Skipped[0] != (capability.SkippedEvent{EventID: "e2", Title: "Offsite", Reason: "declined"}) {
+		t.Errorf("skipped = %+v, want the declined offsite", results.Skipped)
+	}
+}
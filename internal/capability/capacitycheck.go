@@ -0,0 +1,406 @@
+package capability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const (
+	// capacityFilter selects tasks due today and on the days tasks may be moved to.
+	capacityFilter = "next 7 days"
+	// capacityHorizonDays is how far ahead --apply looks for a day with free time.
+	capacityHorizonDays = 7
+
+	// soonWindow is how soon a meeting must start for its conflicts to be
+	// urgent.
+	soonWindow = 15 * time.Minute
+
+	defaultTaskMinutes = 30
+)
+
+// CapacityResult records today's capacity and the tasks suggested for
+// moving, for machine-readable output.
+type CapacityResult struct {
+	PlannedMinutes int        `json:"planned_minutes" yaml:"planned_minutes"`
+	FreeMinutes    int        `json:"free_minutes" yaml:"free_minutes"`
+	Suggested      []TaskMove `json:"suggested,omitempty" yaml:"suggested,omitempty"`
+}
+
+// TaskMove is a task suggested for another day. MovedTo is the date it was
+// moved to with --apply; Reason says why it could not be moved.
+type TaskMove struct {
+	TaskID  string `json:"task_id" yaml:"task_id"`
+	Title   string `json:"title" yaml:"title"`
+	MovedTo string `json:"moved_to,omitempty" yaml:"moved_to,omitempty"`
+	Reason  string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// CapacityCheck compares the time needed for today's tasks with the free time
+// left between meetings, and suggests lower-priority tasks to move.
+type CapacityCheck struct {
+	Calendar    platform.CalendarRangeReader
+	Todoist     platform.TaskReader
+	Rescheduler platform.TaskRescheduler
+	// Apply moves suggested tasks to the next working day with free capacity.
+	Apply bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (cc *CapacityCheck) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	now := cc.now()
+	today := startOfDay(now)
+	horizon := today.AddDate(0, 0, capacityHorizonDays+1)
+
+	events, err := cc.Calendar.EventsBetween(cfg.Calendar.CalendarID, today, horizon)
+	if err != nil {
+		return fmt.Errorf("fetching calendar events: %w", err)
+	}
+
+	tasks, err := cc.Todoist.Tasks(capacityFilter)
+	if err != nil {
+		return fmt.Errorf("fetching todoist tasks: %w", err)
+	}
+	// Meeting tasks mirrored by calendar-sync are already counted as busy time.
+	tasks = excludeProject(tasks, cfg.Todoist.ProjectID)
+
+	workday, err := newWorkday(cfg.Capacity)
+	if err != nil {
+		return err
+	}
+	estimator := taskEstimator{fallback: time.Duration(cfg.Capacity.DefaultTaskMinutes) * time.Minute}
+	if estimator.fallback == 0 {
+		estimator.fallback = defaultTaskMinutes * time.Minute
+	}
+
+	todayTasks := tasksDueOn(tasks, today)
+	planned := estimator.total(todayTasks)
+	free := workday.freeTime(today, now, events)
+
+	// An over-committed day is urgent, so it is pushed to notification outputs.
+	sections := []output.Section{
+		{Heading: "Today", Items: capacityItems(len(todayTasks), planned, free, workday.hours(today)), Urgent: planned > free},
+	}
+	if section, ok := startingSoon(events, now); ok {
+		sections = append(sections, section)
+	}
+	results := CapacityResult{PlannedMinutes: int(planned.Minutes()), FreeMinutes: int(free.Minutes())}
+
+	if planned <= free {
+		return out.Present(output.Briefing{Title: "Capacity Check", Sections: sections, Results: results})
+	}
+
+	suggested := suggestReschedules(todayTasks, planned-free, estimator)
+	var lines []string
+	for _, task := range suggested {
+		lines = append(lines, fmt.Sprintf("%s (%s, %s)", task.Title, priorityLabel(task.Priority), formatDuration(estimator.estimate(task))))
+		results.Suggested = append(results.Suggested, TaskMove{TaskID: task.ID, Title: task.Title})
+	}
+	sections = append(sections, output.Section{Heading: "Suggested Reschedules", Items: []output.Item{output.List{Entries: lines}}})
+
+	if cc.Apply {
+		moved, err := cc.reschedule(suggested, tasks, events, today, workday, estimator, results.Suggested)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, output.Section{Heading: "Rescheduled", Items: []output.Item{output.List{Entries: moved}}})
+	}
+
+	return out.Present(output.Briefing{Title: "Capacity Check", Sections: sections, Results: results})
+}
+
+// reschedule moves each suggested task to the first working day within the
+// horizon that still has room for it, records the outcome in the matching
+// move, and returns a line per task.
+func (cc *CapacityCheck) reschedule(suggested, tasks []platform.TodoistTask, events []platform.CalendarEvent, today time.Time, workday workday, estimator taskEstimator, moves []TaskMove) ([]string, error) {
+	spare := make(map[int]time.Duration)
+	for offset := 1; offset <= capacityHorizonDays; offset++ {
+		day := today.AddDate(0, 0, offset)
+		spare[offset] = workday.freeTime(day, day, events) - estimator.total(tasksDueOn(tasks, day))
+	}
+
+	var lines []string
+	for i, task := range suggested {
+		need := estimator.estimate(task)
+		target := 0
+		for offset := 1; offset <= capacityHorizonDays; offset++ {
+			if isWeekend(today.AddDate(0, 0, offset)) {
+				continue
+			}
+			if spare[offset] >= need {
+				target = offset
+				break
+			}
+		}
+
+		if target == 0 {
+			moves[i].Reason = "no free day in the next week"
+			lines = append(lines, fmt.Sprintf("%s (%s)", task.Title, moves[i].Reason))
+			continue
+		}
+
+		day := today.AddDate(0, 0, target)
+		if err := cc.Rescheduler.RescheduleTask(task, day); err != nil {
+			return nil, fmt.Errorf("rescheduling %q: %w", task.Title, err)
+		}
+		spare[target] -= need
+		moves[i].MovedTo = day.Format(time.DateOnly)
+		lines = append(lines, fmt.Sprintf("%s → %s", task.Title, day.Format("Mon 2 Jan")))
+	}
+	return lines, nil
+}
+
+func (cc *CapacityCheck) now() time.Time {
+	if cc.Now != nil {
+		return cc.Now()
+	}
+	return time.Now()
+}
+
+// suggestReschedules picks the lowest-priority tasks, largest first within a
+// priority, until their combined estimate covers the overage. Recurring
+// tasks are never picked: setting a new due date drops their recurrence.
+func suggestReschedules(tasks []platform.TodoistTask, overage time.Duration, estimator taskEstimator) []platform.TodoistTask {
+	var candidates []platform.TodoistTask
+	for _, task := range tasks {
+		if !task.IsRecurring {
+			candidates = append(candidates, task)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority < candidates[j].Priority
+		}
+		return estimator.estimate(candidates[i]) > estimator.estimate(candidates[j])
+	})
+
+	var picked []platform.TodoistTask
+	var freed time.Duration
+	for _, task := range candidates {
+		if freed >= overage {
+			break
+		}
+		picked = append(picked, task)
+		freed += estimator.estimate(task)
+	}
+	return picked
+}
+
+// startingSoon returns an urgent section listing the meetings starting
+// within soonWindow that overlap another meeting, so there is still time to
+// pick one.
+func startingSoon(events []platform.CalendarEvent, now time.Time) (output.Section, bool) {
+	timed := func(e platform.CalendarEvent) bool {
+		return !e.AllDay && e.RSVP != platform.RSVPDeclined && e.EndTime.After(e.StartTime)
+	}
+
+	var lines []string
+	for i, e := range events {
+		if !timed(e) || e.StartTime.Before(now) || e.StartTime.After(now.Add(soonWindow)) {
+			continue
+		}
+		var titles []string
+		for j, other := range events {
+			if j != i && timed(other) && other.StartTime.Before(e.EndTime) && other.EndTime.After(e.StartTime) {
+				titles = append(titles, other.Title)
+			}
+		}
+		if len(titles) > 0 {
+			lines = append(lines, fmt.Sprintf("%s %s conflicts with %s", e.StartTime.Local().Format("15:04"), e.Title, strings.Join(titles, ", ")))
+		}
+	}
+	if len(lines) == 0 {
+		return output.Section{}, false
+	}
+	return output.Section{
+		Heading: "Starting Soon",
+		Items: []output.Item{
+			output.Status{Level: output.LevelWarning, Text: fmt.Sprintf("%d meetings in the next %s overlap another", len(lines), formatDuration(soonWindow))},
+			output.List{Entries: lines},
+		},
+		Urgent: true,
+	}, true
+}
+
+// capacityItems summarises planned against free time in today's working hours.
+func capacityItems(count int, planned, free time.Duration, hours output.TimeRange) []output.Item {
+	items := []output.Item{
+		output.Facts{
+			{Label: "Planned", Value: fmt.Sprintf("%s across %d tasks", formatDuration(planned), count)},
+			{Label: "Free", Value: formatDuration(free)},
+		},
+		hours,
+	}
+	if planned > free {
+		return append(items, output.Status{Level: output.LevelWarning, Text: "Over-committed by " + formatDuration(planned-free)})
+	}
+	return append(items, output.Status{Level: output.LevelOK, Text: fmt.Sprintf("Fits with %s to spare", formatDuration(free-planned))})
+}
+
+// formatDuration renders durations as "1h30m", "2h" or "45m".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", m)
+	case m == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh%dm", h, m)
+	}
+}
+
+// taskEstimator resolves how long a task will take: Todoist's duration first,
+// then an estimate label such as "30m" or "1h30m", then a fallback.
+type taskEstimator struct {
+	fallback time.Duration
+}
+
+func (e taskEstimator) estimate(task platform.TodoistTask) time.Duration {
+	if task.Duration > 0 {
+		return task.Duration
+	}
+	for _, label := range task.Labels {
+		if d, err := time.ParseDuration(label); err == nil && d > 0 {
+			return d
+		}
+	}
+	return e.fallback
+}
+
+func (e taskEstimator) total(tasks []platform.TodoistTask) time.Duration {
+	var sum time.Duration
+	for _, task := range tasks {
+		sum += e.estimate(task)
+	}
+	return sum
+}
+
+// workday is the daily window during which tasks can be worked on.
+type workday struct {
+	start, end time.Duration // offsets from midnight
+}
+
+func newWorkday(cfg config.CapacityConfig) (workday, error) {
+	startClock, endClock := cfg.Workday()
+	start, err := clockOffset(startClock)
+	if err != nil {
+		return workday{}, fmt.Errorf("parsing capacity.workday_start: %w", err)
+	}
+	end, err := clockOffset(endClock)
+	if err != nil {
+		return workday{}, fmt.Errorf("parsing capacity.workday_end: %w", err)
+	}
+	return workday{start: start, end: end}, nil
+}
+
+// hours returns the working hours on day.
+func (w workday) hours(day time.Time) output.TimeRange {
+	midnight := startOfDay(day)
+	return output.TimeRange{Label: "Working hours", Start: midnight.Add(w.start), End: midnight.Add(w.end)}
+}
+
+func clockOffset(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// freeTime returns the working time on day not taken by meetings, counting
+// only time after notBefore (so a mid-day run ignores the morning).
+func (w workday) freeTime(day, notBefore time.Time, events []platform.CalendarEvent) time.Duration {
+	midnight := startOfDay(day)
+	windowStart := midnight.Add(w.start)
+	windowEnd := midnight.Add(w.end)
+	if notBefore.After(windowStart) {
+		windowStart = notBefore
+	}
+	if !windowEnd.After(windowStart) {
+		return 0
+	}
+
+	var busy []interval
+	for _, e := range events {
+		if e.AllDay || e.RSVP == platform.RSVPDeclined || e.EndTime.IsZero() {
+			continue
+		}
+		start, end := e.StartTime, e.EndTime
+		if start.Before(windowStart) {
+			start = windowStart
+		}
+		if end.After(windowEnd) {
+			end = windowEnd
+		}
+		if end.After(start) {
+			busy = append(busy, interval{start, end})
+		}
+	}
+
+	return windowEnd.Sub(windowStart) - mergedLength(busy)
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+// mergedLength returns the total time covered by possibly overlapping intervals.
+func mergedLength(intervals []interval) time.Duration {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start.Before(intervals[j].start) })
+
+	var total time.Duration
+	var current *interval
+	for i := range intervals {
+		iv := intervals[i]
+		if current != nil && !iv.start.After(current.end) {
+			if iv.end.After(current.end) {
+				current.end = iv.end
+			}
+			continue
+		}
+		if current != nil {
+			total += current.end.Sub(current.start)
+		}
+		current = &iv
+	}
+	if current != nil {
+		total += current.end.Sub(current.start)
+	}
+	return total
+}
+
+func tasksDueOn(tasks []platform.TodoistTask, day time.Time) []platform.TodoistTask {
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if isDueOn(task, day) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+func excludeProject(tasks []platform.TodoistTask, projectID string) []platform.TodoistTask {
+	if projectID == "" {
+		return tasks
+	}
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if task.ProjectID != projectID {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
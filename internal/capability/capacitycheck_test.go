@@ -0,0 +1,224 @@
+package capability_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubRangeReader struct {
+	events []platform.CalendarEvent
+	err    error
+}
+
+func (s *stubRangeReader) EventsBetween(calendarID string, start, end time.Time) ([]platform.CalendarEvent, error) {
+	return s.events, s.err
+}
+
+type stubRescheduler struct {
+	moved map[string]time.Time
+}
+
+func (s *stubRescheduler) RescheduleTask(task platform.TodoistTask, due time.Time) error {
+	if s.moved == nil {
+		s.moved = make(map[string]time.Time)
+	}
+	s.moved[task.Title] = due
+	return nil
+}
+
+// at returns a time on 2026-02-10 (a Tuesday) or a later day in February.
+func at(d, hour, minute int) time.Time {
+	return time.Date(2026, 2, d, hour, minute, 0, 0, time.Local)
+}
+
+func meeting(d, startHour, endHour int) platform.CalendarEvent {
+	return platform.CalendarEvent{
+		Title:     "Meeting",
+		StartTime: at(d, startHour, 0),
+		EndTime:   at(d, endHour, 0),
+		RSVP:      platform.RSVPAccepted,
+	}
+}
+
+func TestCapacityCheck_Fits(t *testing.T) {
+	var buf bytes.Buffer
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{meeting(10, 9, 12)}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{Title: "Review PR", Priority: 1, DueDate: day(10), Duration: time.Hour},
+		}},
+		Now: fixedNow,
+	}
+
+	if err := cc.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "Planned: 1h across 1 tasks") || !strings.Contains(got, "Free: 5h") {
+		t.Errorf("unexpected capacity summary, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Fits with 4h to spare") {
+		t.Errorf("expected day to fit, got:\n%s", got)
+	}
+	if strings.Contains(got, "Suggested Reschedules") {
+		t.Errorf("no reschedules expected, got:\n%s", got)
+	}
+}
+
+func TestCapacityCheck_OverCommittedSuggestsLowPriority(t *testing.T) {
+	var buf bytes.Buffer
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{
+			meeting(10, 9, 12),
+			meeting(10, 11, 15), // overlaps the first meeting
+			{Title: "Declined", StartTime: at(10, 15, 0), EndTime: at(10, 17, 0), RSVP: platform.RSVPDeclined},
+		}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{Title: "Urgent fix", Priority: 4, DueDate: day(10), Duration: time.Hour},
+			{Title: "Tidy backlog", Priority: 1, DueDate: day(10), Labels: []string{"1h30m"}},
+			{Title: "Reply to forum", Priority: 1, DueDate: day(10)},
+			{Title: "Standup", ProjectID: "meetings", Priority: 3, DueDate: day(10), Duration: time.Hour},
+		}},
+		Now: fixedNow,
+	}
+	cfg := config.Config{Todoist: config.TodoistConfig{ProjectID: "meetings"}}
+
+	if err := cc.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "Planned: 3h across 3 tasks") {
+		t.Errorf("calendar-sync tasks should be excluded from planned time, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Free: 2h") || !strings.Contains(got, "Over-committed by 1h") {
+		t.Errorf("unexpected capacity summary, got:\n%s", got)
+	}
+	if !strings.Contains(got, "- Tidy backlog (P4, 1h30m)") {
+		t.Errorf("expected largest low-priority task suggested, got:\n%s", got)
+	}
+	if strings.Contains(got, "Urgent fix (") || strings.Contains(got, "Reply to forum (") {
+		t.Errorf("only enough tasks to cover the overage should be suggested, got:\n%s", got)
+	}
+}
+
+func TestCapacityCheck_ConflictStartingSoonIsUrgent(t *testing.T) {
+	review := meeting(10, 8, 9)
+	review.Title = "Design review"
+	review.StartTime = at(10, 8, 10)
+	out := &recordingPresenter{}
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{
+			review,
+			{Title: "Standup", StartTime: at(10, 8, 30), EndTime: at(10, 8, 45), RSVP: platform.RSVPAccepted},
+			{Title: "Skipped sync", StartTime: at(10, 8, 0), EndTime: at(10, 9, 0), RSVP: platform.RSVPDeclined},
+			// Conflicts, but not for another hour.
+			{Title: "Lunch", StartTime: at(10, 9, 30), EndTime: at(10, 10, 0), RSVP: platform.RSVPAccepted},
+			{Title: "Interview", StartTime: at(10, 9, 45), EndTime: at(10, 10, 30), RSVP: platform.RSVPAccepted},
+		}},
+		Todoist: &stubTaskReader{},
+		Now:     fixedNow,
+	}
+
+	if err := cc.Run(config.Config{}, config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sections := out.briefing.Sections
+	if len(sections) != 2 || sections[0].Urgent {
+		t.Fatalf("sections = %+v, want today's capacity followed by an urgent section", sections)
+	}
+	soon := sections[1]
+	if soon.Heading != "Starting Soon" || !soon.Urgent {
+		t.Errorf("section = %+v, want an urgent Starting Soon section", soon)
+	}
+	if list, ok := soon.Items[1].(output.List); !ok || strings.Join(list.Entries, "; ") != "08:10 Design review conflicts with Standup" {
+		t.Errorf("items = %+v, want only the meeting starting within 15 minutes", soon.Items)
+	}
+}
+
+func TestCapacityCheck_SkipsRecurringTasks(t *testing.T) {
+	var buf bytes.Buffer
+	rescheduler := &stubRescheduler{}
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{meeting(10, 9, 16)}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{Title: "Water plants", Priority: 1, DueDate: day(10), Duration: 2 * time.Hour, IsRecurring: true},
+			{Title: "Review PR", Priority: 2, DueDate: day(10), Duration: time.Hour},
+		}},
+		Rescheduler: rescheduler,
+		Apply:       true,
+		Now:         fixedNow,
+	}
+
+	if err := cc.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := rescheduler.moved["Water plants"]; ok {
+		t.Errorf("recurring task was rescheduled, got:\n%s", buf.String())
+	}
+	if _, ok := rescheduler.moved["Review PR"]; !ok {
+		t.Errorf("expected the non-recurring task to be moved, got:\n%s", buf.String())
+	}
+}
+
+func TestCapacityCheck_ApplyMovesToNextFreeWorkday(t *testing.T) {
+	var buf bytes.Buffer
+	rescheduler := &stubRescheduler{}
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{
+			meeting(10, 9, 17),
+			meeting(11, 9, 17), // Wednesday is fully booked
+		}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{ID: "1", Title: "Write docs", Priority: 1, DueDate: day(10), Duration: 2 * time.Hour},
+		}},
+		Rescheduler: rescheduler,
+		Apply:       true,
+		Now:         fixedNow,
+	}
+
+	if err := cc.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	due, ok := rescheduler.moved["Write docs"]
+	if !ok {
+		t.Fatalf("task was not rescheduled, output:\n%s", buf.String())
+	}
+	if due.Day() != 12 {
+		t.Errorf("rescheduled to %v, want Thursday 12 Feb", due)
+	}
+	if !strings.Contains(buf.String(), "Write docs → Thu 12 Feb") {
+		t.Errorf("missing rescheduled line, got:\n%s", buf.String())
+	}
+}
+
+func TestCapacityCheck_WithoutApplyDoesNotReschedule(t *testing.T) {
+	var buf bytes.Buffer
+	rescheduler := &stubRescheduler{}
+	cc := &capability.CapacityCheck{
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{meeting(10, 9, 17)}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{Title: "Write docs", Priority: 1, DueDate: day(10)},
+		}},
+		Rescheduler: rescheduler,
+		Now:         fixedNow,
+	}
+
+	if err := cc.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rescheduler.moved) != 0 {
+		t.Errorf("tasks moved without --apply: %v", rescheduler.moved)
+	}
+}
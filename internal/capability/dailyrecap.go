@@ -0,0 +1,160 @@
+package capability
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+// dailyRecapFilter selects open tasks that should have been done by today.
+const dailyRecapFilter = "overdue | today"
+
+// DailyRecapResult records the day's recap and the meeting tasks it closed,
+// for machine-readable output.
+type DailyRecapResult struct {
+	Completed []TaskRef     `json:"completed,omitempty" yaml:"completed,omitempty"`
+	Slipped   []TaskRef     `json:"slipped,omitempty" yaml:"slipped,omitempty"`
+	Closed    []SyncedEvent `json:"closed,omitempty" yaml:"closed,omitempty"`
+	Journal   string        `json:"journal,omitempty" yaml:"journal,omitempty"`
+}
+
+// DailyRecap summarises the day: completed tasks, attended meetings and tasks
+// that slipped. It closes meeting tasks created by calendar-sync for meetings
+// that have already ended and journals the recap.
+type DailyRecap struct {
+	Calendar  platform.CalendarReader
+	Todoist   platform.TaskReader
+	Completed platform.CompletedTaskReader
+	Closer    platform.TaskCloser
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (dr *DailyRecap) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	now := dr.now()
+	today := startOfDay(now)
+
+	completed, err := dr.Completed.CompletedTasks(today)
+	if err != nil {
+		return fmt.Errorf("fetching completed tasks: %w", err)
+	}
+
+	events, err := dr.Calendar.TodayEvents(cfg.Calendar.CalendarID)
+	if err != nil {
+		return fmt.Errorf("fetching calendar events: %w", err)
+	}
+
+	open, err := dr.Todoist.Tasks(dailyRecapFilter)
+	if err != nil {
+		return fmt.Errorf("fetching todoist tasks: %w", err)
+	}
+
+	var meetings []string
+	for _, e := range events {
+		if e.AllDay || e.RSVP != platform.RSVPAccepted {
+			continue
+		}
+		meetings = append(meetings, fmt.Sprintf("%s %s", e.StartTime.Local().Format("15:04"), e.Title))
+	}
+
+	slipped := excludeProject(open, cfg.Todoist.ProjectID)
+
+	briefing := output.Briefing{
+		Title: "Daily Recap — " + today.Format("Mon 2 Jan"),
+		Sections: []output.Section{
+			{Heading: "Completed", Items: listOrNone(completedTitles(completed))},
+			{Heading: "Meetings", Items: listOrNone(meetings)},
+			{Heading: "Slipped", Items: listOrNone(taskTitles(slipped))},
+		},
+	}
+
+	results := DailyRecapResult{Slipped: taskRefs(slipped)}
+	for _, task := range completed {
+		results.Completed = append(results.Completed, TaskRef{TaskID: task.ID, Title: task.Title})
+	}
+
+	closed, err := dr.closeEndedMeetingTasks(open, events, cfg.Todoist.ProjectID, now)
+	if err != nil {
+		return err
+	}
+	results.Closed = closed
+	if len(closed) > 0 {
+		var titles []string
+		for _, c := range closed {
+			titles = append(titles, c.Title)
+		}
+		briefing.Sections = append(briefing.Sections, output.Section{
+			Heading: "Cleanup",
+			Items: []output.Item{output.List{
+				Title:   fmt.Sprintf("Completed %d tasks for ended meetings", len(closed)),
+				Entries: titles,
+			}},
+		})
+	}
+
+	if dir := cfg.DailyRecap.JournalDir; dir != "" {
+		// A re-run replaces the day's recap block rather than adding another.
+		journal := output.NewJournalPresenter(config.JournalConfig{Dir: dir}, cfg.Areas)
+		journal.Now = func() time.Time { return now }
+		briefing.Capability = "daily-recap"
+		if err := journal.Present(briefing); err != nil {
+			return err
+		}
+		results.Journal = filepath.Join(dir, today.Format(time.DateOnly)+".md")
+		briefing.Sections = append(briefing.Sections, output.Section{
+			Heading: "Journal",
+			Items:   []output.Item{output.Paragraph("Written to " + results.Journal)},
+		})
+	}
+
+	briefing.Results = results
+	return out.Present(briefing)
+}
+
+// closeEndedMeetingTasks completes the tasks calendar-sync created for
+// today's meetings once those meetings are over. A task carrying an event
+// marker matches that event only. A task without one matches when it lives in
+// the calendar-sync project, carries the title calendar-sync would give the
+// event and, if timed, is due at the event's start.
+func (dr *DailyRecap) closeEndedMeetingTasks(tasks []platform.TodoistTask, events []platform.CalendarEvent, projectID string, now time.Time) ([]SyncedEvent, error) {
+	if projectID == "" {
+		return nil, nil
+	}
+
+	var closed []SyncedEvent
+	for _, e := range events {
+		if e.AllDay || e.EndTime.IsZero() || e.EndTime.After(now) {
+			continue
+		}
+		expected := toTodoistTask(e, projectID)
+
+		for _, task := range tasks {
+			if task.ProjectID != projectID {
+				continue
+			}
+			if id := markedEventID(task.Description); id != "" {
+				if id != e.ID {
+					continue
+				}
+			} else if task.Title != expected.Title || (task.DueDateTime != nil && !task.DueDateTime.Equal(e.StartTime)) {
+				continue
+			}
+			if err := dr.Closer.CloseTask(task.ID); err != nil {
+				return nil, fmt.Errorf("closing meeting task %q: %w", task.Title, err)
+			}
+			closed = append(closed, SyncedEvent{EventID: e.ID, TaskID: task.ID, Title: task.Title})
+		}
+	}
+	return closed, nil
+}
+
+func (dr *DailyRecap) now() time.Time {
+	if dr.Now != nil {
+		return dr.Now()
+	}
+	return time.Now()
+}
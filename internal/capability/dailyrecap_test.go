@@ -0,0 +1,162 @@
+package capability_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubTaskCloser struct {
+	closed []string
+}
+
+func (s *stubTaskCloser) CloseTask(taskID string) error {
+	s.closed = append(s.closed, taskID)
+	return nil
+}
+
+func recapNow() time.Time {
+	return at(10, 18, 0)
+}
+
+func dailyRecapFixture() *capability.DailyRecap {
+	standupStart := at(10, 9, 0)
+	return &capability.DailyRecap{
+		Calendar: &stubCalendarReader{events: []platform.CalendarEvent{
+			{Title: "Standup", StartTime: standupStart, EndTime: at(10, 9, 15), RSVP: platform.RSVPAccepted},
+			{Title: "Design review", StartTime: at(10, 14, 0), EndTime: at(10, 15, 0), RSVP: platform.RSVPNeedsAction},
+			{Title: "Late call", StartTime: at(10, 19, 0), EndTime: at(10, 20, 0), RSVP: platform.RSVPAccepted},
+		}},
+		Todoist: &stubTaskReader{tasks: []platform.TodoistTask{
+			{ID: "m1", Title: "Standup", ProjectID: "meetings", DueDateTime: &standupStart, DueDate: day(10)},
+			{ID: "m2", Title: "UNCONFIRMED: Design review", ProjectID: "meetings", DueDate: day(10)},
+			{ID: "m3", Title: "Late call", ProjectID: "meetings", DueDate: day(10)},
+			{ID: "t1", Title: "Write proposal", ProjectID: "work", DueDate: day(10)},
+		}},
+		Completed: &stubCompletedReader{tasks: []platform.CompletedTask{{Title: "Fix login bug"}}},
+		Closer:    &stubTaskCloser{},
+		Now:       recapNow,
+	}
+}
+
+func TestDailyRecap_Summary(t *testing.T) {
+	var buf bytes.Buffer
+	dr := dailyRecapFixture()
+	cfg := config.Config{Todoist: config.TodoistConfig{ProjectID: "meetings"}}
+
+	if err := dr.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{"- Fix login bug", "09:00 Standup", "- Write proposal"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "14:00 Design review") {
+		t.Errorf("unaccepted meeting listed as attended, got:\n%s", got)
+	}
+	slipped := got[strings.Index(got, "## Slipped"):]
+	if strings.Contains(slipped[:strings.Index(slipped, "## Cleanup")], "Standup") {
+		t.Errorf("meeting tasks listed as slipped, got:\n%s", got)
+	}
+}
+
+func TestDailyRecap_ClosesTasksForEndedMeetings(t *testing.T) {
+	var buf bytes.Buffer
+	dr := dailyRecapFixture()
+	closer := dr.Closer.(*stubTaskCloser)
+	cfg := config.Config{Todoist: config.TodoistConfig{ProjectID: "meetings"}}
+
+	if err := dr.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(closer.closed, ",") != "m1,m2" {
+		t.Errorf("closed = %v, want [m1 m2] (late call has not ended)", closer.closed)
+	}
+	if !strings.Contains(buf.String(), "Completed 2 tasks for ended meetings") {
+		t.Errorf("missing cleanup summary, got:\n%s", buf.String())
+	}
+}
+
+func TestDailyRecap_Results(t *testing.T) {
+	out := &recordingPresenter{}
+	cfg := config.Config{Todoist: config.TodoistConfig{ProjectID: "meetings"}}
+	if err := dailyRecapFixture().Run(cfg, config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, ok := out.briefing.Results.(capability.DailyRecapResult)
+	if !ok {
+		t.Fatalf("results = %T, want DailyRecapResult", out.briefing.Results)
+	}
+	if len(results.Completed) != 1 || results.Completed[0].Title != "Fix login bug" {
+		t.Errorf("completed = %+v, want the fixed bug", results.Completed)
+	}
+	if len(results.Slipped) != 1 || results.Slipped[0] != (capability.TaskRef{TaskID: "t1", Title: "Write proposal"}) {
+		t.Errorf("slipped = %+v, want the proposal", results.Slipped)
+	}
+	if len(results.Closed) != 2 || results.Closed[0].TaskID != "m1" || results.Closed[1].TaskID != "m2" {
+		t.Errorf("closed = %+v, want the standup and design review tasks", results.Closed)
+	}
+}
+
+func TestDailyRecap_MatchesMeetingTasksByEventMarker(t *testing.T) {
+	standupStart := at(10, 9, 0)
+	dr := dailyRecapFixture()
+	dr.Calendar = &stubCalendarReader{events: []platform.CalendarEvent{
+		{ID: "ev1", Title: "Standup", StartTime: standupStart, EndTime: at(10, 9, 15), RSVP: platform.RSVPAccepted},
+	}}
+	dr.Todoist = &stubTaskReader{tasks: []platform.TodoistTask{
+		{ID: "m1", Title: "Standup", ProjectID: "meetings", DueDateTime: &standupStart, Description: "sam-event-id: ev1"},
+		// The user's own task, and a task for another event, share the title.
+		{ID: "own", Title: "Standup", ProjectID: "meetings", DueDateTime: &standupStart, Description: "Prepare notes\n\nsam-event-id: ev2"},
+		{ID: "renamed", Title: "Daily standup", ProjectID: "meetings", Description: "sam-event-id: ev1"},
+	}}
+	closer := dr.Closer.(*stubTaskCloser)
+	cfg := config.Config{Todoist: config.TodoistConfig{ProjectID: "meetings"}}
+
+	if err := dr.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(closer.closed, ",") != "m1,renamed" {
+		t.Errorf("closed = %v, want the tasks marked with the event", closer.closed)
+	}
+}
+
+func TestDailyRecap_ReplacesJournalBlock(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "journal")
+	cfg := config.Config{
+		Todoist:    config.TodoistConfig{ProjectID: "meetings"},
+		DailyRecap: config.DailyRecapConfig{JournalDir: dir},
+	}
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		if err := dailyRecapFixture().Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "2026-02-10.md"))
+	if err != nil {
+		t.Fatalf("reading journal: %v", err)
+	}
+	got := string(data)
+	if n := strings.Count(got, "## Daily Recap — Tue 10 Feb"); n != 1 {
+		t.Errorf("journal has %d recaps, want 1 replaced on re-run, got:\n%s", n, got)
+	}
+	if !strings.Contains(got, "Completed 2 tasks for ended meetings") {
+		t.Errorf("journal missing cleanup, got:\n%s", got)
+	}
+}
@@ -0,0 +1,346 @@
+package capability
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+// threadMarker tags the tasks created for Gmail threads so later runs can
+// skip threads that already have a task.
+const threadMarker = "sam-thread-id: "
+
+const starredLabel = "STARRED"
+
+// completedLookback is how far back completed tasks still count as
+// tracking their thread, so a thread left flagged after its task is done
+// does not get a new one.
+const completedLookback = 90 * 24 * time.Hour
+
+// EmailTaskResult records the tasks created for email threads, for
+// machine-readable output. Follow-ups reports the same shape.
+type EmailTaskResult struct {
+	Created []ThreadTask    `json:"created,omitempty" yaml:"created,omitempty"`
+	Skipped []SkippedThread `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+}
+
+// ThreadTask is an email thread and the task created for it.
+type ThreadTask struct {
+	ThreadID string `json:"thread_id" yaml:"thread_id"`
+	TaskID   string `json:"task_id" yaml:"task_id"`
+	Title    string `json:"title" yaml:"title"`
+}
+
+// SkippedThread is an email thread no task was created for, and why.
+type SkippedThread struct {
+	ThreadID string `json:"thread_id" yaml:"thread_id"`
+	Subject  string `json:"subject" yaml:"subject"`
+	Reason   string `json:"reason" yaml:"reason"`
+}
+
+// EmailTasks creates a Todoist task for each starred or labelled email thread.
+type EmailTasks struct {
+	Mail platform.MailReader
+	// Todoist finds tasks created on earlier runs in Creator's backend, so a
+	// thread is not added twice. When nil, as for backends that cannot be
+	// read, only email_tasks.remove_label stops threads being added again.
+	Todoist platform.TaskReader
+	Creator platform.TaskCreator
+	// Completed lists recently completed tasks, whose threads are tracked
+	// too. Optional.
+	Completed platform.CompletedTaskReader
+	// Modifier unflags threads once their task exists. Required when
+	// email_tasks.remove_label is set.
+	Modifier platform.GmailModifier
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (et *EmailTasks) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	settings := cfg.EmailTasks
+	label := settings.Label
+	if label == "" {
+		label = starredLabel
+	}
+
+	threads, err := et.Mail.Threads(flaggedQuery(settings.Label, cfg.Mail.BackendName()))
+	if err != nil {
+		return fmt.Errorf("fetching flagged threads: %w", err)
+	}
+
+	if len(threads) == 0 {
+		return out.Present(output.Briefing{
+			Title:    "Email Tasks",
+			Sections: []output.Section{{Heading: "Result", Items: []output.Item{output.Paragraph("No flagged emails")}}},
+		})
+	}
+
+	tracked := make(map[string]bool)
+	if et.Todoist != nil {
+		tasks, err := et.Todoist.Tasks("")
+		if err != nil {
+			return fmt.Errorf("fetching todoist tasks: %w", err)
+		}
+		tracked = trackedThreads(tasks)
+	}
+	if et.Completed != nil {
+		completed, err := et.Completed.CompletedTasks(et.now().Add(-completedLookback))
+		if err != nil {
+			return fmt.Errorf("fetching completed tasks: %w", err)
+		}
+		for _, task := range completed {
+			markThreads(tracked, task.Description)
+		}
+	}
+
+	var created, unflagged []string
+	var results EmailTaskResult
+	existing := 0
+	for _, thread := range threads {
+		if tracked[thread.ID] {
+			existing++
+			results.Skipped = append(results.Skipped, SkippedThread{ThreadID: thread.ID, Subject: threadSubject(thread), Reason: "already tracked"})
+		} else {
+			task := emailTask(thread, settings, cfg)
+			taskID, err := et.Creator.CreateTask(task)
+			if err != nil {
+				return fmt.Errorf("creating todoist task %q: %w", task.Title, err)
+			}
+			created = append(created, task.Title+emailDueSuffix(task))
+			results.Created = append(results.Created, ThreadTask{ThreadID: thread.ID, TaskID: taskID, Title: task.Title})
+		}
+
+		if settings.RemoveLabel && et.Modifier != nil {
+			if err := et.Modifier.RemoveLabel(thread.ID, label); err != nil {
+				return err
+			}
+			unflagged = append(unflagged, threadSubject(thread))
+		}
+	}
+
+	result := fmt.Sprintf("Created %d tasks", len(created))
+	if existing > 0 {
+		result += fmt.Sprintf(", %d already tracked", existing)
+	}
+
+	sections := []output.Section{{Heading: "Result", Items: []output.Item{output.Paragraph(result)}}}
+	if len(created) > 0 {
+		sections = append(sections, output.Section{Heading: "Tasks", Items: []output.Item{output.List{Entries: created}}})
+	}
+	if len(unflagged) > 0 {
+		sections = append(sections, output.Section{
+			Heading: "Unflagged",
+			Items:   []output.Item{output.List{Entries: unflagged}},
+		})
+	}
+
+	return out.Present(output.Briefing{Title: "Email Tasks", Sections: sections, Results: results})
+}
+
+func (et *EmailTasks) now() time.Time {
+	if et.Now != nil {
+		return et.Now()
+	}
+	return time.Now()
+}
+
+// flaggedQuery searches the whole mailbox for the label, or for starred
+// threads when no label is configured. Gmail search writes spaces and
+// slashes in label names as dashes; IMAP labels are folders, named as is.
+func flaggedQuery(label, backend string) string {
+	if label == "" {
+		return "is:starred"
+	}
+	if backend != config.MailBackendGmail {
+		return `label:"` + label + `"`
+	}
+	return "label:" + strings.NewReplacer(" ", "-", "/", "-").Replace(label)
+}
+
+// trackedThreads returns the thread IDs that already have an active task.
+func trackedThreads(tasks []platform.TodoistTask) map[string]bool {
+	tracked := make(map[string]bool)
+	for _, task := range tasks {
+		markThreads(tracked, task.Description)
+	}
+	return tracked
+}
+
+// markThreads adds the thread IDs marked in a task description to tracked.
+func markThreads(tracked map[string]bool, description string) {
+	for _, line := range strings.Split(description, "\n") {
+		if id, ok := strings.CutPrefix(strings.TrimSpace(line), threadMarker); ok {
+			tracked[id] = true
+		}
+	}
+}
+
+func emailTask(thread platform.EmailThread, settings config.EmailTasksConfig, cfg config.Config) platform.TodoistTask {
+	sender := thread.From.Email
+	if thread.From.Name != "" {
+		sender = fmt.Sprintf("%s <%s>", thread.From.Name, thread.From.Email)
+	}
+
+	task := platform.TodoistTask{
+		Title:       threadSubject(thread),
+		ProjectID:   settings.ProjectID,
+		Priority:    1,
+		Description: emailTaskDescription("From: "+sender, thread.ID, cfg),
+	}
+
+	if due, ok := parseEmailDueDate(thread.Subject+"\n"+thread.Snippet, thread.Date); ok {
+		task.DueDate = &due
+		return task
+	}
+
+	task.DueString = settings.DefaultDue
+	for _, rule := range settings.DueRules {
+		if len(rule.SenderDomains) > 0 && !matchesDomain(thread.From.Email, rule.SenderDomains) {
+			continue
+		}
+		if len(rule.Keywords) > 0 && !containsAnyKeyword(thread.Subject+"\n"+thread.Snippet, rule.Keywords) {
+			continue
+		}
+		task.DueString = rule.Due
+		break
+	}
+	return task
+}
+
+// emailTaskDescription combines a summary line, a link to the thread when
+// there is one and the thread marker.
+func emailTaskDescription(summary, threadID string, cfg config.Config) string {
+	lines := []string{summary}
+	if link := threadURL(cfg, threadID); link != "" {
+		lines = append(lines, fmt.Sprintf("[Open in Gmail](%s)", link))
+	}
+	return strings.Join(append(lines, "", threadMarker+threadID), "\n")
+}
+
+func threadSubject(thread platform.EmailThread) string {
+	if thread.Subject == "" {
+		return "(no subject)"
+	}
+	return thread.Subject
+}
+
+func emailDueSuffix(task platform.TodoistTask) string {
+	switch {
+	case task.DueDate != nil:
+		return " (due " + task.DueDate.Format("Mon 2 Jan") + ")"
+	case task.DueString != "":
+		return " (due " + task.DueString + ")"
+	}
+	return ""
+}
+
+// monthNames matches full and abbreviated English month names.
+const monthNames = `(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept|sep|oct|nov|dec)\.?`
+
+var (
+	isoDatePattern      = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	monthDayPattern     = regexp.MustCompile(`(?i)\b` + monthNames + `\s+(\d{1,2})(?:st|nd|rd|th)?\b(?:,?\s+(\d{4}))?`)
+	dayMonthPattern     = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+` + monthNames + `(?:\s+(\d{4}))?\b`)
+	relativeDatePattern = regexp.MustCompile(`(?i)\b(?:by|due|before|on|until)\s+(today|tomorrow|eod|end of (?:the )?day|end of (?:the )?week|monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`)
+)
+
+// monthsByPrefix maps the first three letters of a month name to the month.
+var monthsByPrefix = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+var weekdaysByName = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday, "friday": time.Friday,
+	"saturday": time.Saturday,
+}
+
+// parseEmailDueDate finds deadlines such as "2026-02-14", "Feb 14",
+// "14 February" or "by Friday" in text and returns the earliest. Dates
+// without a year and relative dates are read from the perspective of sent,
+// the time the latest message arrived.
+func parseEmailDueDate(text string, sent time.Time) (time.Time, bool) {
+	sentDay := startOfDay(sent)
+	var candidates []time.Time
+
+	for _, m := range isoDatePattern.FindAllStringSubmatch(text, -1) {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if d, ok := validDate(year, time.Month(month), day); ok {
+			candidates = append(candidates, d)
+		}
+	}
+
+	addMonthDay := func(monthName, dayStr, yearStr string) {
+		month := monthsByPrefix[strings.ToLower(monthName[:3])]
+		day, _ := strconv.Atoi(dayStr)
+		year := sentDay.Year()
+		if yearStr != "" {
+			year, _ = strconv.Atoi(yearStr)
+		}
+		d, ok := validDate(year, month, day)
+		if !ok {
+			return
+		}
+		// "Jan 5" in a December email means next January.
+		if yearStr == "" && d.Before(sentDay.AddDate(0, -6, 0)) {
+			d = d.AddDate(1, 0, 0)
+		}
+		candidates = append(candidates, d)
+	}
+	for _, m := range monthDayPattern.FindAllStringSubmatch(text, -1) {
+		addMonthDay(m[1], m[2], m[3])
+	}
+	for _, m := range dayMonthPattern.FindAllStringSubmatch(text, -1) {
+		addMonthDay(m[2], m[1], m[3])
+	}
+
+	for _, m := range relativeDatePattern.FindAllStringSubmatch(text, -1) {
+		word := strings.ToLower(m[1])
+		switch {
+		case word == "today", word == "eod", strings.HasSuffix(word, " day"):
+			candidates = append(candidates, sentDay)
+		case word == "tomorrow":
+			candidates = append(candidates, sentDay.AddDate(0, 0, 1))
+		case strings.HasSuffix(word, " week"):
+			candidates = append(candidates, nextWeekday(sentDay, time.Friday))
+		default:
+			candidates = append(candidates, nextWeekday(sentDay, weekdaysByName[word]))
+		}
+	}
+
+	if len(candidates) == 0 {
+		return time.Time{}, false
+	}
+	earliest := candidates[0]
+	for _, d := range candidates[1:] {
+		if d.Before(earliest) {
+			earliest = d
+		}
+	}
+	return earliest, true
+}
+
+// validDate builds a local date, rejecting overflow such as February 30.
+func validDate(year int, month time.Month, day int) (time.Time, bool) {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+	if d.Month() != month || d.Day() != day {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// nextWeekday returns the first day on or after from that falls on weekday.
+func nextWeekday(from time.Time, weekday time.Weekday) time.Time {
+	offset := (int(weekday) - int(from.Weekday()) + 7) % 7
+	return from.AddDate(0, 0, offset)
+}
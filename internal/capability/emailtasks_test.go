@@ -0,0 +1,239 @@
+package capability_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubGmailModifier struct {
+	removed  []string // "threadID:label"
+	modified []string // "threadID +added -removed"
+}
+
+func (s *stubGmailModifier) RemoveLabel(threadID, label string) error {
+	s.removed = append(s.removed, threadID+":"+label)
+	return nil
+}
+
+func (s *stubGmailModifier) ModifyLabels(threadID string, add, remove []string) error {
+	change := threadID
+	for _, label := range add {
+		change += " +" + label
+	}
+	for _, label := range remove {
+		change += " -" + label
+	}
+	s.modified = append(s.modified, change)
+	return nil
+}
+
+func TestEmailTasks_CreatesTaskPerFlaggedThread(t *testing.T) {
+	budget := emailThread("t1", "Q1 budget", "ada@example.com", 9, nil, nil)
+	budget.From.Name = "Ada Lovelace"
+	mail := &stubMailReader{threads: []platform.EmailThread{budget}}
+	creator := &stubTaskCreator{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTasks{Mail: mail, Todoist: &stubTaskReader{}, Creator: creator, Now: fixedNow}
+	cfg := triageConfig()
+	cfg.EmailTasks = config.EmailTasksConfig{ProjectID: "inbox", DefaultDue: "today"}
+
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "is:starred" {
+		t.Errorf("query = %q, want starred threads by default", mail.query)
+	}
+	if len(creator.created) != 1 {
+		t.Fatalf("created %d tasks, want 1", len(creator.created))
+	}
+
+	task := creator.created[0]
+	if task.Title != "Q1 budget" || task.ProjectID != "inbox" || task.DueString != "today" {
+		t.Errorf("task = %+v, want subject as title, configured project and default due", task)
+	}
+	for _, want := range []string{
+		"From: Ada Lovelace <ada@example.com>",
+		"[Open in Gmail](https://mail.google.com/mail/?authuser=me%40example.com#all/t1)",
+		"sam-thread-id: t1",
+	} {
+		if !strings.Contains(task.Description, want) {
+			t.Errorf("description missing %q, got:\n%s", want, task.Description)
+		}
+	}
+	if !strings.Contains(buf.String(), "- Q1 budget (due today)") {
+		t.Errorf("output missing created task, got:\n%s", buf.String())
+	}
+}
+
+func TestEmailTasks_UnreadableBackend(t *testing.T) {
+	mail := &stubMailReader{threads: []platform.EmailThread{emailThread("t1", "Q1 budget", "ada@example.com", 9, nil, nil)}}
+	creator := &stubTaskCreator{}
+
+	et := &capability.EmailTasks{Mail: mail, Creator: creator, Now: fixedNow}
+	if err := et.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creator.created) != 1 {
+		t.Errorf("created %d tasks, want 1 without a task reader", len(creator.created))
+	}
+}
+
+func TestEmailTasks_IdempotentByThreadID(t *testing.T) {
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("t1", "Q1 budget", "ada@example.com", 9, nil, nil),
+		emailThread("t2", "Offsite", "grace@example.com", 9, nil, nil),
+	}}
+	tasks := &stubTaskReader{tasks: []platform.TodoistTask{
+		{ID: "1", Title: "Q1 budget", Description: "From: ada@example.com\n\nsam-thread-id: t1"},
+	}}
+	creator := &stubTaskCreator{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTasks{Mail: mail, Todoist: tasks, Creator: creator, Now: fixedNow}
+	cfg := triageConfig()
+	cfg.EmailTasks.Label = "Follow up/Work"
+
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "label:Follow-up-Work" {
+		t.Errorf("query = %q, want label search with dashes", mail.query)
+	}
+	if len(creator.created) != 1 || creator.created[0].Title != "Offsite" {
+		t.Errorf("created = %+v, want only the untracked thread", creator.created)
+	}
+	if !strings.Contains(buf.String(), "Created 1 tasks, 1 already tracked") {
+		t.Errorf("output missing result, got:\n%s", buf.String())
+	}
+}
+
+func TestEmailTasks_SkipsThreadsWithCompletedTasks(t *testing.T) {
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("t1", "Q1 budget", "ada@example.com", 9, nil, nil),
+		emailThread("t2", "Offsite", "grace@example.com", 9, nil, nil),
+	}}
+	completed := &stubCompletedReader{tasks: []platform.CompletedTask{
+		{ID: "1", Title: "Q1 budget", Description: "From: ada@example.com\n\nsam-thread-id: t1"},
+	}}
+	creator := &stubTaskCreator{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTasks{Mail: mail, Todoist: &stubTaskReader{}, Creator: creator, Completed: completed, Now: fixedNow}
+	if err := et.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(creator.created) != 1 || creator.created[0].Title != "Offsite" {
+		t.Errorf("created = %+v, want no new task for the thread whose task was completed", creator.created)
+	}
+	if want := fixedNow().Add(-90 * 24 * time.Hour); !completed.since.Equal(want) {
+		t.Errorf("completed since = %v, want %v", completed.since, want)
+	}
+	if !strings.Contains(buf.String(), "Created 1 tasks, 1 already tracked") {
+		t.Errorf("output missing result, got:\n%s", buf.String())
+	}
+}
+
+func TestEmailTasks_IMAPLabelIsFolderName(t *testing.T) {
+	mail := &stubMailReader{}
+	et := &capability.EmailTasks{Mail: mail, Todoist: &stubTaskReader{}, Creator: &stubTaskCreator{}, Now: fixedNow}
+	cfg := triageConfig()
+	cfg.Mail.Backend = config.MailBackendIMAP
+	cfg.EmailTasks.Label = "Action Needed"
+
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mail.query != `label:"Action Needed"` {
+		t.Errorf("query = %q, want the folder name quoted as is", mail.query)
+	}
+}
+
+func TestEmailTasks_DueDates(t *testing.T) {
+	rules := []config.EmailDueRule{
+		{SenderDomains: []string{"vendor.example"}, Due: "in 7 days"},
+		{Keywords: []string{"invoice"}, Due: "tomorrow"},
+	}
+
+	tests := []struct {
+		name, subject, snippet, from string
+		wantDate                     *time.Time
+		wantString                   string
+	}{
+		{"iso date", "Report due 2026-02-20", "", "ada@example.com", day(20), ""},
+		{"month day", "Slides", "Could you send them by Feb 13th?", "ada@example.com", day(13), ""},
+		{"day month", "Review", "Deadline is 12 February", "ada@example.com", day(12), ""},
+		{"weekday", "Contract", "Please sign by Friday", "ada@example.com", day(13), ""},
+		{"tomorrow", "Quick one", "need this by tomorrow", "ada@example.com", day(10), ""},
+		{"earliest wins", "Due Feb 20", "draft by Wednesday", "ada@example.com", day(11), ""},
+		{"invalid date ignored", "Order 2026-02-30", "", "ada@example.com", nil, ""},
+		{"month word in text ignored", "Please decide 3 options", "", "ada@example.com", nil, ""},
+		{"sender rule", "Invoice 42", "", "billing@vendor.example", nil, "in 7 days"},
+		{"keyword rule", "Invoice 43", "", "ada@example.com", nil, "tomorrow"},
+		{"no date", "Hello", "", "ada@example.com", nil, ""},
+	}
+
+	for _, tt := range tests {
+		thread := emailThread("t1", tt.subject, tt.from, 9, nil, nil)
+		thread.Snippet = tt.snippet
+		creator := &stubTaskCreator{}
+		et := &capability.EmailTasks{
+			Mail:    &stubMailReader{threads: []platform.EmailThread{thread}},
+			Todoist: &stubTaskReader{},
+			Creator: creator,
+			Now:     fixedNow,
+		}
+		cfg := triageConfig()
+		cfg.EmailTasks.DueRules = rules
+
+		if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		task := creator.created[0]
+		switch {
+		case tt.wantDate == nil && task.DueDate != nil:
+			t.Errorf("%s: due date = %v, want none", tt.name, task.DueDate)
+		case tt.wantDate != nil && (task.DueDate == nil || !task.DueDate.Equal(*tt.wantDate)):
+			t.Errorf("%s: due date = %v, want %v", tt.name, task.DueDate, *tt.wantDate)
+		}
+		if task.DueString != tt.wantString {
+			t.Errorf("%s: due string = %q, want %q", tt.name, task.DueString, tt.wantString)
+		}
+	}
+}
+
+func TestEmailTasks_RemovesLabelOnceTracked(t *testing.T) {
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("t1", "Q1 budget", "ada@example.com", 9, nil, nil),
+		emailThread("t2", "Offsite", "grace@example.com", 9, nil, nil),
+	}}
+	tasks := &stubTaskReader{tasks: []platform.TodoistTask{{ID: "1", Description: "sam-thread-id: t1"}}}
+	modifier := &stubGmailModifier{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTasks{Mail: mail, Todoist: tasks, Creator: &stubTaskCreator{}, Modifier: modifier, Now: fixedNow}
+	cfg := triageConfig()
+	cfg.EmailTasks.RemoveLabel = true
+
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(modifier.removed, ",") != "t1:STARRED,t2:STARRED" {
+		t.Errorf("removed = %v, want star removed from both threads", modifier.removed)
+	}
+	if !strings.Contains(buf.String(), "## Unflagged\n\n- Q1 budget\n- Offsite") {
+		t.Errorf("output missing unflagged threads, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,394 @@
+package capability
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const defaultTopThreads = 5
+
+// triageBuckets lists the buckets in briefing order.
+var triageBuckets = []struct{ name, heading, summary string }{
+	{config.BucketNeedsReply, "Needs Reply", "need a reply"},
+	{config.BucketWaiting, "Waiting on Others", "waiting on others"},
+	{config.BucketFYI, "FYI", "FYI"},
+	{config.BucketNewsletter, "Newsletters", "newsletters"},
+	{config.BucketNotification, "Notifications", "notifications"},
+}
+
+// EmailTriageResult records the bucket of each inbox thread and the changes
+// actions made, or would make in a dry run, for machine-readable output.
+type EmailTriageResult struct {
+	Threads  []TriagedThread `json:"threads,omitempty" yaml:"threads,omitempty"`
+	Modified []ThreadChange  `json:"modified,omitempty" yaml:"modified,omitempty"`
+	DryRun   bool            `json:"dry_run,omitempty" yaml:"dry_run,omitempty"`
+}
+
+// TriagedThread is an inbox thread and the bucket it was sorted into.
+type TriagedThread struct {
+	ThreadID string `json:"thread_id" yaml:"thread_id"`
+	Subject  string `json:"subject" yaml:"subject"`
+	Bucket   string `json:"bucket" yaml:"bucket"`
+}
+
+// ThreadChange is the labels an action added to and removed from a thread.
+// Archiving removes INBOX and marking read removes UNREAD.
+type ThreadChange struct {
+	ThreadID string   `json:"thread_id" yaml:"thread_id"`
+	Subject  string   `json:"subject" yaml:"subject"`
+	Added    []string `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty" yaml:"removed,omitempty"`
+}
+
+// EmailTriage buckets inbox threads into needs-reply, waiting-on-others,
+// FYI, newsletters and notifications.
+type EmailTriage struct {
+	Mail platform.MailReader
+	// Modifier applies email_triage.actions. Required when actions are
+	// configured, unless DryRun is set.
+	Modifier platform.GmailModifier
+	// DryRun lists the threads actions would change without changing them.
+	DryRun bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (et *EmailTriage) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	threads, err := et.Mail.InboxThreads(cfg.EmailTriage.Query)
+	if err != nil {
+		return fmt.Errorf("fetching inbox threads: %w", err)
+	}
+
+	if len(threads) == 0 {
+		return out.Present(output.Briefing{
+			Title:    "Email Triage",
+			Sections: []output.Section{{Heading: "Summary", Items: []output.Item{output.Status{Level: output.LevelOK, Text: "Inbox zero"}}}},
+			Results:  EmailTriageResult{DryRun: et.DryRun},
+		})
+	}
+
+	now := et.now()
+	me := newMailbox(cfg)
+	results := EmailTriageResult{DryRun: et.DryRun}
+	buckets := make(map[string][]platform.EmailThread)
+	for _, thread := range threads {
+		bucket := triageBucket(thread, cfg.EmailTriage.Rules, me, now)
+		buckets[bucket] = append(buckets[bucket], thread)
+		results.Threads = append(results.Threads, TriagedThread{ThreadID: thread.ID, Subject: threadSubject(thread), Bucket: bucket})
+	}
+
+	top := cfg.EmailTriage.TopThreads
+	if top == 0 {
+		top = defaultTopThreads
+	}
+
+	var counts []string
+	var sections []output.Section
+	for _, b := range triageBuckets {
+		bucket := buckets[b.name]
+		counts = append(counts, fmt.Sprintf("%d %s", len(bucket), b.summary))
+		if len(bucket) == 0 {
+			continue
+		}
+
+		// Replies and follow-ups get more urgent with age; the rest is
+		// most useful newest first.
+		oldestFirst := b.name == config.BucketNeedsReply || b.name == config.BucketWaiting
+		sort.SliceStable(bucket, func(i, j int) bool {
+			if oldestFirst {
+				return bucket[i].Date.Before(bucket[j].Date)
+			}
+			return bucket[i].Date.After(bucket[j].Date)
+		})
+
+		var lines []string
+		for i, thread := range bucket {
+			if i == top {
+				lines = append(lines, fmt.Sprintf("…and %d more", len(bucket)-top))
+				break
+			}
+			lines = append(lines, formatThreadLine(thread, cfg, now))
+		}
+
+		sections = append(sections, output.Section{
+			Heading: fmt.Sprintf("%s (%d)", b.heading, len(bucket)),
+			Items:   []output.Item{output.List{Entries: lines}},
+		})
+	}
+
+	sections = append([]output.Section{{Heading: "Summary", Items: []output.Item{output.Paragraph(strings.Join(counts, ", "))}}}, sections...)
+
+	if len(cfg.EmailTriage.Actions) > 0 {
+		section, err := et.applyActions(buckets, cfg, now, &results)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, section)
+	}
+
+	return out.Present(output.Briefing{Title: "Email Triage", Sections: sections, Results: results})
+}
+
+// applyActions runs the configured actions on each bucket's threads, or only
+// lists them in a dry run, and reports every change, recording it in results.
+func (et *EmailTriage) applyActions(buckets map[string][]platform.EmailThread, cfg config.Config, now time.Time, results *EmailTriageResult) (output.Section, error) {
+	heading := "Actions"
+	if et.DryRun {
+		heading = "Actions (dry run)"
+	} else if et.Modifier == nil {
+		return output.Section{}, fmt.Errorf("email_triage.actions need a Gmail modifier")
+	}
+
+	var lines []string
+	for _, b := range triageBuckets {
+		for _, thread := range buckets[b.name] {
+			add, remove := threadChanges(thread, b.name, cfg.EmailTriage.Actions, now)
+			if len(add) == 0 && len(remove) == 0 {
+				continue
+			}
+			if !et.DryRun {
+				if err := et.Modifier.ModifyLabels(thread.ID, add, remove); err != nil {
+					return output.Section{}, err
+				}
+			}
+			lines = append(lines, threadLink(thread, cfg)+" — "+describeChanges(add, remove))
+			results.Modified = append(results.Modified, ThreadChange{ThreadID: thread.ID, Subject: threadSubject(thread), Added: add, Removed: remove})
+		}
+	}
+
+	if len(lines) == 0 {
+		return output.Section{Heading: heading, Items: []output.Item{output.Paragraph("No threads to change")}}, nil
+	}
+	return output.Section{Heading: heading, Items: []output.Item{output.List{Entries: lines}}}, nil
+}
+
+func (et *EmailTriage) now() time.Time {
+	if et.Now != nil {
+		return et.Now()
+	}
+	return time.Now()
+}
+
+// mailbox is the set of addresses that belong to the user.
+type mailbox map[string]bool
+
+func newMailbox(cfg config.Config) mailbox {
+	me := make(mailbox)
+	for _, address := range cfg.MailAddresses() {
+		me[strings.ToLower(address)] = true
+	}
+	return me
+}
+
+func (m mailbox) in(addresses []platform.EmailAddress) bool {
+	for _, a := range addresses {
+		if m[strings.ToLower(a.Email)] {
+			return true
+		}
+	}
+	return false
+}
+
+// triageBucket returns the bucket of the first matching rule, falling back to
+// built-in heuristics.
+func triageBucket(thread platform.EmailThread, rules []config.TriageRule, me mailbox, now time.Time) string {
+	for _, rule := range rules {
+		if ruleMatches(rule, thread, me, now) {
+			return rule.Bucket
+		}
+	}
+	return defaultTriageBucket(thread, me)
+}
+
+func ruleMatches(rule config.TriageRule, thread platform.EmailThread, me mailbox, now time.Time) bool {
+	if len(rule.SenderDomains) > 0 && !matchesDomain(thread.From.Email, rule.SenderDomains) {
+		return false
+	}
+	if len(rule.Labels) > 0 && !hasAnyLabel(thread.Labels, rule.Labels) {
+		return false
+	}
+	if len(rule.Keywords) > 0 && !containsAnyKeyword(thread.Subject+"\n"+thread.Snippet, rule.Keywords) {
+		return false
+	}
+	if rule.Recipient != "" {
+		latest := latestMessage(thread)
+		recipients := latest.To
+		if rule.Recipient == "cc" {
+			recipients = latest.Cc
+		}
+		if !me.in(recipients) {
+			return false
+		}
+	}
+	if rule.OlderThanDays > 0 && daysBetween(thread.Date, now) <= rule.OlderThanDays {
+		return false
+	}
+	return true
+}
+
+// defaultTriageBucket classifies threads no rule matched. Automated mail is
+// recognised by Gmail's categories, no-reply senders and List-Unsubscribe;
+// conversations by who sent the latest message and whether you were in To.
+func defaultTriageBucket(thread platform.EmailThread, me mailbox) string {
+	switch {
+	case hasAnyLabel(thread.Labels, []string{"CATEGORY_PROMOTIONS"}):
+		return config.BucketNewsletter
+	case isAutomatedSender(thread.From.Email):
+		return config.BucketNotification
+	case len(thread.ListUnsubscribe) > 0:
+		return config.BucketNewsletter
+	case hasAnyLabel(thread.Labels, []string{"CATEGORY_UPDATES", "CATEGORY_SOCIAL", "CATEGORY_FORUMS"}):
+		return config.BucketNotification
+	}
+
+	latest := latestMessage(thread)
+	switch {
+	case me[strings.ToLower(latest.From.Email)]:
+		return config.BucketWaiting
+	case me.in(latest.To):
+		return config.BucketNeedsReply
+	}
+	return config.BucketFYI
+}
+
+// threadChanges returns the labels to add and remove for a thread in bucket,
+// skipping changes that would do nothing.
+func threadChanges(thread platform.EmailThread, bucket string, actions []config.TriageAction, now time.Time) (add, remove []string) {
+	seen := make(map[string]bool)
+	for _, action := range actions {
+		if action.Bucket != bucket {
+			continue
+		}
+		if action.OlderThanDays > 0 && daysBetween(thread.Date, now) <= action.OlderThanDays {
+			continue
+		}
+		if action.Label != "" && !hasAnyLabel(thread.Labels, []string{action.Label}) && !seen["+"+action.Label] {
+			seen["+"+action.Label] = true
+			add = append(add, action.Label)
+		}
+		if action.MarkRead && thread.Unread && !seen["-UNREAD"] {
+			seen["-UNREAD"] = true
+			remove = append(remove, "UNREAD")
+		}
+		if action.Archive && !seen["-INBOX"] {
+			seen["-INBOX"] = true
+			remove = append(remove, "INBOX")
+		}
+	}
+	return add, remove
+}
+
+// describeChanges renders label changes as "label Receipts, mark read, archive".
+func describeChanges(add, remove []string) string {
+	var parts []string
+	for _, label := range add {
+		parts = append(parts, "label "+label)
+	}
+	for _, label := range remove {
+		switch label {
+		case "UNREAD":
+			parts = append(parts, "mark read")
+		case "INBOX":
+			parts = append(parts, "archive")
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+var automatedSenderParts = []string{"noreply", "no-reply", "donotreply", "do-not-reply", "notification", "alerts", "mailer-daemon"}
+
+func isAutomatedSender(email string) bool {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	for _, part := range automatedSenderParts {
+		if strings.Contains(local, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesDomain(email string, domains []string) bool {
+	_, domain, ok := strings.Cut(strings.ToLower(email), "@")
+	if !ok {
+		return false
+	}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "@"))
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyLabel(labels, wanted []string) bool {
+	for _, l := range labels {
+		for _, w := range wanted {
+			if strings.EqualFold(l, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAnyKeyword(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+	for _, k := range keywords {
+		if strings.Contains(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+func latestMessage(thread platform.EmailThread) platform.EmailMessage {
+	if len(thread.Messages) == 0 {
+		return platform.EmailMessage{From: thread.From}
+	}
+	return thread.Messages[len(thread.Messages)-1]
+}
+
+// formatThreadLine renders a thread as "[Subject](link) — Sender, 2 days ago".
+func formatThreadLine(thread platform.EmailThread, cfg config.Config, now time.Time) string {
+	return fmt.Sprintf("%s — %s, %s", threadLink(thread, cfg), senderName(thread.From), formatAge(thread.Date, now))
+}
+
+// threadLink renders the subject as a Markdown link to the thread when the
+// mail backend has a web UI to link to.
+func threadLink(thread platform.EmailThread, cfg config.Config) string {
+	if link := threadURL(cfg, thread.ID); link != "" {
+		return fmt.Sprintf("[%s](%s)", threadSubject(thread), link)
+	}
+	return threadSubject(thread)
+}
+
+// threadURL links to a thread in the Gmail web UI, opening the right account
+// when several are signed in. IMAP threads have no URL.
+func threadURL(cfg config.Config, threadID string) string {
+	if cfg.Mail.BackendName() != config.MailBackendGmail {
+		return ""
+	}
+	if cfg.Gmail.User == "" {
+		return "https://mail.google.com/mail/#all/" + threadID
+	}
+	return "https://mail.google.com/mail/?authuser=" + url.QueryEscape(cfg.Gmail.User) + "#all/" + threadID
+}
+
+func formatAge(t, now time.Time) string {
+	switch days := daysBetween(t, now); days {
+	case 0:
+		return "today"
+	case 1:
+		return "yesterday"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
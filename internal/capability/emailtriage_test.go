@@ -0,0 +1,286 @@
+package capability_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubMailReader struct {
+	threads []platform.EmailThread
+	query   string
+	err     error
+}
+
+func (s *stubMailReader) InboxThreads(query string) ([]platform.EmailThread, error) {
+	s.query = query
+	return s.threads, s.err
+}
+
+func (s *stubMailReader) Threads(query string) ([]platform.EmailThread, error) {
+	s.query = query
+	return s.threads, s.err
+}
+
+func addr(email string) platform.EmailAddress {
+	return platform.EmailAddress{Email: email}
+}
+
+// emailThread builds a single-message thread received on day d at 09:00.
+func emailThread(id, subject, from string, d int, to, cc []platform.EmailAddress, labels ...string) platform.EmailThread {
+	date := at(d, 9, 0)
+	msg := platform.EmailMessage{ID: id, From: addr(from), To: to, Cc: cc, Subject: subject, Date: date, Labels: labels}
+	return platform.EmailThread{
+		ID:       id,
+		Subject:  subject,
+		From:     addr(from),
+		Date:     date,
+		Labels:   labels,
+		Messages: []platform.EmailMessage{msg},
+	}
+}
+
+func triageConfig() config.Config {
+	return config.Config{Gmail: config.GmailConfig{User: "me@example.com", Aliases: []string{"sam@example.org"}}}
+}
+
+func TestEmailTriage_DefaultBuckets(t *testing.T) {
+	me := []platform.EmailAddress{addr("me@example.com")}
+	others := []platform.EmailAddress{addr("team@example.com")}
+
+	sent := emailThread("t2", "Contract draft", "legal@example.com", 6, me, nil)
+	sent.Messages = append(sent.Messages, platform.EmailMessage{From: addr("sam@example.org"), To: others, Date: at(7, 9, 0)})
+
+	newsletter := emailThread("t4", "Go Weekly #600", "editor@golangweekly.com", 10, me, nil)
+	newsletter.ListUnsubscribe = []string{"https://golangweekly.com/unsubscribe"}
+
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("t1", "Q1 budget", "ada@example.com", 9, me, nil),
+		sent,
+		emailThread("t3", "Offsite plans", "grace@example.com", 10, others, me),
+		newsletter,
+		emailThread("t5", "Build passed", "notifications@github.com", 10, me, nil, "CATEGORY_UPDATES"),
+		emailThread("t6", "50% off", "deals@shop.example", 10, me, nil, "CATEGORY_PROMOTIONS"),
+	}}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: mail, Now: fixedNow}
+	if err := et.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"1 need a reply, 1 waiting on others, 1 FYI, 2 newsletters, 1 notifications",
+		"## Needs Reply (1)\n\n- [Q1 budget](https://mail.google.com/mail/?authuser=me%40example.com#all/t1) — ada@example.com, yesterday",
+		"## Waiting on Others (1)\n\n- [Contract draft]",
+		"## FYI (1)\n\n- [Offsite plans]",
+		"## Newsletters (2)\n\n- [Go Weekly #600]",
+		"## Notifications (1)\n\n- [Build passed]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailTriage_RulesTakePrecedence(t *testing.T) {
+	me := []platform.EmailAddress{addr("me@example.com")}
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("t1", "Invoice 42", "billing@vendor.example.com", 9, me, nil),
+		emailThread("t2", "Urgent: prod down", "oncall@example.com", 2, nil, me),
+		emailThread("t3", "Lunch?", "ada@example.com", 9, me, nil),
+	}}
+
+	cfg := triageConfig()
+	cfg.EmailTriage = config.EmailTriageConfig{
+		Query: "newer_than:30d",
+		Rules: []config.TriageRule{
+			{Bucket: config.BucketFYI, SenderDomains: []string{"example.com"}, Keywords: []string{"invoice"}},
+			{Bucket: config.BucketNeedsReply, Recipient: "cc", Keywords: []string{"urgent"}, OlderThanDays: 3},
+		},
+	}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: mail, Now: fixedNow}
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "newer_than:30d" {
+		t.Errorf("query = %q, want configured query", mail.query)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"2 need a reply, 0 waiting on others, 1 FYI",
+		"## Needs Reply (2)\n\n- [Urgent: prod down]",
+		"## FYI (1)\n\n- [Invoice 42]",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestEmailTriage_CapsThreadsPerBucket(t *testing.T) {
+	me := []platform.EmailAddress{addr("me@example.com")}
+	var threads []platform.EmailThread
+	for i := 1; i <= 4; i++ {
+		threads = append(threads, emailThread(fmt.Sprintf("t%d", i), fmt.Sprintf("Question %d", i), "ada@example.com", i, me, nil))
+	}
+
+	cfg := triageConfig()
+	cfg.EmailTriage.TopThreads = 2
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: &stubMailReader{threads: threads}, Now: fixedNow}
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "Question 1") || !strings.Contains(got, "Question 2") || strings.Contains(got, "Question 3") {
+		t.Errorf("want the two oldest questions only, got:\n%s", got)
+	}
+	if !strings.Contains(got, "- …and 2 more") {
+		t.Errorf("want truncation note, got:\n%s", got)
+	}
+}
+
+func TestEmailTriage_InboxZero(t *testing.T) {
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: &stubMailReader{}, Now: fixedNow}
+	if err := et.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Inbox zero") {
+		t.Errorf("output = %q, want inbox zero message", buf.String())
+	}
+}
+
+func TestEmailTriage_GmailError(t *testing.T) {
+	et := &capability.EmailTriage{Mail: &stubMailReader{err: fmt.Errorf("Gmail API returned 403")}, Now: fixedNow}
+	err := et.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("error = %v, want mail failure", err)
+	}
+}
+
+func triageActionThreads() *stubMailReader {
+	me := []platform.EmailAddress{addr("me@example.com")}
+	oldNewsletter := emailThread("t1", "Go Weekly #598", "editor@golangweekly.com", 1, me, nil, "CATEGORY_PROMOTIONS")
+	newNewsletter := emailThread("t2", "Go Weekly #600", "editor@golangweekly.com", 9, me, nil, "CATEGORY_PROMOTIONS")
+	build := emailThread("t3", "Build passed", "notifications@github.com", 10, me, nil, "CATEGORY_UPDATES", "UNREAD")
+	build.Unread = true
+	return &stubMailReader{threads: []platform.EmailThread{
+		oldNewsletter,
+		newNewsletter,
+		build,
+		emailThread("t4", "Build failed", "notifications@github.com", 10, me, nil, "CATEGORY_UPDATES", "CI"),
+		emailThread("t5", "Q1 budget", "ada@example.com", 9, me, nil),
+	}}
+}
+
+func triageActionConfig() config.Config {
+	cfg := triageConfig()
+	cfg.EmailTriage.Actions = []config.TriageAction{
+		{Bucket: config.BucketNewsletter, OlderThanDays: 7, Archive: true},
+		{Bucket: config.BucketNotification, Label: "CI", MarkRead: true},
+	}
+	return cfg
+}
+
+func TestEmailTriage_AppliesActions(t *testing.T) {
+	modifier := &stubGmailModifier{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: triageActionThreads(), Modifier: modifier, Now: fixedNow}
+	if err := et.Run(triageActionConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "t1 -INBOX,t3 +CI -UNREAD"
+	if got := strings.Join(modifier.modified, ","); got != want {
+		t.Errorf("modified = %q, want %q", got, want)
+	}
+
+	wantSection := "## Actions\n\n" +
+		"- [Go Weekly #598](https://mail.google.com/mail/?authuser=me%40example.com#all/t1) — archive\n" +
+		"- [Build passed](https://mail.google.com/mail/?authuser=me%40example.com#all/t3) — label CI, mark read\n"
+	if !strings.Contains(buf.String(), wantSection) {
+		t.Errorf("output missing %q, got:\n%s", wantSection, buf.String())
+	}
+}
+
+func TestEmailTriage_Results(t *testing.T) {
+	out := &recordingPresenter{}
+	et := &capability.EmailTriage{Mail: triageActionThreads(), Modifier: &stubGmailModifier{}, Now: fixedNow}
+	if err := et.Run(triageActionConfig(), config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, ok := out.briefing.Results.(capability.EmailTriageResult)
+	if !ok {
+		t.Fatalf("results = %T, want EmailTriageResult", out.briefing.Results)
+	}
+	if len(results.Threads) != 5 || results.Threads[4] != (capability.TriagedThread{ThreadID: "t5", Subject: "Q1 budget", Bucket: config.BucketNeedsReply}) {
+		t.Errorf("threads = %+v, want every thread with its bucket", results.Threads)
+	}
+	want := []capability.ThreadChange{
+		{ThreadID: "t1", Subject: "Go Weekly #598", Removed: []string{"INBOX"}},
+		{ThreadID: "t3", Subject: "Build passed", Added: []string{"CI"}, Removed: []string{"UNREAD"}},
+	}
+	if !reflect.DeepEqual(results.Modified, want) || results.DryRun {
+		t.Errorf("modified = %+v, want %+v", results.Modified, want)
+	}
+}
+
+func TestEmailTriage_DryRunListsWithoutModifying(t *testing.T) {
+	modifier := &stubGmailModifier{}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: triageActionThreads(), Modifier: modifier, DryRun: true, Now: fixedNow}
+	if err := et.Run(triageActionConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(modifier.modified) != 0 {
+		t.Errorf("modified = %v, want no changes in a dry run", modifier.modified)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "## Actions (dry run)\n\n- [Go Weekly #598]") || !strings.Contains(got, "— label CI, mark read") {
+		t.Errorf("output missing dry run actions, got:\n%s", got)
+	}
+}
+
+func TestEmailTriage_ActionsNeedModifier(t *testing.T) {
+	et := &capability.EmailTriage{Mail: triageActionThreads(), Now: fixedNow}
+	if err := et.Run(triageActionConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err == nil {
+		t.Error("expected error when actions are configured without a modifier")
+	}
+}
+
+func TestEmailTriage_IMAPBackend(t *testing.T) {
+	me := []platform.EmailAddress{addr("me@fastmail.example")}
+	mail := &stubMailReader{threads: []platform.EmailThread{
+		emailThread("q1@example.com", "Q1 budget", "ada@example.com", 9, me, nil),
+	}}
+	cfg := config.Config{Mail: config.MailConfig{Backend: "imap", IMAP: config.IMAPConfig{Address: "me@fastmail.example"}}}
+
+	var buf bytes.Buffer
+	et := &capability.EmailTriage{Mail: mail, Now: fixedNow}
+	if err := et.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "## Needs Reply (1)\n\n- Q1 budget — ada@example.com, yesterday"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q (IMAP address as you, no Gmail link), got:\n%s", want, buf.String())
+	}
+}
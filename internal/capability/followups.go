@@ -0,0 +1,192 @@
+package capability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const (
+	defaultFollowUpBusinessDays = 3
+	defaultFollowUpLookbackDays = 30
+)
+
+// FollowUps lists sent threads that have gone unanswered for a number of
+// business days.
+type FollowUps struct {
+	Mail platform.MailReader
+	// Todoist and Creator are used only with CreateTasks. Todoist finds
+	// follow-up tasks created on earlier runs in Creator's backend; when it
+	// is nil, as for backends that cannot be read, every run creates them.
+	Todoist platform.TaskReader
+	Creator platform.TaskCreator
+	// CreateTasks adds a "Follow up" Todoist task for each waiting thread.
+	CreateTasks bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (fu *FollowUps) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	settings := followUpSettings(cfg.FollowUps)
+
+	threads, err := fu.Mail.Threads(fmt.Sprintf("in:sent newer_than:%dd", settings.LookbackDays))
+	if err != nil {
+		return fmt.Errorf("fetching sent threads: %w", err)
+	}
+
+	now := fu.now()
+	waiting := awaitingReply(threads, newMailbox(cfg), now, settings.AfterBusinessDays)
+
+	if len(waiting) == 0 {
+		return out.Present(output.Briefing{
+			Title:    "Follow-ups",
+			Sections: []output.Section{{Heading: "Waiting for Reply", Items: []output.Item{output.Paragraph("Nothing waiting for a reply")}}},
+		})
+	}
+
+	var lines []string
+	for _, thread := range waiting {
+		lines = append(lines, formatFollowUpLine(thread, cfg, now))
+	}
+
+	sections := []output.Section{{Heading: "Waiting for Reply", Items: []output.Item{output.List{Entries: lines}}}}
+
+	var results EmailTaskResult
+	if fu.CreateTasks {
+		result, err := fu.createFollowUpTasks(waiting, settings, cfg, &results)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, output.Section{Heading: "Follow-up Tasks", Items: []output.Item{output.Paragraph(result)}})
+	}
+
+	return out.Present(output.Briefing{Title: "Follow-ups", Sections: sections, Results: results})
+}
+
+// createFollowUpTasks creates a task due today for each thread that does not
+// have one yet, recording them in results.
+func (fu *FollowUps) createFollowUpTasks(threads []platform.EmailThread, settings config.FollowUpsConfig, cfg config.Config, results *EmailTaskResult) (string, error) {
+	tracked := make(map[string]bool)
+	if fu.Todoist != nil {
+		tasks, err := fu.Todoist.Tasks("")
+		if err != nil {
+			return "", fmt.Errorf("fetching todoist tasks: %w", err)
+		}
+		tracked = trackedThreads(tasks)
+	}
+
+	created := 0
+	for _, thread := range threads {
+		if tracked[thread.ID] {
+			results.Skipped = append(results.Skipped, SkippedThread{ThreadID: thread.ID, Subject: threadSubject(thread), Reason: "already tracked"})
+			continue
+		}
+
+		latest := latestMessage(thread)
+		task := platform.TodoistTask{
+			Title:       "Follow up: " + threadSubject(thread),
+			ProjectID:   settings.ProjectID,
+			DueString:   "today",
+			Priority:    1,
+			Description: emailTaskDescription("Sent to: "+formatRecipients(latest.To), thread.ID, cfg),
+		}
+		taskID, err := fu.Creator.CreateTask(task)
+		if err != nil {
+			return "", fmt.Errorf("creating follow-up task %q: %w", task.Title, err)
+		}
+		results.Created = append(results.Created, ThreadTask{ThreadID: thread.ID, TaskID: taskID, Title: task.Title})
+		created++
+	}
+
+	result := fmt.Sprintf("Created %d follow-up tasks", created)
+	if skipped := len(threads) - created; skipped > 0 {
+		result += fmt.Sprintf(", %d already tracked", skipped)
+	}
+	return result, nil
+}
+
+func (fu *FollowUps) now() time.Time {
+	if fu.Now != nil {
+		return fu.Now()
+	}
+	return time.Now()
+}
+
+// followUpSettings fills in defaults for unset fields.
+func followUpSettings(cfg config.FollowUpsConfig) config.FollowUpsConfig {
+	if cfg.AfterBusinessDays == 0 {
+		cfg.AfterBusinessDays = defaultFollowUpBusinessDays
+	}
+	if cfg.LookbackDays == 0 {
+		cfg.LookbackDays = defaultFollowUpLookbackDays
+	}
+	return cfg
+}
+
+// awaitingReply returns threads whose latest message you sent at least
+// minBusinessDays ago to someone else, oldest first.
+func awaitingReply(threads []platform.EmailThread, me mailbox, now time.Time, minBusinessDays int) []platform.EmailThread {
+	var result []platform.EmailThread
+	for _, thread := range threads {
+		latest := latestMessage(thread)
+		if !me[strings.ToLower(latest.From.Email)] || onlyTo(latest, me) {
+			continue
+		}
+		if businessDaysBetween(latest.Date, now) >= minBusinessDays {
+			result = append(result, thread)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Date.Before(result[j].Date) })
+	return result
+}
+
+// onlyTo reports whether every recipient of msg is you, e.g. notes to self.
+func onlyTo(msg platform.EmailMessage, me mailbox) bool {
+	for _, a := range append(append([]platform.EmailAddress{}, msg.To...), msg.Cc...) {
+		if !me[strings.ToLower(a.Email)] {
+			return false
+		}
+	}
+	return true
+}
+
+// businessDaysBetween counts weekdays after from's day up to and including
+// to's day.
+func businessDaysBetween(from, to time.Time) int {
+	count := 0
+	end := startOfDay(to)
+	for d := startOfDay(from).AddDate(0, 0, 1); !d.After(end); d = d.AddDate(0, 0, 1) {
+		if !isWeekend(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// formatFollowUpLine renders a thread as
+// "[Subject](link) — to Ada Lovelace, sent 4 business days ago".
+func formatFollowUpLine(thread platform.EmailThread, cfg config.Config, now time.Time) string {
+	latest := latestMessage(thread)
+	return fmt.Sprintf("%s — to %s, sent %d business days ago",
+		threadLink(thread, cfg), formatRecipients(latest.To), businessDaysBetween(latest.Date, now))
+}
+
+func formatRecipients(addresses []platform.EmailAddress) string {
+	var names []string
+	for _, a := range addresses {
+		if a.Name != "" {
+			names = append(names, a.Name)
+		} else {
+			names = append(names, a.Email)
+		}
+	}
+	if len(names) == 0 {
+		return "(no recipients)"
+	}
+	return strings.Join(names, ", ")
+}
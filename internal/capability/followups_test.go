@@ -0,0 +1,108 @@
+package capability_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+// sentThread builds a thread whose last message you sent on day d.
+func sentThread(id, subject string, d int, to ...platform.EmailAddress) platform.EmailThread {
+	thread := emailThread(id, subject, "ada@example.com", d-1, []platform.EmailAddress{addr("me@example.com")}, nil)
+	thread.Messages = append(thread.Messages, platform.EmailMessage{
+		From: addr("me@example.com"),
+		To:   to,
+		Date: at(d, 15, 0),
+	})
+	thread.Date = at(d, 15, 0)
+	return thread
+}
+
+func followUpThreads() *stubMailReader {
+	ada := platform.EmailAddress{Name: "Ada Lovelace", Email: "ada@example.com"}
+
+	answered := sentThread("t4", "Lunch", 3, ada)
+	answered.Messages = append(answered.Messages, platform.EmailMessage{From: ada, To: []platform.EmailAddress{addr("me@example.com")}, Date: at(4, 9, 0)})
+
+	return &stubMailReader{threads: []platform.EmailThread{
+		sentThread("t1", "Contract draft", 4, ada),
+		sentThread("t2", "Budget question", 6, ada),
+		sentThread("t3", "Note to self", 2, addr("me@example.com")),
+		answered,
+		sentThread("t5", "Intro", 2, addr("grace@example.com")),
+	}}
+}
+
+func TestFollowUps_ListsUnansweredAfterBusinessDays(t *testing.T) {
+	mail := followUpThreads()
+
+	var buf bytes.Buffer
+	fu := &capability.FollowUps{Mail: mail, Now: fixedNow}
+	if err := fu.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "in:sent newer_than:30d" {
+		t.Errorf("query = %q, want sent threads from the last 30 days", mail.query)
+	}
+
+	want := "## Waiting for Reply\n\n" +
+		"- [Intro](https://mail.google.com/mail/?authuser=me%40example.com#all/t5) — to grace@example.com, sent 6 business days ago\n" +
+		"- [Contract draft](https://mail.google.com/mail/?authuser=me%40example.com#all/t1) — to Ada Lovelace, sent 4 business days ago\n"
+	got := buf.String()
+	if !strings.Contains(got, want) {
+		t.Errorf("output missing %q, got:\n%s", want, got)
+	}
+	for _, skipped := range []string{"Budget question", "Note to self", "Lunch"} {
+		if strings.Contains(got, skipped) {
+			t.Errorf("output should not list %q, got:\n%s", skipped, got)
+		}
+	}
+}
+
+func TestFollowUps_CreatesTasksOnce(t *testing.T) {
+	creator := &stubTaskCreator{}
+	tasks := &stubTaskReader{tasks: []platform.TodoistTask{{ID: "1", Description: "sam-thread-id: t5"}}}
+
+	var buf bytes.Buffer
+	fu := &capability.FollowUps{Mail: followUpThreads(), Todoist: tasks, Creator: creator, CreateTasks: true, Now: fixedNow}
+	cfg := triageConfig()
+	cfg.FollowUps = config.FollowUpsConfig{AfterBusinessDays: 2, ProjectID: "work"}
+
+	if err := fu.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(creator.created) != 2 {
+		t.Fatalf("created %d tasks, want 2 (t5 is already tracked)", len(creator.created))
+	}
+	task := creator.created[0]
+	if task.Title != "Follow up: Contract draft" || task.ProjectID != "work" || task.DueString != "today" {
+		t.Errorf("task = %+v, want follow-up due today in configured project", task)
+	}
+	if !strings.Contains(task.Description, "Sent to: Ada Lovelace") || !strings.Contains(task.Description, "sam-thread-id: t1") {
+		t.Errorf("description = %q, want recipient and thread marker", task.Description)
+	}
+	if creator.created[1].Title != "Follow up: Budget question" {
+		t.Errorf("second task = %q, want the thread past the lower threshold", creator.created[1].Title)
+	}
+	if !strings.Contains(buf.String(), "Created 2 follow-up tasks, 1 already tracked") {
+		t.Errorf("output missing task result, got:\n%s", buf.String())
+	}
+}
+
+func TestFollowUps_NothingWaiting(t *testing.T) {
+	var buf bytes.Buffer
+	fu := &capability.FollowUps{Mail: &stubMailReader{}, Now: fixedNow}
+	if err := fu.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Nothing waiting for a reply") {
+		t.Errorf("output = %q, want empty message", buf.String())
+	}
+}
@@ -0,0 +1,373 @@
+package capability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const defaultInvitationsQuery = "newer_than:30d"
+
+// rsvpStatuses maps configured responses to calendar response statuses.
+var rsvpStatuses = map[string]platform.RSVPStatus{
+	config.ResponseAccept:    platform.RSVPAccepted,
+	config.ResponseDecline:   platform.RSVPDeclined,
+	config.ResponseTentative: platform.RSVPTentative,
+}
+
+// Invitations lists calendar invitations received by email that are still
+// waiting for a response, and suggests one from invitations.rules.
+type Invitations struct {
+	Mail     platform.InvitationReader
+	Calendar platform.CalendarRangeReader
+	// Responder answers invitations. Required only with RSVP.
+	Responder platform.CalendarResponder
+	// RSVP sends the suggested response for every invitation a rule matches.
+	RSVP bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// InvitationResult is an invitation waiting for a response, for
+// machine-readable output. Responded is set once --rsvp sent the suggestion.
+type InvitationResult struct {
+	EventID   string    `json:"event_id" yaml:"event_id"`
+	Title     string    `json:"title" yaml:"title"`
+	Start     time.Time `json:"start" yaml:"start"`
+	End       time.Time `json:"end" yaml:"end"`
+	Organizer string    `json:"organizer,omitempty" yaml:"organizer,omitempty"`
+	Area      string    `json:"area,omitempty" yaml:"area,omitempty"`
+	Conflicts []string  `json:"conflicts,omitempty" yaml:"conflicts,omitempty"`
+	Suggested string    `json:"suggested,omitempty" yaml:"suggested,omitempty"`
+	Responded bool      `json:"responded" yaml:"responded"`
+}
+
+// pendingInvitation is an emailed invitation matched to its calendar event.
+type pendingInvitation struct {
+	platform.EmailInvitation
+	event     platform.CalendarEvent
+	conflicts []platform.CalendarEvent
+	area      string
+	response  string
+}
+
+func (inv *Invitations) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	query := cfg.Invitations.Query
+	if query == "" {
+		query = defaultInvitationsQuery
+	}
+
+	invitations, err := inv.Mail.Invitations(query)
+	if err != nil {
+		return fmt.Errorf("fetching emailed invitations: %w", err)
+	}
+
+	now := inv.now()
+	upcoming := latestInvitations(invitations, now)
+
+	var pending []pendingInvitation
+	if len(upcoming) > 0 {
+		start, end := upcoming[0].Start, upcoming[0].End
+		for _, i := range upcoming[1:] {
+			if i.Start.Before(start) {
+				start = i.Start
+			}
+			if i.End.After(end) {
+				end = i.End
+			}
+		}
+
+		events, err := inv.Calendar.EventsBetween(cfg.Calendar.CalendarID, start, end)
+		if err != nil {
+			return fmt.Errorf("fetching calendar events: %w", err)
+		}
+		pending = matchInvitations(upcoming, events, cfg)
+	}
+
+	if len(pending) == 0 {
+		return out.Present(output.Briefing{
+			Title:    "Invitations",
+			Sections: []output.Section{{Heading: "Pending Invitations", Items: []output.Item{output.Paragraph("No invitations waiting for a response")}}},
+		})
+	}
+
+	var lines []string
+	for _, p := range pending {
+		lines = append(lines, formatInvitationLine(p, cfg))
+	}
+	sections := []output.Section{{Heading: fmt.Sprintf("Pending Invitations (%d)", len(pending)), Items: []output.Item{output.List{Entries: lines}}}}
+	if section, ok := answerToday(pending, now, cfg); ok {
+		sections = append(sections, section)
+	}
+
+	if inv.RSVP {
+		section, err := inv.respond(pending, cfg)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, section)
+	}
+
+	var results []InvitationResult
+	for _, p := range pending {
+		r := InvitationResult{
+			EventID:   p.event.ID,
+			Title:     p.Title,
+			Start:     p.Start,
+			End:       p.End,
+			Organizer: p.Organizer.Email,
+			Area:      p.area,
+			Suggested: p.response,
+			Responded: inv.RSVP && p.response != "",
+		}
+		for _, e := range p.conflicts {
+			r.Conflicts = append(r.Conflicts, e.Title)
+		}
+		results = append(results, r)
+	}
+
+	return out.Present(output.Briefing{Title: "Invitations", Sections: sections, Results: results})
+}
+
+// respond sends the suggested response to each invitation that has one.
+func (inv *Invitations) respond(pending []pendingInvitation, cfg config.Config) (output.Section, error) {
+	if inv.Responder == nil {
+		return output.Section{}, fmt.Errorf("answering invitations needs a calendar responder")
+	}
+
+	var lines []string
+	for _, p := range pending {
+		if p.response == "" {
+			continue
+		}
+		if err := inv.Responder.RespondToEvent(cfg.Calendar.CalendarID, p.event.ID, rsvpStatuses[p.response]); err != nil {
+			return output.Section{}, fmt.Errorf("answering invitation %q: %w", p.Title, err)
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", responseVerb(p.response), p.Title))
+	}
+
+	if len(lines) == 0 {
+		return output.Section{Heading: "RSVPs", Items: []output.Item{output.Paragraph("No invitations matched a rule")}}, nil
+	}
+	return output.Section{Heading: "RSVPs", Items: []output.Item{output.List{Entries: lines}}}, nil
+}
+
+// answerToday returns an urgent section listing the pending invitations
+// still to start today, warning about the ones that conflict.
+func answerToday(pending []pendingInvitation, now time.Time, cfg config.Config) (output.Section, bool) {
+	tomorrow := startOfDay(now).AddDate(0, 0, 1)
+
+	var lines []string
+	conflicts := 0
+	for _, p := range pending {
+		if p.Start.Before(now) || !p.Start.Before(tomorrow) {
+			continue
+		}
+		lines = append(lines, formatInvitationLine(p, cfg))
+		if len(p.conflicts) > 0 {
+			conflicts++
+		}
+	}
+	if len(lines) == 0 {
+		return output.Section{}, false
+	}
+
+	items := []output.Item{output.List{Entries: lines}}
+	if conflicts > 0 {
+		items = append(items, output.Status{Level: output.LevelWarning, Text: fmt.Sprintf("%d of %d conflict with your calendar", conflicts, len(lines))})
+	}
+	return output.Section{Heading: "Answer Today", Items: items, Urgent: true}, true
+}
+
+func (inv *Invitations) now() time.Time {
+	if inv.Now != nil {
+		return inv.Now()
+	}
+	return time.Now()
+}
+
+// latestInvitations keeps the most recent version of each event that has
+// not ended yet, soonest first. Organizers send a new invitation with a
+// higher sequence number each time they change the event.
+func latestInvitations(invitations []platform.EmailInvitation, now time.Time) []platform.EmailInvitation {
+	latest := make(map[string]platform.EmailInvitation)
+	for _, i := range invitations {
+		prev, ok := latest[i.UID]
+		if !ok || i.Sequence > prev.Sequence || i.Sequence == prev.Sequence && i.Received.After(prev.Received) {
+			latest[i.UID] = i
+		}
+	}
+
+	var result []platform.EmailInvitation
+	for _, i := range latest {
+		if i.End.After(now) {
+			result = append(result, i)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if !result[a].Start.Equal(result[b].Start) {
+			return result[a].Start.Before(result[b].Start)
+		}
+		return result[a].UID < result[b].UID
+	})
+	return result
+}
+
+// matchInvitations pairs invitations with the calendar events that still
+// need a response. Invitations already answered, or not on the calendar,
+// are dropped.
+func matchInvitations(invitations []platform.EmailInvitation, events []platform.CalendarEvent, cfg config.Config) []pendingInvitation {
+	var pending []pendingInvitation
+	for _, i := range invitations {
+		event, ok := invitationEvent(i, events)
+		if !ok || event.RSVP != platform.RSVPNeedsAction {
+			continue
+		}
+
+		p := pendingInvitation{
+			EmailInvitation: i,
+			event:           event,
+			conflicts:       conflictingEvents(i, events),
+			area:            eventArea(i.Title, cfg.Areas),
+		}
+		p.response = suggestResponse(p, cfg.Invitations.Rules)
+		pending = append(pending, p)
+	}
+	return pending
+}
+
+// invitationEvent finds the calendar event an invitation is for. Recurring
+// events have an instance per occurrence sharing the UID; the one starting
+// with the invitation is preferred.
+func invitationEvent(i platform.EmailInvitation, events []platform.CalendarEvent) (platform.CalendarEvent, bool) {
+	var found *platform.CalendarEvent
+	for n := range events {
+		e := &events[n]
+		if e.ICalUID != i.UID {
+			continue
+		}
+		if e.StartTime.Equal(i.Start) {
+			return *e, true
+		}
+		if found == nil {
+			found = e
+		}
+	}
+	if found == nil {
+		return platform.CalendarEvent{}, false
+	}
+	return *found, true
+}
+
+// conflictingEvents returns the timed events overlapping the invitation
+// that you have not declined.
+func conflictingEvents(i platform.EmailInvitation, events []platform.CalendarEvent) []platform.CalendarEvent {
+	var conflicts []platform.CalendarEvent
+	for _, e := range events {
+		if e.ICalUID == i.UID || e.AllDay || e.RSVP == platform.RSVPDeclined {
+			continue
+		}
+		end := e.EndTime
+		if end.IsZero() {
+			end = e.StartTime
+		}
+		if e.StartTime.Before(i.End) && end.After(i.Start) {
+			conflicts = append(conflicts, e)
+		}
+	}
+	return conflicts
+}
+
+// eventArea returns the first area with a keyword in title.
+func eventArea(title string, areas []config.Area) string {
+	for _, area := range areas {
+		if containsAnyKeyword(title, area.Keywords) {
+			return area.Name
+		}
+	}
+	return ""
+}
+
+// suggestResponse returns the response of the first rule matching p.
+func suggestResponse(p pendingInvitation, rules []config.RSVPRule) string {
+	for _, rule := range rules {
+		if len(rule.OrganizerDomains) > 0 && !matchesDomain(p.Organizer.Email, rule.OrganizerDomains) {
+			continue
+		}
+		if len(rule.Keywords) > 0 && !containsAnyKeyword(p.Title, rule.Keywords) {
+			continue
+		}
+		if len(rule.Areas) > 0 && !hasAnyLabel([]string{p.area}, rule.Areas) {
+			continue
+		}
+		if rule.Conflict != nil && *rule.Conflict != (len(p.conflicts) > 0) {
+			continue
+		}
+		return rule.Response
+	}
+	return ""
+}
+
+// formatInvitationLine renders an invitation as
+// "[Design review](link) — Thu 12 Feb 14:00–15:00, from Ada Lovelace (Work);
+// conflicts with Standup; suggest accept".
+func formatInvitationLine(p pendingInvitation, cfg config.Config) string {
+	line := fmt.Sprintf("%s — %s", threadLink(platform.EmailThread{ID: p.ThreadID, Subject: p.Title}, cfg), formatInvitationTime(p.Invitation))
+
+	if p.Organizer.Email != "" {
+		line += ", from " + formatRecipients([]platform.EmailAddress{p.Organizer})
+	}
+	if p.area != "" {
+		line += " (" + p.area + ")"
+	}
+
+	if len(p.conflicts) > 0 {
+		var titles []string
+		for _, e := range p.conflicts {
+			titles = append(titles, e.Title)
+		}
+		line += "; conflicts with " + strings.Join(titles, ", ")
+	}
+	if p.response != "" {
+		line += "; suggest " + p.response
+	}
+	return line
+}
+
+// formatInvitationTime renders "Thu 12 Feb 14:00–15:00", or
+// "Thu 5 Mar – Fri 6 Mar" for all-day events.
+func formatInvitationTime(i platform.Invitation) string {
+	if i.AllDay {
+		return formatAllDay(i.Start, i.End)
+	}
+	start, end := i.Start.Local(), i.End.Local()
+	if startOfDay(start).Equal(startOfDay(end)) {
+		return start.Format("Mon 2 Jan 15:04") + "–" + end.Format("15:04")
+	}
+	return start.Format("Mon 2 Jan 15:04") + " – " + end.Format("Mon 2 Jan 15:04")
+}
+
+func responseVerb(response string) string {
+	switch response {
+	case config.ResponseAccept:
+		return "Accepted"
+	case config.ResponseDecline:
+		return "Declined"
+	}
+	return "Tentatively accepted"
+}
+
+// formatAllDay renders all-day events ending before end as
+// "Thu 5 Mar (all day)" or "Thu 5 Mar – Fri 6 Mar".
+func formatAllDay(start, end time.Time) string {
+	last := end.AddDate(0, 0, -1)
+	if !last.After(start) {
+		return start.Format("Mon 2 Jan") + " (all day)"
+	}
+	return start.Format("Mon 2 Jan") + " – " + last.Format("Mon 2 Jan")
+}
@@ -0,0 +1,227 @@
+package capability_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubInvitationReader struct {
+	invitations []platform.EmailInvitation
+	query       string
+	err         error
+}
+
+func (s *stubInvitationReader) Invitations(query string) ([]platform.EmailInvitation, error) {
+	s.query = query
+	return s.invitations, s.err
+}
+
+type stubResponder struct {
+	responses []string // "eventID:status"
+}
+
+func (s *stubResponder) RespondToEvent(calendarID, eventID string, status platform.RSVPStatus) error {
+	s.responses = append(s.responses, eventID+":"+string(status))
+	return nil
+}
+
+func invitation(uid, title string, seq, d, startHour, endHour int, organizer string) platform.EmailInvitation {
+	return platform.EmailInvitation{
+		Invitation: platform.Invitation{
+			UID:       uid,
+			Sequence:  seq,
+			Title:     title,
+			Start:     at(d, startHour, 0),
+			End:       at(d, endHour, 0),
+			Organizer: platform.EmailAddress{Email: organizer},
+		},
+		ThreadID: "thread-" + uid,
+		Received: at(9, 8, seq),
+	}
+}
+
+func invitedEvent(id, uid, title string, d, startHour, endHour int, rsvp platform.RSVPStatus) platform.CalendarEvent {
+	return platform.CalendarEvent{
+		ID:        id,
+		ICalUID:   uid,
+		Title:     title,
+		StartTime: at(d, startHour, 0),
+		EndTime:   at(d, endHour, 0),
+		RSVP:      rsvp,
+	}
+}
+
+func invitationsFixture() (*stubInvitationReader, *stubRangeReader) {
+	mail := &stubInvitationReader{invitations: []platform.EmailInvitation{
+		invitation("review@example.com", "Design review", 0, 12, 14, 15, "ada@example.com"),
+		// Rescheduled: the later version wins.
+		invitation("review@example.com", "Design review", 1, 12, 16, 17, "ada@example.com"),
+		invitation("allhands@example.com", "All hands", 0, 11, 10, 11, "ceo@corp.example"),
+		invitation("answered@example.com", "Lunch", 0, 11, 12, 13, "grace@example.com"),
+		invitation("past@example.com", "Kickoff", 0, 9, 10, 11, "grace@example.com"),
+	}}
+	calendar := &stubRangeReader{events: []platform.CalendarEvent{
+		invitedEvent("e1", "review@example.com", "Design review", 12, 16, 17, platform.RSVPNeedsAction),
+		invitedEvent("e2", "allhands@example.com", "All hands", 11, 10, 11, platform.RSVPNeedsAction),
+		invitedEvent("e3", "answered@example.com", "Lunch", 11, 12, 13, platform.RSVPAccepted),
+		invitedEvent("e4", "standup@example.com", "Standup", 12, 16, 17, platform.RSVPAccepted),
+		invitedEvent("e5", "skipped@example.com", "Skipped sync", 11, 10, 11, platform.RSVPDeclined),
+	}}
+	return mail, calendar
+}
+
+func invitationsConfig() config.Config {
+	cfg := triageConfig()
+	cfg.Calendar.CalendarID = "me@example.com"
+	cfg.Areas = []config.Area{{Name: "Product", Keywords: []string{"design"}}}
+	return cfg
+}
+
+func TestInvitations_ListsPendingWithConflictsAndArea(t *testing.T) {
+	mail, calendar := invitationsFixture()
+
+	var buf bytes.Buffer
+	inv := &capability.Invitations{Mail: mail, Calendar: calendar, Now: fixedNow}
+	if err := inv.Run(invitationsConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "newer_than:30d" {
+		t.Errorf("query = %q, want invitations from the last 30 days", mail.query)
+	}
+
+	want := "## Pending Invitations (2)\n\n" +
+		"- [All hands](https://mail.google.com/mail/?authuser=me%40example.com#all/thread-allhands@example.com) — Wed 11 Feb 10:00–11:00, from ceo@corp.example\n" +
+		"- [Design review](https://mail.google.com/mail/?authuser=me%40example.com#all/thread-review@example.com) — Thu 12 Feb 16:00–17:00, from ada@example.com (Product); conflicts with Standup\n"
+	got := buf.String()
+	if !strings.Contains(got, want) {
+		t.Errorf("output missing %q, got:\n%s", want, got)
+	}
+	for _, skipped := range []string{"Lunch", "Kickoff", "Skipped sync", "RSVPs"} {
+		if strings.Contains(got, skipped) {
+			t.Errorf("output should not mention %q, got:\n%s", skipped, got)
+		}
+	}
+}
+
+func TestInvitations_RSVPsByRule(t *testing.T) {
+	mail, calendar := invitationsFixture()
+	responder := &stubResponder{}
+	conflict := true
+
+	var buf bytes.Buffer
+	inv := &capability.Invitations{Mail: mail, Calendar: calendar, Responder: responder, RSVP: true, Now: fixedNow}
+	cfg := invitationsConfig()
+	cfg.Invitations.Rules = []config.RSVPRule{
+		{Response: "tentative", Areas: []string{"product"}, Conflict: &conflict},
+		{Response: "accept", OrganizerDomains: []string{"corp.example"}, Keywords: []string{"all hands"}},
+	}
+
+	if err := inv.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(responder.responses, ",") != "e2:accepted,e1:tentative" {
+		t.Errorf("responses = %v, want all hands accepted and design review tentative", responder.responses)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"from ceo@corp.example; suggest accept",
+		"conflicts with Standup; suggest tentative",
+		"## RSVPs\n\n- Accepted: All hands\n- Tentatively accepted: Design review",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestInvitations_RSVPNeedsResponder(t *testing.T) {
+	mail, calendar := invitationsFixture()
+	inv := &capability.Invitations{Mail: mail, Calendar: calendar, RSVP: true, Now: fixedNow}
+
+	err := inv.Run(invitationsConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}})
+	if err == nil {
+		t.Fatal("expected error without a responder")
+	}
+}
+
+func TestInvitations_AllDay(t *testing.T) {
+	offsite := platform.EmailInvitation{Invitation: platform.Invitation{
+		UID:    "offsite@example.com",
+		Title:  "Offsite",
+		AllDay: true,
+		Start:  *day(16),
+		End:    *day(18),
+	}, ThreadID: "t-offsite"}
+	calendar := &stubRangeReader{events: []platform.CalendarEvent{
+		{ID: "e1", ICalUID: "offsite@example.com", Title: "Offsite", AllDay: true, RSVP: platform.RSVPNeedsAction},
+		invitedEvent("e2", "1on1@example.com", "1:1", 17, 9, 10, platform.RSVPAccepted),
+	}}
+
+	var buf bytes.Buffer
+	inv := &capability.Invitations{Mail: &stubInvitationReader{invitations: []platform.EmailInvitation{offsite}}, Calendar: calendar, Now: fixedNow}
+	if err := inv.Run(invitationsConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "#all/t-offsite) — Mon 16 Feb – Tue 17 Feb; conflicts with 1:1") {
+		t.Errorf("output missing all-day invitation, got:\n%s", buf.String())
+	}
+}
+
+func TestInvitations_AnswerTodayIsUrgent(t *testing.T) {
+	mail, calendar := invitationsFixture()
+	mail.invitations = append(mail.invitations,
+		invitation("retro@example.com", "Retro", 0, 10, 15, 16, "grace@example.com"),
+		// Already started, so too late to answer.
+		invitation("breakfast@example.com", "Breakfast", 0, 10, 7, 9, "grace@example.com"),
+	)
+	calendar.events = append(calendar.events,
+		invitedEvent("e6", "retro@example.com", "Retro", 10, 15, 16, platform.RSVPNeedsAction),
+		invitedEvent("e8", "breakfast@example.com", "Breakfast", 10, 7, 9, platform.RSVPNeedsAction),
+		invitedEvent("e7", "focus@example.com", "Focus time", 10, 14, 16, platform.RSVPAccepted),
+	)
+
+	out := &recordingPresenter{}
+	inv := &capability.Invitations{Mail: mail, Calendar: calendar, Now: fixedNow}
+	if err := inv.Run(invitationsConfig(), config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sections := out.briefing.Sections
+	if len(sections) != 2 || sections[0].Urgent {
+		t.Fatalf("sections = %+v, want the pending list followed by an urgent section", sections)
+	}
+	today := sections[1]
+	if today.Heading != "Answer Today" || !today.Urgent {
+		t.Errorf("section = %+v, want an urgent Answer Today section", today)
+	}
+	if len(today.Items) != 2 {
+		t.Fatalf("items = %+v, want the invitation and a conflict warning", today.Items)
+	}
+	if list, ok := today.Items[0].(output.List); !ok || len(list.Entries) != 1 || !strings.Contains(list.Entries[0], "Retro") {
+		t.Errorf("items[0] = %+v, want only today's invitation", today.Items[0])
+	}
+	if status, ok := today.Items[1].(output.Status); !ok || status.Level != output.LevelWarning || status.Text != "1 of 1 conflict with your calendar" {
+		t.Errorf("items[1] = %+v, want a conflict warning", today.Items[1])
+	}
+}
+
+func TestInvitations_NothingPending(t *testing.T) {
+	var buf bytes.Buffer
+	inv := &capability.Invitations{Mail: &stubInvitationReader{}, Calendar: &stubRangeReader{err: errors.New("not called")}, Now: fixedNow}
+	if err := inv.Run(invitationsConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No invitations waiting for a response") {
+		t.Errorf("output = %q, want empty message", buf.String())
+	}
+}
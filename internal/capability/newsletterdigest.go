@@ -0,0 +1,282 @@
+package capability
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const (
+	defaultNewsletterQuery = "newer_than:7d"
+	defaultMaxHeadlines    = 5
+	// minHeadlineWords filters out link text such as "Read more".
+	minHeadlineWords = 3
+)
+
+// NewsletterDigestResult records the digested newsletters and those archived,
+// for machine-readable output.
+type NewsletterDigestResult struct {
+	Digested []DigestedNewsletter `json:"digested,omitempty" yaml:"digested,omitempty"`
+	Archived []string             `json:"archived,omitempty" yaml:"archived,omitempty"`
+}
+
+// DigestedNewsletter is a newsletter thread and how many headlines it gave.
+type DigestedNewsletter struct {
+	ThreadID  string `json:"thread_id" yaml:"thread_id"`
+	Subject   string `json:"subject" yaml:"subject"`
+	Sender    string `json:"sender" yaml:"sender"`
+	Headlines int    `json:"headlines" yaml:"headlines"`
+}
+
+// NewsletterDigest collects newsletters from the inbox into one briefing
+// of their headlines, with statistics on how often each sender writes.
+type NewsletterDigest struct {
+	Mail   platform.MailReader
+	Bodies platform.MessageBodyReader
+	// Modifier archives the newsletters. Required only with Archive.
+	Modifier platform.GmailModifier
+	// Archive removes the newsletters from the inbox once digested.
+	Archive bool
+}
+
+func (nd *NewsletterDigest) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	if nd.Archive && nd.Modifier == nil {
+		return fmt.Errorf("archiving newsletters needs a Gmail modifier")
+	}
+
+	query := cfg.Newsletters.Query
+	if query == "" {
+		query = defaultNewsletterQuery
+	}
+	threads, err := nd.Mail.InboxThreads(query)
+	if err != nil {
+		return fmt.Errorf("fetching inbox threads: %w", err)
+	}
+
+	me := newMailbox(cfg)
+	var newsletters []platform.EmailThread
+	for _, thread := range threads {
+		if isNewsletter(thread, cfg.Newsletters.Senders, me) {
+			newsletters = append(newsletters, thread)
+		}
+	}
+
+	if len(newsletters) == 0 {
+		return out.Present(output.Briefing{
+			Title:    "Newsletter Digest",
+			Sections: []output.Section{{Heading: "Newsletters", Items: []output.Item{output.Paragraph("No newsletters")}}},
+			Results:  NewsletterDigestResult{},
+		})
+	}
+
+	sort.SliceStable(newsletters, func(i, j int) bool { return newsletters[i].Date.After(newsletters[j].Date) })
+
+	maxHeadlines := cfg.Newsletters.MaxHeadlines
+	if maxHeadlines == 0 {
+		maxHeadlines = defaultMaxHeadlines
+	}
+
+	var results NewsletterDigestResult
+	var sections []output.Section
+	for _, thread := range newsletters {
+		body, err := nd.Bodies.MessageBody(latestMessage(thread).ID)
+		if err != nil {
+			return fmt.Errorf("fetching newsletter %q: %w", threadSubject(thread), err)
+		}
+
+		var lines []string
+		headlines := extractHeadlines(body.HTML, thread.ListUnsubscribe, maxHeadlines)
+		for _, h := range headlines {
+			lines = append(lines, fmt.Sprintf("[%s](%s)", h.text, h.url))
+		}
+		results.Digested = append(results.Digested, DigestedNewsletter{
+			ThreadID:  thread.ID,
+			Subject:   threadSubject(thread),
+			Sender:    thread.From.Email,
+			Headlines: len(headlines),
+		})
+		if len(lines) == 0 && thread.Snippet != "" {
+			lines = append(lines, thread.Snippet)
+		}
+
+		items := []output.Item{output.Paragraph(fmt.Sprintf("From %s, %s", senderName(thread.From), thread.Date.Local().Format("Mon 2 Jan")))}
+		if len(lines) > 0 {
+			items = append(items, output.List{Entries: lines})
+		}
+		if link := threadURL(cfg, thread.ID); link != "" {
+			items = append(items, output.Link{Text: "Open in Gmail", URL: link})
+		}
+		sections = append(sections, output.Section{Heading: threadSubject(thread), Items: items})
+	}
+
+	sections = append(sections, output.Section{Heading: "Senders", Items: []output.Item{output.List{Entries: formatSenderStats(newsletterSenders(newsletters))}}})
+
+	if nd.Archive {
+		for _, thread := range newsletters {
+			if err := nd.Modifier.ModifyLabels(thread.ID, nil, []string{"INBOX"}); err != nil {
+				return fmt.Errorf("archiving newsletter %q: %w", threadSubject(thread), err)
+			}
+			results.Archived = append(results.Archived, thread.ID)
+		}
+		sections = append(sections, output.Section{Heading: "Archived", Items: []output.Item{output.Status{Level: output.LevelOK, Text: fmt.Sprintf("Archived %d newsletters", len(newsletters))}}})
+	}
+
+	return out.Present(output.Briefing{Title: "Newsletter Digest", Sections: sections, Results: results})
+}
+
+// isNewsletter reports whether a thread is mailing-list mail, by its
+// List-Unsubscribe or List-Id headers or a configured sender. Threads you
+// wrote in are conversations, even on a mailing list.
+func isNewsletter(thread platform.EmailThread, senders []string, me mailbox) bool {
+	for _, msg := range thread.Messages {
+		if me[strings.ToLower(msg.From.Email)] {
+			return false
+		}
+	}
+	if len(thread.ListUnsubscribe) > 0 || thread.ListID != "" {
+		return true
+	}
+	for _, sender := range senders {
+		if strings.Contains(sender, "@") && strings.EqualFold(sender, thread.From.Email) {
+			return true
+		}
+	}
+	return matchesDomain(thread.From.Email, senders)
+}
+
+type headline struct {
+	text, url string
+}
+
+var (
+	anchorPattern = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*["']([^"']+)["'][^>]*>(.*?)</a>`)
+	hiddenPattern = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	markupPattern = regexp.MustCompile(`<[^>]*>`)
+	// boilerplateLinks is link text found in newsletter headers and footers.
+	boilerplateLinks = []string{
+		"unsubscribe", "view in browser", "view online", "view this email",
+		"email preferences", "privacy policy", "forward to a friend", "manage your subscription",
+	}
+)
+
+// extractHeadlines returns up to max article links from a newsletter's HTML,
+// skipping short link text, boilerplate and unsubscribe links.
+func extractHeadlines(body string, unsubscribe []string, max int) []headline {
+	body = hiddenPattern.ReplaceAllString(body, "")
+
+	var headlines []headline
+	seen := make(map[string]bool)
+	for _, match := range anchorPattern.FindAllStringSubmatch(body, -1) {
+		link := html.UnescapeString(strings.TrimSpace(match[1]))
+		text := strings.Join(strings.Fields(html.UnescapeString(markupPattern.ReplaceAllString(match[2], " "))), " ")
+
+		switch {
+		case !strings.HasPrefix(link, "https://") && !strings.HasPrefix(link, "http://"),
+			len(strings.Fields(text)) < minHeadlineWords,
+			containsAnyKeyword(text, boilerplateLinks),
+			slices.Contains(unsubscribe, link) || strings.Contains(strings.ToLower(link), "unsubscribe"),
+			seen[link] || seen[text]:
+			continue
+		}
+		seen[link], seen[text] = true, true
+
+		headlines = append(headlines, headline{text: strings.NewReplacer("[", "(", "]", ")").Replace(text), url: link})
+		if len(headlines) == max {
+			break
+		}
+	}
+	return headlines
+}
+
+// senderStats counts a newsletter's issues in the digest.
+type senderStats struct {
+	name        string
+	issues      int
+	unread      int
+	unsubscribe string
+}
+
+// newsletterSenders groups newsletters by mailing list, or by sender when
+// they have no List-Id, most frequent first.
+func newsletterSenders(newsletters []platform.EmailThread) []senderStats {
+	byKey := make(map[string]*senderStats)
+	var senders []*senderStats
+	for _, thread := range newsletters {
+		key := thread.ListID
+		if key == "" {
+			key = strings.ToLower(thread.From.Email)
+		}
+		stats, ok := byKey[key]
+		if !ok {
+			stats = &senderStats{name: senderName(thread.From)}
+			byKey[key] = stats
+			senders = append(senders, stats)
+		}
+		stats.issues++
+		if thread.Unread {
+			stats.unread++
+		}
+		if stats.unsubscribe == "" {
+			stats.unsubscribe = unsubscribeLink(thread.ListUnsubscribe)
+		}
+	}
+
+	result := make([]senderStats, len(senders))
+	for i, s := range senders {
+		result[i] = *s
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].issues != result[j].issues {
+			return result[i].issues > result[j].issues
+		}
+		return result[i].unread > result[j].unread
+	})
+	return result
+}
+
+// formatSenderStats renders "Go Weekly — 3 issues, 3 unread · [unsubscribe](url)".
+func formatSenderStats(senders []senderStats) []string {
+	var lines []string
+	for _, s := range senders {
+		line := fmt.Sprintf("%s — %d %s, %d unread", s.name, s.issues, plural(s.issues, "issue", "issues"), s.unread)
+		if s.unsubscribe != "" {
+			line += fmt.Sprintf(" · [unsubscribe](%s)", s.unsubscribe)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// unsubscribeLink prefers a web unsubscribe link over a mailto: one.
+func unsubscribeLink(targets []string) string {
+	for _, target := range targets {
+		if strings.HasPrefix(target, "https://") || strings.HasPrefix(target, "http://") {
+			return target
+		}
+	}
+	if len(targets) > 0 {
+		return targets[0]
+	}
+	return ""
+}
+
+func senderName(a platform.EmailAddress) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return a.Email
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,160 @@
+package capability_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubBodyReader struct {
+	bodies map[string]platform.MessageBody
+}
+
+func (s *stubBodyReader) MessageBody(messageID string) (platform.MessageBody, error) {
+	return s.bodies[messageID], nil
+}
+
+const goWeeklyHTML = `<html><head><style>a { color: red }</style></head><body>
+<p><a href="https://golangweekly.com/view/600">View in browser</a></p>
+<h2><a href="https://go.dev/blog/generics-tips">Five <b>generics</b> tips you&#39;ll actually use</a></h2>
+<p><a href="https://go.dev/blog/generics-tips">Read more</a></p>
+<h2><a href="https://example.com/[draft]/errors">Error handling [revisited] in 2026</a></h2>
+<p><a href="mailto:editor@golangweekly.com">Write to the editor of this newsletter</a></p>
+<p><a href="https://golangweekly.com/u/abc">Click here to stop receiving these</a></p>
+</body></html>`
+
+func newsletterThreads() *stubMailReader {
+	goWeekly := emailThread("t1", "Go Weekly #600", "editor@golangweekly.com", 10, nil, nil)
+	goWeekly.From.Name = "Go Weekly"
+	goWeekly.Unread = true
+	goWeekly.ListID = "weekly.golangweekly.com"
+	goWeekly.ListUnsubscribe = []string{"mailto:unsub@golangweekly.com", "https://golangweekly.com/u/abc"}
+
+	older := emailThread("t2", "Go Weekly #599", "editor@golangweekly.com", 3, nil, nil)
+	older.From.Name = "Go Weekly"
+	older.ListID = "weekly.golangweekly.com"
+
+	digest := emailThread("t3", "Friday links", "links@blog.example", 9, nil, nil)
+	digest.Snippet = "Three things worth reading"
+
+	discussion := emailThread("t4", "Re: RFC: new API", "dev@lists.example", 9, nil, nil)
+	discussion.ListID = "dev.lists.example"
+	discussion.Messages = append(discussion.Messages, platform.EmailMessage{ID: "t4b", From: addr("me@example.com"), Date: at(9, 10, 0)})
+
+	return &stubMailReader{threads: []platform.EmailThread{
+		older,
+		emailThread("t5", "Q1 budget", "ada@example.com", 9, nil, nil),
+		digest,
+		goWeekly,
+		discussion,
+	}}
+}
+
+func TestNewsletterDigest_Headlines(t *testing.T) {
+	mail := newsletterThreads()
+	bodies := &stubBodyReader{bodies: map[string]platform.MessageBody{"t1": {HTML: goWeeklyHTML}}}
+
+	var buf bytes.Buffer
+	nd := &capability.NewsletterDigest{Mail: mail, Bodies: bodies}
+	cfg := triageConfig()
+	cfg.Newsletters.Senders = []string{"blog.example"}
+
+	if err := nd.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if mail.query != "newer_than:7d" {
+		t.Errorf("query = %q, want the last week by default", mail.query)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"## Go Weekly #600\n\nFrom Go Weekly, Tue 10 Feb\n\n" +
+			"- [Five generics tips you'll actually use](https://go.dev/blog/generics-tips)\n" +
+			"- [Error handling (revisited) in 2026](https://example.com/[draft]/errors)\n\n",
+		"## Friday links\n\nFrom links@blog.example, Mon 9 Feb\n\n- Three things worth reading\n\n",
+		"## Go Weekly #599\n\nFrom Go Weekly, Tue 3 Feb\n\n",
+		"[Open in Gmail](https://mail.google.com/mail/?authuser=me%40example.com#all/t1)",
+		"## Senders\n\n- Go Weekly — 2 issues, 1 unread · [unsubscribe](https://golangweekly.com/u/abc)\n- links@blog.example — 1 issue, 0 unread\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+	for _, skipped := range []string{"Q1 budget", "RFC", "View in browser", "Read more", "stop receiving", "editor of this"} {
+		if strings.Contains(got, skipped) {
+			t.Errorf("output should not contain %q, got:\n%s", skipped, got)
+		}
+	}
+	if strings.Index(got, "Go Weekly #600") > strings.Index(got, "Friday links") {
+		t.Errorf("newsletters should be listed newest first, got:\n%s", got)
+	}
+}
+
+func TestNewsletterDigest_ArchiveMaxHeadlines(t *testing.T) {
+	modifier := &stubGmailModifier{}
+	bodies := &stubBodyReader{bodies: map[string]platform.MessageBody{"t1": {HTML: goWeeklyHTML}}}
+
+	var buf bytes.Buffer
+	nd := &capability.NewsletterDigest{Mail: newsletterThreads(), Bodies: bodies, Modifier: modifier, Archive: true}
+	cfg := triageConfig()
+	cfg.Newsletters.MaxHeadlines = 1
+
+	if err := nd.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(modifier.modified, ",") != "t1 -INBOX,t2 -INBOX" {
+		t.Errorf("modified = %v, want both Go Weekly issues archived", modifier.modified)
+	}
+	got := buf.String()
+	if strings.Contains(got, "Error handling") {
+		t.Errorf("output should list one headline per newsletter, got:\n%s", got)
+	}
+	if !strings.Contains(got, "## Archived\n\n✅ Archived 2 newsletters") {
+		t.Errorf("output missing archive result, got:\n%s", got)
+	}
+}
+
+func TestNewsletterDigest_Results(t *testing.T) {
+	bodies := &stubBodyReader{bodies: map[string]platform.MessageBody{"t1": {HTML: goWeeklyHTML}}}
+	out := &recordingPresenter{}
+	nd := &capability.NewsletterDigest{Mail: newsletterThreads(), Bodies: bodies, Modifier: &stubGmailModifier{}, Archive: true}
+	if err := nd.Run(triageConfig(), config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, ok := out.briefing.Results.(capability.NewsletterDigestResult)
+	if !ok {
+		t.Fatalf("results = %T, want NewsletterDigestResult", out.briefing.Results)
+	}
+	if len(results.Digested) != 2 || results.Digested[0] != (capability.DigestedNewsletter{ThreadID: "t1", Subject: "Go Weekly #600", Sender: "editor@golangweekly.com", Headlines: 2}) {
+		t.Errorf("digested = %+v, want both Go Weekly issues, newest first", results.Digested)
+	}
+	if strings.Join(results.Archived, ",") != "t1,t2" {
+		t.Errorf("archived = %v, want [t1 t2]", results.Archived)
+	}
+}
+
+func TestNewsletterDigest_ArchiveNeedsModifier(t *testing.T) {
+	nd := &capability.NewsletterDigest{Mail: newsletterThreads(), Bodies: &stubBodyReader{}, Archive: true}
+	if err := nd.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err == nil {
+		t.Fatal("expected error without a modifier")
+	}
+}
+
+func TestNewsletterDigest_NoNewsletters(t *testing.T) {
+	var buf bytes.Buffer
+	nd := &capability.NewsletterDigest{Mail: &stubMailReader{}, Bodies: &stubBodyReader{}}
+	if err := nd.Run(triageConfig(), config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No newsletters") {
+		t.Errorf("output = %q, want empty message", buf.String())
+	}
+}
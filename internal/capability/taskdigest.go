@@ -0,0 +1,310 @@
+package capability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const (
+	// taskDigestFilter selects overdue, due-today and P1/P2 tasks.
+	// Todoist filters use UI priorities, where p1 is API priority 4.
+	taskDigestFilter = "overdue | today | p1 | p2"
+
+	defaultOverdueDays      = 3
+	defaultRescheduledTimes = 2
+	// rescheduleLookback is how far back reschedules are counted.
+	rescheduleLookback = 30 * 24 * time.Hour
+)
+
+// TaskDigestResult counts the digest's tasks and lists those that need
+// attention, for machine-readable output.
+type TaskDigestResult struct {
+	Overdue      int           `json:"overdue" yaml:"overdue"`
+	DueToday     int           `json:"due_today" yaml:"due_today"`
+	HighPriority int           `json:"high_priority" yaml:"high_priority"`
+	Attention    []FlaggedTask `json:"attention,omitempty" yaml:"attention,omitempty"`
+}
+
+// FlaggedTask is a task that needs attention, and why.
+type FlaggedTask struct {
+	TaskID string `json:"task_id" yaml:"task_id"`
+	Title  string `json:"title" yaml:"title"`
+	Reason string `json:"reason" yaml:"reason"`
+}
+
+// TaskDigest summarises overdue, due-today and high-priority Todoist tasks.
+type TaskDigest struct {
+	Todoist platform.TaskReader
+	// Activity counts how often tasks were rescheduled. Optional; without
+	// it, rescheduled tasks are not highlighted.
+	Activity platform.TaskActivityReader
+	// Issues are optional work trackers whose current-sprint issues assigned
+	// to the user are listed after the Todoist tasks.
+	Issues []platform.IssueReader
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (td *TaskDigest) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	tasks, err := td.Todoist.Tasks(taskDigestFilter)
+	if err != nil {
+		return fmt.Errorf("fetching todoist tasks: %w", err)
+	}
+
+	projects, err := td.Todoist.Projects()
+	if err != nil {
+		return fmt.Errorf("fetching todoist projects: %w", err)
+	}
+
+	issues, err := assignedIssues(td.Issues)
+	if err != nil {
+		return err
+	}
+
+	tasks = filterByProject(tasks, cfg.TaskDigest.ProjectIDs)
+
+	if len(tasks) == 0 {
+		sections := []output.Section{{Heading: "Summary", Items: []output.Item{output.Status{Level: output.LevelOK, Text: "Nothing overdue, due today or high priority"}}}}
+		if len(issues) > 0 {
+			sections = append(sections, output.Section{Heading: "Sprint Issues", Items: []output.Item{output.List{Entries: formatIssueLines(issues)}}})
+		}
+		return out.Present(output.Briefing{Title: "Task Digest", Sections: sections, Results: TaskDigestResult{}})
+	}
+
+	today := startOfDay(td.now())
+	overdueDays := cfg.TaskDigest.OverdueDays
+	if overdueDays == 0 {
+		overdueDays = defaultOverdueDays
+	}
+	rescheduledTimes := cfg.TaskDigest.RescheduledTimes
+	if rescheduledTimes == 0 {
+		rescheduledTimes = defaultRescheduledTimes
+	}
+
+	// The activity log is a Todoist Pro feature, so the digest goes out
+	// without reschedules when it cannot be read.
+	var reschedules map[string]int
+	var activityErr error
+	if td.Activity != nil {
+		reschedules, activityErr = td.Activity.Reschedules(td.now().Add(-rescheduleLookback))
+	}
+
+	var results TaskDigestResult
+	var attention []string
+	for _, task := range tasks {
+		switch {
+		case isOverdue(task, today):
+			results.Overdue++
+		case isDueOn(task, today):
+			results.DueToday++
+		}
+		if task.Priority >= 3 {
+			results.HighPriority++
+		}
+
+		reason := ""
+		if days := daysOverdue(task, today); days > overdueDays {
+			reason = fmt.Sprintf("overdue %d days", days)
+		} else if times := reschedules[task.ID]; !task.IsRecurring && times > rescheduledTimes {
+			reason = fmt.Sprintf("rescheduled %d times", times)
+		}
+		if reason != "" {
+			attention = append(attention, fmt.Sprintf("%s (%s)", task.Title, reason))
+			results.Attention = append(results.Attention, FlaggedTask{TaskID: task.ID, Title: task.Title, Reason: reason})
+		}
+	}
+
+	sections := []output.Section{
+		{
+			Heading: "Summary",
+			Items:   []output.Item{output.Paragraph(fmt.Sprintf("%d overdue, %d due today, %d high priority", results.Overdue, results.DueToday, results.HighPriority))},
+		},
+	}
+
+	if len(attention) > 0 || activityErr != nil {
+		var items []output.Item
+		if len(attention) > 0 {
+			items = append(items, output.List{Entries: attention})
+		}
+		if activityErr != nil {
+			items = append(items, output.Status{Level: output.LevelInfo, Text: "Reschedules not counted: " + activityErr.Error()})
+		}
+		sections = append(sections, output.Section{Heading: "Needs Attention", Items: items})
+	}
+
+	for _, group := range groupByProject(tasks, projects) {
+		sections = append(sections, output.Section{
+			Heading: group.name,
+			Items:   priorityGroups(group.tasks, today),
+		})
+	}
+
+	if len(issues) > 0 {
+		sections = append(sections, output.Section{Heading: "Sprint Issues", Items: []output.Item{output.List{Entries: formatIssueLines(issues)}}})
+	}
+
+	return out.Present(output.Briefing{Title: "Task Digest", Sections: sections, Results: results})
+}
+
+func (td *TaskDigest) now() time.Time {
+	if td.Now != nil {
+		return td.Now()
+	}
+	return time.Now()
+}
+
+type projectTasks struct {
+	name  string
+	tasks []platform.TodoistTask
+}
+
+// groupByProject buckets tasks by project, ordered by project name.
+// Tasks whose project is unknown are grouped under their project ID.
+func groupByProject(tasks []platform.TodoistTask, projects []platform.TodoistProject) []projectTasks {
+	names := make(map[string]string, len(projects))
+	for _, p := range projects {
+		names[p.ID] = p.Name
+	}
+
+	byProject := make(map[string][]platform.TodoistTask)
+	for _, task := range tasks {
+		byProject[task.ProjectID] = append(byProject[task.ProjectID], task)
+	}
+
+	groups := make([]projectTasks, 0, len(byProject))
+	for id, projectTasksList := range byProject {
+		name, ok := names[id]
+		if !ok {
+			name = id
+		}
+		groups = append(groups, projectTasks{name: name, tasks: projectTasksList})
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
+
+// priorityGroups lists tasks grouped by priority, most urgent first.
+func priorityGroups(tasks []platform.TodoistTask, today time.Time) []output.Item {
+	byPriority := make(map[int][]platform.TodoistTask)
+	for _, task := range tasks {
+		byPriority[task.Priority] = append(byPriority[task.Priority], task)
+	}
+
+	var groups []output.Item
+	for priority := 4; priority >= 1; priority-- {
+		group := byPriority[priority]
+		if len(group) == 0 {
+			continue
+		}
+
+		var lines []string
+		for _, task := range group {
+			lines = append(lines, task.Title+dueSuffix(task, today))
+		}
+		groups = append(groups, output.List{Title: priorityLabel(priority), Entries: lines})
+	}
+	return groups
+}
+
+func dueSuffix(task platform.TodoistTask, today time.Time) string {
+	if days := daysOverdue(task, today); days > 0 {
+		return fmt.Sprintf(" (overdue %dd)", days)
+	}
+	if task.DueDateTime != nil {
+		return fmt.Sprintf(" (%s)", task.DueDateTime.Local().Format("15:04"))
+	}
+	return ""
+}
+
+// priorityLabel converts an API priority (4 = urgent) to the UI label (P1 = urgent).
+func priorityLabel(priority int) string {
+	return fmt.Sprintf("P%d", 5-priority)
+}
+
+// assignedIssues collects the current sprint's issues from every work
+// tracker, highest priority first.
+func assignedIssues(readers []platform.IssueReader) ([]platform.WorkIssue, error) {
+	var all []platform.WorkIssue
+	for _, r := range readers {
+		issues, err := r.AssignedIssues()
+		if err != nil {
+			return nil, fmt.Errorf("fetching assigned issues: %w", err)
+		}
+		all = append(all, issues...)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Priority > all[j].Priority })
+	return all, nil
+}
+
+// formatIssueLines renders issues as "OPS-42 Fix deploys (In Progress, P1)".
+func formatIssueLines(issues []platform.WorkIssue) []string {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		var meta []string
+		if issue.Status != "" {
+			meta = append(meta, issue.Status)
+		}
+		if issue.Priority > 1 {
+			meta = append(meta, priorityLabel(issue.Priority))
+		}
+		line := issue.Key + " " + issue.Title
+		if len(meta) > 0 {
+			line += " (" + strings.Join(meta, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func filterByProject(tasks []platform.TodoistTask, projectIDs []string) []platform.TodoistTask {
+	if len(projectIDs) == 0 {
+		return tasks
+	}
+
+	allowed := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		allowed[id] = true
+	}
+
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if allowed[task.ProjectID] {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+func isOverdue(task platform.TodoistTask, today time.Time) bool {
+	return task.DueDate != nil && daysBetween(*task.DueDate, today) > 0
+}
+
+func isDueOn(task platform.TodoistTask, day time.Time) bool {
+	return task.DueDate != nil && daysBetween(*task.DueDate, day) == 0
+}
+
+func daysOverdue(task platform.TodoistTask, today time.Time) int {
+	if !isOverdue(task, today) {
+		return 0
+	}
+	return daysBetween(*task.DueDate, today)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from a to b, comparing each date as it
+// reads in its own location and ignoring the time of day.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
@@ -0,0 +1,264 @@
+package capability_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubTaskReader struct {
+	tasks    []platform.TodoistTask
+	projects []platform.TodoistProject
+	filter   string
+	err      error
+}
+
+func (s *stubTaskReader) Tasks(filter string) ([]platform.TodoistTask, error) {
+	s.filter = filter
+	return s.tasks, s.err
+}
+
+func (s *stubTaskReader) Projects() ([]platform.TodoistProject, error) {
+	return s.projects, nil
+}
+
+type stubIssueReader struct {
+	issues []platform.WorkIssue
+	err    error
+}
+
+func (s *stubIssueReader) AssignedIssues() ([]platform.WorkIssue, error) {
+	return s.issues, s.err
+}
+
+func fixedNow() time.Time {
+	return time.Date(2026, 2, 10, 8, 0, 0, 0, time.Local)
+}
+
+func day(d int) *time.Time {
+	t := time.Date(2026, 2, d, 0, 0, 0, 0, time.Local)
+	return &t
+}
+
+func TestTaskDigest_NoTasks(t *testing.T) {
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: &stubTaskReader{}, Now: fixedNow}
+
+	if err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Nothing overdue") {
+		t.Errorf("output = %q, want empty digest message", buf.String())
+	}
+}
+
+func TestTaskDigest_GroupsByProjectAndPriority(t *testing.T) {
+	reader := &stubTaskReader{
+		tasks: []platform.TodoistTask{
+			{Title: "Low errand", ProjectID: "home", Priority: 1, DueDate: day(10)},
+			{Title: "Ship release", ProjectID: "work", Priority: 4, DueDate: day(10)},
+			{Title: "Expense report", ProjectID: "work", Priority: 2, DueDate: day(9)},
+		},
+		projects: []platform.TodoistProject{
+			{ID: "home", Name: "Home"},
+			{ID: "work", Name: "Work"},
+		},
+	}
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: reader, Now: fixedNow}
+
+	if err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if !strings.Contains(got, "1 overdue, 2 due today, 1 high priority") {
+		t.Errorf("missing summary counts, got:\n%s", got)
+	}
+
+	home := strings.Index(got, "## Home")
+	work := strings.Index(got, "## Work")
+	if home == -1 || work == -1 || home > work {
+		t.Errorf("expected Home and Work sections in name order, got:\n%s", got)
+	}
+
+	p1 := strings.Index(got, "P1\n- Ship release")
+	p3 := strings.Index(got, "P3\n- Expense report (overdue 1d)")
+	if p1 == -1 || p3 == -1 || p1 > p3 {
+		t.Errorf("expected P1 before P3 within Work, got:\n%s", got)
+	}
+	if reader.filter == "" {
+		t.Error("expected a Todoist filter to be used")
+	}
+}
+
+func TestTaskDigest_FiltersConfiguredProjects(t *testing.T) {
+	reader := &stubTaskReader{
+		tasks: []platform.TodoistTask{
+			{Title: "Work task", ProjectID: "work", Priority: 1, DueDate: day(10)},
+			{Title: "Home task", ProjectID: "home", Priority: 1, DueDate: day(10)},
+		},
+	}
+	cfg := config.Config{TaskDigest: config.TaskDigestConfig{ProjectIDs: []string{"work"}}}
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: reader, Now: fixedNow}
+
+	if err := td.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	if strings.Contains(got, "Home task") {
+		t.Errorf("task from unconfigured project included, got:\n%s", got)
+	}
+	if !strings.Contains(got, "Work task") {
+		t.Errorf("task from configured project missing, got:\n%s", got)
+	}
+}
+
+type stubActivityReader struct {
+	reschedules map[string]int
+	err         error
+	since       time.Time
+}
+
+func (s *stubActivityReader) Reschedules(since time.Time) (map[string]int, error) {
+	s.since = since
+	return s.reschedules, s.err
+}
+
+func TestTaskDigest_HighlightsLongOverdueAndRescheduled(t *testing.T) {
+	reader := &stubTaskReader{
+		tasks: []platform.TodoistTask{
+			{ID: "1", Title: "Ancient chore", ProjectID: "home", Priority: 1, DueDate: day(1)},
+			{ID: "2", Title: "Slightly late", ProjectID: "home", Priority: 1, DueDate: day(9)},
+			{ID: "3", Title: "Perpetual plan", ProjectID: "home", Priority: 1, DueDate: day(10)},
+			{
+				ID: "4", Title: "Planned ahead", ProjectID: "home", Priority: 1, DueDate: day(10),
+				CreatedAt: time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local),
+			},
+			{ID: "5", Title: "Moved twice", ProjectID: "home", Priority: 1, DueDate: day(10)},
+			{ID: "6", Title: "Weekly sync", ProjectID: "home", Priority: 1, DueDate: day(10), IsRecurring: true},
+		},
+		projects: []platform.TodoistProject{{ID: "home", Name: "Home"}},
+	}
+	activity := &stubActivityReader{reschedules: map[string]int{"3": 3, "5": 2, "6": 9}}
+	cfg := config.Config{TaskDigest: config.TaskDigestConfig{OverdueDays: 5}}
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: reader, Activity: activity, Now: fixedNow}
+
+	if err := td.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	attention := got[strings.Index(got, "## Needs Attention"):]
+	attention = attention[:strings.Index(attention, "## Home")]
+
+	if !strings.Contains(attention, "Ancient chore (overdue 9 days)") {
+		t.Errorf("long-overdue task not highlighted, got:\n%s", attention)
+	}
+	if !strings.Contains(attention, "Perpetual plan (rescheduled 3 times)") {
+		t.Errorf("rescheduled task not highlighted, got:\n%s", attention)
+	}
+	for _, title := range []string{"Slightly late", "Planned ahead", "Moved twice", "Weekly sync"} {
+		if strings.Contains(attention, title) {
+			t.Errorf("%q highlighted, got:\n%s", title, attention)
+		}
+	}
+	if want := fixedNow().AddDate(0, 0, -30); !activity.since.Equal(want) {
+		t.Errorf("reschedules counted since %v, want %v", activity.since, want)
+	}
+}
+
+func TestTaskDigest_Results(t *testing.T) {
+	reader := &stubTaskReader{tasks: []platform.TodoistTask{
+		{ID: "1", Title: "Ancient chore", ProjectID: "home", Priority: 4, DueDate: day(1)},
+		{ID: "2", Title: "Write proposal", ProjectID: "home", Priority: 1, DueDate: day(10)},
+	}}
+	out := &recordingPresenter{}
+	td := &capability.TaskDigest{Todoist: reader, Now: fixedNow}
+	if err := td.Run(config.Config{}, config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := capability.TaskDigestResult{
+		Overdue: 1, DueToday: 1, HighPriority: 1,
+		Attention: []capability.FlaggedTask{{TaskID: "1", Title: "Ancient chore", Reason: "overdue 9 days"}},
+	}
+	if !reflect.DeepEqual(out.briefing.Results, want) {
+		t.Errorf("results = %+v, want %+v", out.briefing.Results, want)
+	}
+}
+
+func TestTaskDigest_WithoutActivityLog(t *testing.T) {
+	reader := &stubTaskReader{tasks: []platform.TodoistTask{{ID: "1", Title: "Write proposal", ProjectID: "work", Priority: 1, DueDate: day(10)}}}
+	activity := &stubActivityReader{err: errors.New("Todoist API returned 403: premium only")}
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: reader, Activity: activity, Now: fixedNow}
+
+	if err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := buf.String()
+	if !strings.Contains(got, "Reschedules not counted: Todoist API returned 403") || !strings.Contains(got, "Write proposal") {
+		t.Errorf("expected the digest with a note on reschedules, got:\n%s", got)
+	}
+}
+
+func TestTaskDigest_IncludesSprintIssues(t *testing.T) {
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{
+		Todoist: &stubTaskReader{},
+		Issues: []platform.IssueReader{
+			&stubIssueReader{issues: []platform.WorkIssue{{Key: "ENG-2", Title: "Triage bugs", Status: "Todo", Priority: 1}}},
+			&stubIssueReader{issues: []platform.WorkIssue{{Key: "OPS-1", Title: "Fix deploys", Status: "In Progress", Priority: 4}}},
+		},
+		Now: fixedNow,
+	}
+
+	if err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "## Sprint Issues\n\n- OPS-1 Fix deploys (In Progress, P1)\n- ENG-2 Triage bugs (Todo)"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q, got:\n%s", want, buf.String())
+	}
+}
+
+func TestTaskDigest_IssueError(t *testing.T) {
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{
+		Todoist: &stubTaskReader{},
+		Issues:  []platform.IssueReader{&stubIssueReader{err: fmt.Errorf("Jira API returned 401")}},
+		Now:     fixedNow,
+	}
+
+	err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("error = %v, want issue tracker failure", err)
+	}
+}
+
+func TestTaskDigest_TodoistError(t *testing.T) {
+	var buf bytes.Buffer
+	td := &capability.TaskDigest{Todoist: &stubTaskReader{err: fmt.Errorf("unauthorized")}, Now: fixedNow}
+
+	err := td.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "unauthorized") {
+		t.Errorf("error = %q, want mention of unauthorized", err.Error())
+	}
+}
@@ -0,0 +1,373 @@
+package capability
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+const (
+	weeklyReviewTaskTitle = "Weekly review"
+
+	defaultStaleAfterDays = 30
+	defaultWaitingLabel   = "waiting"
+	defaultRecurrence     = "every friday"
+	// maxListedItems caps the detail lists so the briefing stays readable.
+	maxListedItems = 10
+)
+
+// WeeklyReviewResult records the review's checklist and the tasks added for
+// it, for machine-readable output.
+type WeeklyReviewResult struct {
+	Checklist    []string  `json:"checklist" yaml:"checklist"`
+	ReviewTaskID string    `json:"review_task_id,omitempty" yaml:"review_task_id,omitempty"`
+	Created      []TaskRef `json:"created,omitempty" yaml:"created,omitempty"`
+}
+
+// TaskRef is a task by ID and title.
+type TaskRef struct {
+	TaskID string `json:"task_id" yaml:"task_id"`
+	Title  string `json:"title" yaml:"title"`
+}
+
+// WeeklyReview walks through a GTD-style weekly review: inbox, projects
+// without next actions, stale undated tasks, waiting-for items, this week's
+// completed tasks and next week's calendar.
+type WeeklyReview struct {
+	Todoist   platform.TaskReader
+	Completed platform.CompletedTaskReader
+	Calendar  platform.CalendarRangeReader
+	// Creator adds the review task to the tasks.backend backend.
+	Creator platform.TaskCreator
+	// Reviews finds the review task left by earlier runs in Creator's
+	// backend. When nil, as for backends that cannot be read, every run
+	// creates a new review task.
+	Reviews platform.TaskReader
+	// Issues are optional work trackers whose current-sprint issues assigned
+	// to the user are added to the review.
+	Issues []platform.IssueReader
+	// CreateTask adds the generated checklist as sub-tasks of a recurring
+	// "Weekly review" Todoist task.
+	CreateTask bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (wr *WeeklyReview) Run(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+	now := wr.now()
+	weekStart := startOfWeek(now)
+	nextWeekStart := weekStart.AddDate(0, 0, 7)
+
+	tasks, err := wr.Todoist.Tasks("")
+	if err != nil {
+		return fmt.Errorf("fetching todoist tasks: %w", err)
+	}
+
+	projects, err := wr.Todoist.Projects()
+	if err != nil {
+		return fmt.Errorf("fetching todoist projects: %w", err)
+	}
+
+	completed, err := wr.Completed.CompletedTasks(weekStart)
+	if err != nil {
+		return fmt.Errorf("fetching completed tasks: %w", err)
+	}
+
+	events, err := wr.Calendar.EventsBetween(cfg.Calendar.CalendarID, nextWeekStart, nextWeekStart.AddDate(0, 0, 7))
+	if err != nil {
+		return fmt.Errorf("fetching next week's calendar: %w", err)
+	}
+	events = filterDeclined(events)
+
+	issues, err := assignedIssues(wr.Issues)
+	if err != nil {
+		return err
+	}
+
+	review := weeklyReviewSettings(cfg.WeeklyReview)
+	inbox := inboxTasks(tasks, projects)
+	stalled := projectsWithoutNextAction(tasks, projects, review.NextActionLabel, cfg.Todoist.ProjectID)
+	stale := staleUndatedTasks(tasks, now, review.StaleAfterDays)
+	waiting := tasksWithLabel(tasks, review.WaitingLabel)
+
+	checklist := []string{
+		fmt.Sprintf("Process inbox to zero (%d items)", len(inbox)),
+		fmt.Sprintf("Define next actions for %d projects", len(stalled)),
+		fmt.Sprintf("Review %d undated tasks older than %d days", len(stale), review.StaleAfterDays),
+		fmt.Sprintf("Follow up on %d waiting-for items", len(waiting)),
+		fmt.Sprintf("Review %d completed tasks from this week", len(completed)),
+		fmt.Sprintf("Prepare for %d events next week", len(events)),
+	}
+	if len(wr.Issues) > 0 {
+		checklist = append(checklist, fmt.Sprintf("Update %d sprint issues", len(issues)))
+	}
+
+	sections := []output.Section{
+		{Heading: "Checklist", Items: []output.Item{output.List{Entries: checklist, Checklist: true}}},
+		{Heading: "Projects Without Next Actions", Items: listOrNone(capList(stalled))},
+		{Heading: "Stale Undated Tasks", Items: listOrNone(capList(taskTitles(stale)))},
+		{Heading: "Waiting For", Items: listOrNone(capList(taskTitles(waiting)))},
+		{Heading: "Completed This Week", Items: listOrNone(capList(completedTitles(completed)))},
+		{Heading: "Next Week", Items: eventTable(events)},
+	}
+	if len(wr.Issues) > 0 {
+		sections = append(sections, output.Section{Heading: "Sprint Issues", Items: listOrNone(capList(formatIssueLines(issues)))})
+	}
+
+	results := WeeklyReviewResult{Checklist: checklist}
+	if wr.CreateTask {
+		result, err := wr.createReviewTask(checklist, review, &results)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, output.Section{Heading: "Review Task", Items: []output.Item{output.Paragraph(result)}})
+	}
+
+	return out.Present(output.Briefing{Title: "Weekly Review", Sections: sections, Results: results})
+}
+
+// createReviewTask adds the checklist under the existing "Weekly review" task,
+// or creates it as a recurring task first, recording the tasks in results.
+func (wr *WeeklyReview) createReviewTask(checklist []string, review config.WeeklyReviewConfig, results *WeeklyReviewResult) (string, error) {
+	var tasks []platform.TodoistTask
+	if wr.Reviews != nil {
+		var err error
+		if tasks, err = wr.Reviews.Tasks(""); err != nil {
+			return "", fmt.Errorf("fetching review tasks: %w", err)
+		}
+	}
+
+	parentID := ""
+	for _, task := range tasks {
+		if task.Title == weeklyReviewTaskTitle && task.ParentID == "" {
+			parentID = task.ID
+			break
+		}
+	}
+
+	action := "Added checklist to"
+	if parentID == "" {
+		id, err := wr.Creator.CreateTask(platform.TodoistTask{
+			Title:     weeklyReviewTaskTitle,
+			ProjectID: review.ProjectID,
+			DueString: review.Recurrence,
+			Priority:  1,
+		})
+		if err != nil {
+			return "", fmt.Errorf("creating weekly review task: %w", err)
+		}
+		parentID = id
+		action = "Created recurring"
+		results.Created = append(results.Created, TaskRef{TaskID: id, Title: weeklyReviewTaskTitle})
+	}
+	results.ReviewTaskID = parentID
+
+	// Sub-tasks left open from an earlier run are kept rather than added
+	// again, even when their counts have changed since.
+	open := make(map[string]bool)
+	for _, task := range tasks {
+		if task.ParentID == parentID {
+			open[checklistKey(task.Title)] = true
+		}
+	}
+
+	added := 0
+	for _, item := range checklist {
+		if open[checklistKey(item)] {
+			continue
+		}
+		id, err := wr.Creator.CreateTask(platform.TodoistTask{
+			Title:     item,
+			ProjectID: review.ProjectID,
+			ParentID:  parentID,
+			Priority:  1,
+		})
+		if err != nil {
+			return "", fmt.Errorf("creating weekly review sub-task %q: %w", item, err)
+		}
+		results.Created = append(results.Created, TaskRef{TaskID: id, Title: item})
+		added++
+	}
+
+	return fmt.Sprintf("%s %q task (%s) with %d sub-tasks", action, weeklyReviewTaskTitle, review.Recurrence, added), nil
+}
+
+// checklistKey identifies a checklist item regardless of its counts, so
+// "Process inbox to zero (3 items)" matches "Process inbox to zero (5 items)".
+func checklistKey(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '#'
+		}
+		return r
+	}, title)
+}
+
+func (wr *WeeklyReview) now() time.Time {
+	if wr.Now != nil {
+		return wr.Now()
+	}
+	return time.Now()
+}
+
+// weeklyReviewSettings fills in defaults for unset fields.
+func weeklyReviewSettings(cfg config.WeeklyReviewConfig) config.WeeklyReviewConfig {
+	if cfg.StaleAfterDays == 0 {
+		cfg.StaleAfterDays = defaultStaleAfterDays
+	}
+	if cfg.WaitingLabel == "" {
+		cfg.WaitingLabel = defaultWaitingLabel
+	}
+	if cfg.Recurrence == "" {
+		cfg.Recurrence = defaultRecurrence
+	}
+	return cfg
+}
+
+func inboxTasks(tasks []platform.TodoistTask, projects []platform.TodoistProject) []platform.TodoistTask {
+	var inboxID string
+	for _, p := range projects {
+		if p.IsInbox {
+			inboxID = p.ID
+			break
+		}
+	}
+	if inboxID == "" {
+		return nil
+	}
+
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if task.ProjectID == inboxID {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// projectsWithoutNextAction returns the names of projects that have no task
+// carrying the next-action label, or no active tasks at all when no label is
+// configured. The inbox and the calendar-sync project are skipped.
+func projectsWithoutNextAction(tasks []platform.TodoistTask, projects []platform.TodoistProject, nextActionLabel, skipProjectID string) []string {
+	hasNextAction := make(map[string]bool)
+	for _, task := range tasks {
+		if nextActionLabel == "" || hasLabel(task, nextActionLabel) {
+			hasNextAction[task.ProjectID] = true
+		}
+	}
+
+	var names []string
+	for _, p := range projects {
+		if p.IsInbox || p.ID == skipProjectID || hasNextAction[p.ID] {
+			continue
+		}
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// staleUndatedTasks returns tasks without a due date created more than
+// staleAfterDays ago, oldest first.
+func staleUndatedTasks(tasks []platform.TodoistTask, now time.Time, staleAfterDays int) []platform.TodoistTask {
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if task.DueDate != nil || task.CreatedAt.IsZero() || task.ParentID != "" {
+			continue
+		}
+		if daysBetween(task.CreatedAt, now) > staleAfterDays {
+			result = append(result, task)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result
+}
+
+func tasksWithLabel(tasks []platform.TodoistTask, label string) []platform.TodoistTask {
+	var result []platform.TodoistTask
+	for _, task := range tasks {
+		if hasLabel(task, label) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+func hasLabel(task platform.TodoistTask, label string) bool {
+	for _, l := range task.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+func taskTitles(tasks []platform.TodoistTask) []string {
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+func taskRefs(tasks []platform.TodoistTask) []TaskRef {
+	var refs []TaskRef
+	for _, task := range tasks {
+		refs = append(refs, TaskRef{TaskID: task.ID, Title: task.Title})
+	}
+	return refs
+}
+
+func completedTitles(tasks []platform.CompletedTask) []string {
+	titles := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+// eventTable lists events by start time, or "None". All-day events show
+// their days, as in "Mon 16 Feb (all day)".
+func eventTable(events []platform.CalendarEvent) []output.Item {
+	if len(events) == 0 {
+		return []output.Item{output.Paragraph("None")}
+	}
+	table := output.Table{Columns: []string{"When", "Event"}}
+	for _, e := range events {
+		when := e.StartTime.Local().Format("Mon 2 Jan 15:04")
+		if e.AllDay {
+			when = formatAllDay(e.StartTime, e.EndTime)
+		}
+		table.Rows = append(table.Rows, []string{when, e.Title})
+	}
+	return []output.Item{table}
+}
+
+// capList truncates long lists, noting how many items were left out.
+func capList(items []string) []string {
+	if len(items) <= maxListedItems {
+		return items
+	}
+	capped := append([]string{}, items[:maxListedItems]...)
+	return append(capped, fmt.Sprintf("…and %d more", len(items)-maxListedItems))
+}
+
+// listOrNone lists entries, or says "None" when there are none.
+func listOrNone(entries []string) []output.Item {
+	if len(entries) == 0 {
+		return []output.Item{output.Paragraph("None")}
+	}
+	return []output.Item{output.List{Entries: entries}}
+}
+
+// startOfWeek returns midnight on the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,239 @@
+package capability_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/capability"
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+	"github.com/sergekukharev/agent-samwise/internal/platform"
+)
+
+type stubCompletedReader struct {
+	tasks []platform.CompletedTask
+	since time.Time
+}
+
+func (s *stubCompletedReader) CompletedTasks(since time.Time) ([]platform.CompletedTask, error) {
+	s.since = since
+	return s.tasks, nil
+}
+
+func weeklyReviewTasks() *stubTaskReader {
+	return &stubTaskReader{
+		tasks: []platform.TodoistTask{
+			{ID: "1", Title: "Random idea", ProjectID: "inbox"},
+			{ID: "2", Title: "Plan offsite", ProjectID: "work", Labels: []string{"next"}},
+			{ID: "3", Title: "Old someday", ProjectID: "home", CreatedAt: time.Date(2025, 11, 1, 0, 0, 0, 0, time.Local)},
+			{ID: "4", Title: "Contract from legal", ProjectID: "work", Labels: []string{"Waiting"}, DueDate: day(12)},
+		},
+		projects: []platform.TodoistProject{
+			{ID: "inbox", Name: "Inbox", IsInbox: true},
+			{ID: "work", Name: "Work"},
+			{ID: "home", Name: "Home"},
+			{ID: "garden", Name: "Garden"},
+		},
+	}
+}
+
+func TestWeeklyReview_Checklist(t *testing.T) {
+	var buf bytes.Buffer
+	completed := &stubCompletedReader{tasks: []platform.CompletedTask{{Title: "Ship release"}}}
+	wr := &capability.WeeklyReview{
+		Todoist:   weeklyReviewTasks(),
+		Completed: completed,
+		Calendar: &stubRangeReader{events: []platform.CalendarEvent{
+			{Title: "Sprint planning", StartTime: at(16, 10, 0), RSVP: platform.RSVPAccepted},
+			{Title: "Skipped sync", StartTime: at(16, 11, 0), RSVP: platform.RSVPDeclined},
+			{Title: "Offsite", StartTime: at(18, 0, 0), EndTime: at(20, 0, 0), AllDay: true, RSVP: platform.RSVPAccepted},
+		}},
+		Now: fixedNow,
+	}
+	cfg := config.Config{WeeklyReview: config.WeeklyReviewConfig{NextActionLabel: "next"}}
+
+	if err := wr.Run(cfg, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{
+		"- [ ] Process inbox to zero (1 items)",
+		"- [ ] Define next actions for 2 projects",
+		"- [ ] Review 1 undated tasks older than 30 days",
+		"- [ ] Follow up on 1 waiting-for items",
+		"- [ ] Review 1 completed tasks from this week",
+		"- [ ] Prepare for 2 events next week",
+		"- Garden\n- Home",
+		"- Old someday",
+		"- Contract from legal",
+		"- Ship release",
+		"| Mon 16 Feb 10:00 | Sprint planning |",
+		"| Wed 18 Feb – Thu 19 Feb | Offsite |",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+
+	if want := time.Date(2026, 2, 9, 0, 0, 0, 0, time.Local); !completed.since.Equal(want) {
+		t.Errorf("completed since = %v, want Monday %v", completed.since, want)
+	}
+}
+
+func TestWeeklyReview_SprintIssues(t *testing.T) {
+	var buf bytes.Buffer
+	wr := &capability.WeeklyReview{
+		Todoist:   weeklyReviewTasks(),
+		Completed: &stubCompletedReader{},
+		Calendar:  &stubRangeReader{},
+		Issues: []platform.IssueReader{&stubIssueReader{issues: []platform.WorkIssue{
+			{Key: "OPS-1", Title: "Fix deploys", Status: "In Progress", Priority: 3},
+		}}},
+		Now: fixedNow,
+	}
+
+	if err := wr.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := buf.String()
+	for _, want := range []string{"- [ ] Update 1 sprint issues", "- OPS-1 Fix deploys (In Progress, P2)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestWeeklyReview_CreatesRecurringTaskWithSubtasks(t *testing.T) {
+	var buf bytes.Buffer
+	creator := &stubTaskCreator{}
+	wr := &capability.WeeklyReview{
+		Todoist:    weeklyReviewTasks(),
+		Completed:  &stubCompletedReader{},
+		Calendar:   &stubRangeReader{},
+		Creator:    creator,
+		CreateTask: true,
+		Now:        fixedNow,
+	}
+
+	if err := wr.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(creator.created) != 7 {
+		t.Fatalf("created %d tasks, want parent plus 6 sub-tasks", len(creator.created))
+	}
+	parent := creator.created[0]
+	if parent.Title != "Weekly review" || parent.DueString != "every friday" {
+		t.Errorf("unexpected parent task: %+v", parent)
+	}
+	for _, sub := range creator.created[1:] {
+		if sub.ParentID != "task-1" {
+			t.Errorf("sub-task %q parent = %q, want task-1", sub.Title, sub.ParentID)
+		}
+	}
+	if !strings.Contains(buf.String(), `Created recurring "Weekly review" task`) {
+		t.Errorf("missing review task summary, got:\n%s", buf.String())
+	}
+}
+
+func TestWeeklyReview_Results(t *testing.T) {
+	out := &recordingPresenter{}
+	wr := &capability.WeeklyReview{
+		Todoist:    weeklyReviewTasks(),
+		Completed:  &stubCompletedReader{},
+		Calendar:   &stubRangeReader{},
+		Creator:    &stubTaskCreator{},
+		CreateTask: true,
+		Now:        fixedNow,
+	}
+	if err := wr.Run(config.Config{}, config.Secrets{}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	results, ok := out.briefing.Results.(capability.WeeklyReviewResult)
+	if !ok {
+		t.Fatalf("results = %T, want WeeklyReviewResult", out.briefing.Results)
+	}
+	if len(results.Checklist) != 6 || results.ReviewTaskID != "task-1" {
+		t.Errorf("results = %+v, want the checklist and the new review task", results)
+	}
+	if len(results.Created) != 7 || results.Created[0] != (capability.TaskRef{TaskID: "task-1", Title: "Weekly review"}) {
+		t.Errorf("created = %+v, want the review task and its 6 sub-tasks", results.Created)
+	}
+}
+
+func TestWeeklyReview_ReusesExistingReviewTask(t *testing.T) {
+	var buf bytes.Buffer
+	reader := weeklyReviewTasks()
+	reader.tasks = append(reader.tasks, platform.TodoistTask{ID: "wr", Title: "Weekly review", ProjectID: "inbox"})
+	creator := &stubTaskCreator{}
+	wr := &capability.WeeklyReview{
+		Todoist:    reader,
+		Completed:  &stubCompletedReader{},
+		Calendar:   &stubRangeReader{},
+		Creator:    creator,
+		Reviews:    reader,
+		CreateTask: true,
+		Now:        fixedNow,
+	}
+
+	if err := wr.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(creator.created) != 6 {
+		t.Fatalf("created %d tasks, want 6 sub-tasks only", len(creator.created))
+	}
+	if creator.created[0].ParentID != "wr" {
+		t.Errorf("parent = %q, want existing review task", creator.created[0].ParentID)
+	}
+}
+
+func TestWeeklyReview_SkipsExistingSubtasks(t *testing.T) {
+	reader := weeklyReviewTasks()
+	reader.tasks = append(reader.tasks, platform.TodoistTask{ID: "wr", Title: "Weekly review", ProjectID: "inbox"})
+	creator := &stubTaskCreator{}
+	wr := &capability.WeeklyReview{
+		Todoist:    reader,
+		Completed:  &stubCompletedReader{},
+		Calendar:   &stubRangeReader{},
+		Creator:    creator,
+		Reviews:    reader,
+		CreateTask: true,
+		Now:        fixedNow,
+	}
+
+	if err := wr.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &bytes.Buffer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creator.created) != 6 {
+		t.Fatalf("created %d tasks on the first run, want 6 sub-tasks", len(creator.created))
+	}
+
+	// The second run sees the sub-tasks, one of them completed and another
+	// with a different count by now.
+	for i, sub := range creator.created[1:] {
+		if i == 0 {
+			sub.Title = strings.Replace(sub.Title, "2 projects", "3 projects", 1)
+		}
+		sub.ID = fmt.Sprintf("sub-%d", i)
+		reader.tasks = append(reader.tasks, sub)
+	}
+	creator.created = nil
+
+	var buf bytes.Buffer
+	if err := wr.Run(config.Config{}, config.Secrets{}, &output.TerminalPresenter{Writer: &buf}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(creator.created) != 1 || !strings.HasPrefix(creator.created[0].Title, "Process inbox") {
+		t.Errorf("created %+v on the second run, want only the completed sub-task again", creator.created)
+	}
+	if !strings.Contains(buf.String(), `Added checklist to "Weekly review" task (every friday) with 1 sub-tasks`) {
+		t.Errorf("missing review task summary, got:\n%s", buf.String())
+	}
+}
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"flag"
+
 	"github.com/sergekukharev/agent-samwise/internal/config"
 	"github.com/sergekukharev/agent-samwise/internal/output"
 )
@@ -15,4 +17,7 @@ type Capability struct {
 	RequiredEnv []string
 	// RequiredConfig lists the capability names used to validate config sections.
 	RequiredConfig []string
+	// Flags registers capability-specific flags (e.g., --apply), parsed from
+	// the arguments that follow the subcommand. Optional.
+	Flags func(fs *flag.FlagSet)
 }
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -26,10 +27,12 @@ func NewRouter(capabilities []Capability) *Router {
 }
 
 // Run parses arguments and dispatches to the appropriate capability.
-// It returns an exit code (0 for success, 1 for errors).
+// It returns an exit code (0 for success, 1 for errors, 2 when a briefing
+// reached only some of the configured outputs).
 func (r *Router) Run(args []string) int {
 	fs := flag.NewFlagSet("sam", flag.ContinueOnError)
 	configPath := fs.String("config", config.DefaultPath, "path to config file")
+	format := fs.String("output", "", "print the briefing as json, yaml or markdown, or send it by email, instead of detecting the destination")
 
 	// Parse global flags from the full argument list.
 	// flag.FlagSet stops at the first non-flag argument (the subcommand).
@@ -53,6 +56,15 @@ func (r *Router) Run(args []string) int {
 		return 1
 	}
 
+	capFlags := flag.NewFlagSet("sam "+subcmd, flag.ContinueOnError)
+	if cap.Flags != nil {
+		cap.Flags(capFlags)
+	}
+	if err := capFlags.Parse(remaining[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+
 	cfg, err := config.Load(*configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -66,26 +78,50 @@ func (r *Router) Run(args []string) int {
 		}
 	}
 
-	secrets, err := config.ResolveSecrets(cap.RequiredEnv...)
+	secrets, err := config.ResolveSecrets(cfg.EnvFor(cap.RequiredEnv...)...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
-	presenter, err := output.DetectPresenter()
+	presenter, err := output.DetectPresenter(*format, cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		return 1
 	}
 
-	if err := cap.Run(cfg, secrets, presenter); err != nil {
+	if err := cap.Run(cfg, secrets, &capabilityPresenter{name: cap.Name, next: presenter}); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if r, ok := presenter.(failureReporter); ok {
+			r.Fail(err)
+		}
+		var delivery *output.DeliveryError
+		if errors.As(err, &delivery) && delivery.Partial() {
+			return 2
+		}
 		return 1
 	}
 
 	return 0
 }
 
+// failureReporter is a presenter that also reports errors ending the run,
+// such as output.GitHubActionsPresenter.
+type failureReporter interface {
+	Fail(err error)
+}
+
+// capabilityPresenter records which capability produced each briefing.
+type capabilityPresenter struct {
+	name string
+	next output.Presenter
+}
+
+func (p *capabilityPresenter) Present(briefing output.Briefing) error {
+	briefing.Capability = p.name
+	return p.next.Present(briefing)
+}
+
 func (r *Router) printHelp() {
 	fmt.Println("Sam — your personal assistant")
 	fmt.Println()
@@ -93,6 +129,7 @@ func (r *Router) printHelp() {
 	fmt.Println()
 	fmt.Println("Flags:")
 	fmt.Println("  --config <path>  path to config file (default: config.yaml)")
+	fmt.Println("  --output <fmt>   print json, yaml or markdown, or send email, instead of detecting the destination")
 	fmt.Println()
 	fmt.Println("Commands:")
 
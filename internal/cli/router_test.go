@@ -1,8 +1,15 @@
 package cli_test
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sergekukharev/agent-samwise/internal/cli"
@@ -18,7 +25,7 @@ func testCapabilities() []cli.Capability {
 			Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
 				return out.Present(output.Briefing{
 					Title:    "Hello",
-					Sections: []output.Section{{Heading: "Greeting", Body: "Hi there!"}},
+					Sections: []output.Section{{Heading: "Greeting", Items: []output.Item{output.Paragraph("Hi there!")}}},
 				})
 			},
 		},
@@ -121,3 +128,171 @@ func writeMinimalConfig(t *testing.T) string {
 	}
 	return path
 }
+
+func TestRouter_CapabilityFlags(t *testing.T) {
+	cfgPath := writeMinimalConfig(t)
+
+	var apply bool
+	router := cli.NewRouter([]cli.Capability{
+		{
+			Name:        "test",
+			Description: "test command",
+			Flags: func(fs *flag.FlagSet) {
+				fs.BoolVar(&apply, "apply", false, "apply changes")
+			},
+			Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+				return nil
+			},
+		},
+	})
+
+	code := router.Run([]string{"--config", cfgPath, "test", "--apply"})
+	if code != 0 {
+		t.Errorf("exit code = %d, want 0", code)
+	}
+	if !apply {
+		t.Error("capability flag --apply was not parsed")
+	}
+}
+
+func TestRouter_UnknownCapabilityFlag(t *testing.T) {
+	cfgPath := writeMinimalConfig(t)
+
+	router := cli.NewRouter(testCapabilities())
+	code := router.Run([]string{"--config", cfgPath, "greet", "--bogus"})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1 for unknown flag", code)
+	}
+}
+
+// captureStdout returns what fn prints to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestRouter_OutputJSON(t *testing.T) {
+	cfgPath := writeMinimalConfig(t)
+	// The flag wins over delivering to Slack from GitHub Actions.
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = cli.NewRouter(testCapabilities()).Run([]string{"--config", cfgPath, "--output", "json", "greet"})
+	})
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0", code)
+	}
+
+	var doc struct {
+		SchemaVersion int    `json:"schema_version"`
+		Capability    string `json:"capability"`
+		Sections      []struct {
+			Heading string `json:"heading"`
+			Items   []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"items"`
+		} `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not json: %v\n%s", err, out)
+	}
+	if doc.SchemaVersion != 1 || doc.Capability != "greet" {
+		t.Errorf("document = %+v, want schema version 1 from greet", doc)
+	}
+	if len(doc.Sections) != 1 || doc.Sections[0].Items[0].Text != "Hi there!" {
+		t.Errorf("sections = %+v, want the greeting", doc.Sections)
+	}
+}
+
+func TestRouter_UnknownOutputFormat(t *testing.T) {
+	cfgPath := writeMinimalConfig(t)
+
+	code := cli.NewRouter(testCapabilities()).Run([]string{"--config", cfgPath, "--output", "xml", "greet"})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1 for unknown output format", code)
+	}
+}
+
+func TestRouter_PartialDeliveryFailure(t *testing.T) {
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer slack.Close()
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", slack.URL)
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "briefings.md")
+	skipped := filepath.Join(dir, "reviews.md")
+	cfgPath := filepath.Join(dir, "config.yaml")
+	cfg := "outputs:\n" +
+		"  - type: slack\n" +
+		"  - type: markdown\n    path: " + archive + "\n" +
+		"  - type: markdown\n    path: " + skipped + "\n    capabilities: [weekly-review]\n"
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code := cli.NewRouter(testCapabilities()).Run([]string{"--config", cfgPath, "greet"})
+	if code != 2 {
+		t.Errorf("exit code = %d, want 2 for partial delivery", code)
+	}
+
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatalf("expected the archive to be written despite the slack failure: %v", err)
+	}
+	if !strings.Contains(string(data), "Hi there!") {
+		t.Errorf("archive = %q, want the greeting", data)
+	}
+	if _, err := os.Stat(skipped); !os.IsNotExist(err) {
+		t.Error("expected output filtered to weekly-review not to receive greet")
+	}
+}
+
+func TestRouter_ReportsFailureToGitHubActions(t *testing.T) {
+	cfgPath := writeMinimalConfig(t)
+	summary := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", summary)
+	t.Setenv("GITHUB_OUTPUT", "")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/test")
+
+	failing := cli.Capability{
+		Name: "fail",
+		Run: func(cfg config.Config, secrets config.Secrets, out output.Presenter) error {
+			return fmt.Errorf("fetching tasks: unauthorized")
+		},
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = cli.NewRouter([]cli.Capability{failing}).Run([]string{"--config", cfgPath, "fail"})
+	})
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	if out != "::error title=Sam failed::fetching tasks: unauthorized\n" {
+		t.Errorf("stdout = %q, want an error annotation", out)
+	}
+	data, err := os.ReadFile(summary)
+	if err != nil || !strings.Contains(string(data), "**Sam failed:** fetching tasks: unauthorized") {
+		t.Errorf("summary = %q (%v), want the failure", data, err)
+	}
+}
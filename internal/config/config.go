@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,13 +15,36 @@ const DefaultPath = "config.yaml"
 type Config struct {
 	Calendar CalendarConfig `yaml:"calendar"`
 	Todoist  TodoistConfig  `yaml:"todoist"`
+	Tasks    TasksConfig    `yaml:"tasks"`
 	Gmail    GmailConfig    `yaml:"gmail"`
 	Slack    SlackConfig    `yaml:"slack"`
+	SMTP     SMTPConfig     `yaml:"smtp"`
+	Ntfy     NtfyConfig     `yaml:"ntfy"`
+	Pushover PushoverConfig `yaml:"pushover"`
+	Telegram TelegramConfig `yaml:"telegram"`
+	Outputs  []OutputConfig `yaml:"outputs"`
+	Journal  JournalConfig  `yaml:"journal"`
 	Areas    []Area         `yaml:"areas"`
+
+	TaskDigest TaskDigestConfig `yaml:"task_digest"`
+	Capacity   CapacityConfig   `yaml:"capacity"`
+
+	WeeklyReview WeeklyReviewConfig `yaml:"weekly_review"`
+	DailyRecap   DailyRecapConfig   `yaml:"daily_recap"`
+	EmailTriage  EmailTriageConfig  `yaml:"email_triage"`
+	EmailTasks   EmailTasksConfig   `yaml:"email_tasks"`
+	FollowUps    FollowUpsConfig    `yaml:"follow_ups"`
+	Invitations  InvitationsConfig  `yaml:"invitations"`
+	Newsletters  NewslettersConfig  `yaml:"newsletters"`
+
+	Mail MailConfig `yaml:"mail"`
 }
 
 type CalendarConfig struct {
 	CalendarID string `yaml:"calendar_id"`
+	// EventComments makes calendar-sync attach each event's agenda, attendees,
+	// location and attachments to its task as a Todoist comment.
+	EventComments bool `yaml:"event_comments"`
 }
 
 type TodoistConfig struct {
@@ -28,12 +52,389 @@ type TodoistConfig struct {
 	KanbanBoardID string `yaml:"kanban_board_id"`
 }
 
+// Task backends that capabilities can create tasks in.
+const (
+	TaskBackendTodoist = "todoist"
+	TaskBackendFile    = "file"
+	TaskBackendGitHub  = "github"
+	TaskBackendCalDAV  = "caldav"
+	TaskBackendJira    = "jira"
+	TaskBackendLinear  = "linear"
+)
+
+// TasksConfig selects the backends that capabilities create tasks in.
+type TasksConfig struct {
+	// Backend receives personal tasks (weekly-review, email-tasks and
+	// follow-ups). It is one of "todoist" (default), "file", "github",
+	// "caldav", "jira" or "linear".
+	Backend string `yaml:"backend"`
+	// CalendarBackend routes tasks derived from calendar events (calendar-sync)
+	// to a different backend, e.g. work meetings to Jira while personal tasks
+	// stay in Todoist. Defaults to Backend.
+	CalendarBackend string `yaml:"calendar_backend"`
+	// Issues lists work trackers ("jira", "linear") whose issues assigned to
+	// you in the current sprint are included in task-digest and weekly-review.
+	Issues []string `yaml:"issues"`
+
+	File   FileTasksConfig   `yaml:"file"`
+	GitHub GitHubTasksConfig `yaml:"github"`
+	CalDAV CalDAVTasksConfig `yaml:"caldav"`
+	Jira   JiraTasksConfig   `yaml:"jira"`
+	Linear LinearTasksConfig `yaml:"linear"`
+}
+
+// BackendName returns the configured backend, defaulting to Todoist.
+func (t TasksConfig) BackendName() string {
+	if t.Backend == "" {
+		return TaskBackendTodoist
+	}
+	return t.Backend
+}
+
+// CalendarBackendName returns the backend for calendar-derived tasks,
+// defaulting to BackendName.
+func (t TasksConfig) CalendarBackendName() string {
+	if t.CalendarBackend == "" {
+		return t.BackendName()
+	}
+	return t.CalendarBackend
+}
+
+// FileTasksConfig configures the local file backend for offline use.
+type FileTasksConfig struct {
+	Path string `yaml:"path"`
+	// Format is "markdown" (default) or "todotxt".
+	Format string `yaml:"format"`
+}
+
+// GitHubTasksConfig configures the GitHub Issues backend.
+// The token comes from the GITHUB_TOKEN env var.
+type GitHubTasksConfig struct {
+	// Repo is the repository to open issues in, as "owner/name".
+	Repo   string   `yaml:"repo"`
+	Labels []string `yaml:"labels"`
+}
+
+// CalDAVTasksConfig configures the CalDAV VTODO backend (e.g., Apple Reminders).
+// Credentials come from the CALDAV_USERNAME and CALDAV_PASSWORD env vars.
+type CalDAVTasksConfig struct {
+	// URL is the task list's calendar collection URL.
+	URL string `yaml:"url"`
+}
+
+// JiraTasksConfig configures the Jira Cloud backend.
+// Credentials come from the JIRA_EMAIL and JIRA_API_TOKEN env vars.
+type JiraTasksConfig struct {
+	// Site is the Jira Cloud URL, e.g. https://example.atlassian.net.
+	Site       string `yaml:"site"`
+	ProjectKey string `yaml:"project_key"`
+	// IssueType for created issues. Defaults to "Task".
+	IssueType string `yaml:"issue_type"`
+}
+
+// LinearTasksConfig configures the Linear backend.
+// The API key comes from the LINEAR_API_KEY env var.
+type LinearTasksConfig struct {
+	// TeamID is the team new issues are created in.
+	TeamID string `yaml:"team_id"`
+}
+
+// TaskDigestConfig controls which tasks the task-digest capability reports on.
+type TaskDigestConfig struct {
+	// ProjectIDs limits the digest to these projects. Empty means all projects.
+	ProjectIDs []string `yaml:"project_ids"`
+	// OverdueDays highlights tasks that are overdue by more than this many days.
+	OverdueDays int `yaml:"overdue_days"`
+	// RescheduledTimes highlights non-recurring tasks whose due date was
+	// moved more than this many times in the last 30 days, as recorded by
+	// Todoist's activity log (a Pro feature). Defaults to 2.
+	RescheduledTimes int `yaml:"rescheduled_times"`
+}
+
+// CapacityConfig describes the working day used to compute free time.
+type CapacityConfig struct {
+	// WorkdayStart and WorkdayEnd are local times in HH:MM format.
+	// They default to 09:00 and 17:00.
+	WorkdayStart string `yaml:"workday_start"`
+	WorkdayEnd   string `yaml:"workday_end"`
+	// DefaultTaskMinutes is the estimate for tasks with no Todoist duration
+	// or estimate label. Defaults to 30.
+	DefaultTaskMinutes int `yaml:"default_task_minutes"`
+}
+
+// Workday returns the start and end of the working day, defaulting to 09:00
+// and 17:00.
+func (c CapacityConfig) Workday() (start, end string) {
+	start, end = c.WorkdayStart, c.WorkdayEnd
+	if start == "" {
+		start = "09:00"
+	}
+	if end == "" {
+		end = "17:00"
+	}
+	return start, end
+}
+
+// WeeklyReviewConfig tunes the GTD-style weekly-review capability.
+type WeeklyReviewConfig struct {
+	// StaleAfterDays flags undated tasks created more than this many days ago.
+	// Defaults to 30.
+	StaleAfterDays int `yaml:"stale_after_days"`
+	// WaitingLabel marks waiting-for items. Defaults to "waiting".
+	WaitingLabel string `yaml:"waiting_label"`
+	// NextActionLabel, when set, is the label a project needs on at least one
+	// task to count as having a next action. When empty, any active task counts.
+	NextActionLabel string `yaml:"next_action_label"`
+	// ProjectID is where the "Weekly review" task is created. Defaults to the inbox.
+	ProjectID string `yaml:"project_id"`
+	// Recurrence is the Todoist due string for the review task.
+	// Defaults to "every friday".
+	Recurrence string `yaml:"recurrence"`
+}
+
+// DailyRecapConfig controls where the end-of-day recap is journaled.
+type DailyRecapConfig struct {
+	// JournalDir receives one Markdown file per day (e.g., 2026-02-06.md),
+	// in which a re-run replaces the day's recap. It is meant for local runs:
+	// files written on a CI runner are lost when the job ends. When empty,
+	// the recap is only presented, not journaled.
+	JournalDir string `yaml:"journal_dir"`
+}
+
 type GmailConfig struct {
-	// No config fields yet — Gmail capability will use the authenticated user's inbox.
+	// User is the mailbox to read. The service account impersonates this user
+	// through domain-wide delegation, which must grant it the Gmail scopes.
+	User string `yaml:"user"`
+	// Aliases are other addresses that count as you, e.g. when deciding
+	// whether you sent the last message in a thread.
+	Aliases []string `yaml:"aliases"`
 }
 
+// Mail backends that email capabilities read from.
+const (
+	MailBackendGmail = "gmail"
+	MailBackendIMAP  = "imap"
+)
+
+// MailConfig selects the mailbox email capabilities read.
+type MailConfig struct {
+	// Backend is "gmail" (default) or "imap". Gmail settings live under gmail.
+	Backend string     `yaml:"backend"`
+	IMAP    IMAPConfig `yaml:"imap"`
+}
+
+// BackendName returns the configured backend, defaulting to Gmail.
+func (m MailConfig) BackendName() string {
+	if m.Backend == "" {
+		return MailBackendGmail
+	}
+	return m.Backend
+}
+
+// IMAPConfig configures an IMAP server such as Fastmail, Outlook or Dovecot.
+// The username and password come from IMAP_USERNAME and IMAP_PASSWORD.
+type IMAPConfig struct {
+	// Host is the server name, e.g. "imap.fastmail.com". Connections use TLS.
+	Host string `yaml:"host"`
+	// Port defaults to 993.
+	Port int `yaml:"port"`
+	// Address is your email address, used to tell your messages from others'.
+	Address string `yaml:"address"`
+	// Aliases are other addresses that count as you.
+	Aliases []string `yaml:"aliases"`
+	// SentFolder holds sent mail. Defaults to "Sent".
+	SentFolder string `yaml:"sent_folder"`
+}
+
+// MailAddresses returns your address and aliases in the configured mail
+// backend.
+func (c Config) MailAddresses() []string {
+	if c.Mail.BackendName() == MailBackendIMAP {
+		return append([]string{c.Mail.IMAP.Address}, c.Mail.IMAP.Aliases...)
+	}
+	return append([]string{c.Gmail.User}, c.Gmail.Aliases...)
+}
+
+// Email triage buckets.
+const (
+	BucketNeedsReply   = "needs_reply"
+	BucketWaiting      = "waiting"
+	BucketFYI          = "fyi"
+	BucketNewsletter   = "newsletter"
+	BucketNotification = "notification"
+)
+
+// EmailTriageConfig tunes how email-triage buckets inbox threads.
+type EmailTriageConfig struct {
+	// Query narrows the inbox threads considered, in Gmail search syntax
+	// (e.g., "newer_than:30d"). Empty means the whole inbox.
+	Query string `yaml:"query"`
+	// TopThreads caps how many threads are listed per bucket. Defaults to 5.
+	TopThreads int `yaml:"top_threads"`
+	// Rules are checked in order and the first match picks the bucket.
+	// Threads matching no rule are bucketed by built-in heuristics.
+	Rules []TriageRule `yaml:"rules"`
+	// Actions change threads once they are bucketed. They need the
+	// gmail.modify scope and run only when at least one is configured.
+	Actions []TriageAction `yaml:"actions"`
+}
+
+// TriageRule assigns threads to a bucket. Every condition that is set must
+// match; list conditions match when any entry does.
+type TriageRule struct {
+	// Bucket is one of needs_reply, waiting, fyi, newsletter or notification.
+	Bucket string `yaml:"bucket"`
+	// SenderDomains match the first sender's domain, including subdomains.
+	SenderDomains []string `yaml:"sender_domains"`
+	// Labels match Gmail label names, case-insensitively.
+	Labels []string `yaml:"labels"`
+	// Keywords match the subject or snippet, case-insensitively.
+	Keywords []string `yaml:"keywords"`
+	// Recipient is "to" or "cc": you must be a recipient in that field of
+	// the latest message.
+	Recipient string `yaml:"recipient"`
+	// OlderThanDays matches threads whose latest message is older than this.
+	OlderThanDays int `yaml:"older_than_days"`
+}
+
+// TriageAction modifies the threads of a bucket. Every action that is set
+// is applied; a thread matching several actions gets all of them.
+type TriageAction struct {
+	// Bucket is one of needs_reply, waiting, fyi, newsletter or notification.
+	Bucket string `yaml:"bucket"`
+	// OlderThanDays limits the action to threads whose latest message is
+	// older than this.
+	OlderThanDays int `yaml:"older_than_days"`
+	// Archive removes the threads from the inbox.
+	Archive bool `yaml:"archive"`
+	// Label applies a Gmail label by name, creating it if needed.
+	Label string `yaml:"label"`
+	// MarkRead marks unread threads as read.
+	MarkRead bool `yaml:"mark_read"`
+}
+
+// SlackConfig configures where the Slack bot posts briefings. The bot token
+// comes from the SLACK_BOT_TOKEN env var and the webhook URL from
+// SLACK_WEBHOOK_URL, not config; the webhook is used when no bot token is set.
 type SlackConfig struct {
-	// Webhook URL comes from SLACK_WEBHOOK_URL env var, not config.
+	// Channel is the ID of the channel the bot posts in.
+	Channel string `yaml:"channel"`
+	// User is the ID of a user the bot sends briefings to as a direct
+	// message, instead of posting in Channel.
+	User string `yaml:"user"`
+}
+
+// SMTP transport security modes.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNoTLS    = "none"
+)
+
+// SMTPConfig configures delivering briefings by email. The username and
+// password come from SMTP_USERNAME and SMTP_PASSWORD; without them the
+// server is used unauthenticated.
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	// Port defaults to 587, or 465 with implicit TLS.
+	Port int `yaml:"port"`
+	// TLS is "starttls" (default), "tls" for implicit TLS, or "none" for
+	// local servers such as MailHog.
+	TLS  string   `yaml:"tls"`
+	From string   `yaml:"from"`
+	To   []string `yaml:"to"`
+	// Subject is a text/template rendered with .Title, .Capability and
+	// .Date. Defaults to "{{.Title}}".
+	Subject string `yaml:"subject"`
+}
+
+// TLSMode returns the configured transport security, defaulting to STARTTLS.
+func (s SMTPConfig) TLSMode() string {
+	if s.TLS == "" {
+		return SMTPStartTLS
+	}
+	return s.TLS
+}
+
+// Destination types for outputs.
+const (
+	OutputSlack      = "slack"
+	OutputDiscord    = "discord"
+	OutputTeams      = "teams"
+	OutputMattermost = "mattermost"
+	OutputEmail      = "email"
+	OutputJournal    = "journal"
+	OutputNtfy       = "ntfy"
+	OutputPushover   = "pushover"
+	OutputTelegram   = "telegram"
+	OutputMarkdown   = "markdown"
+	OutputJSON       = "json"
+	OutputYAML       = "yaml"
+)
+
+// OutputConfig is one destination briefings are delivered to. When outputs
+// are configured, every matching destination receives each briefing instead
+// of the one detected from the environment.
+type OutputConfig struct {
+	// Type is one of slack, discord, teams, mattermost, email, journal,
+	// ntfy, pushover, telegram, markdown, json or yaml. Slack uses the bot
+	// token or webhook from the environment, the other chats
+	// DISCORD_WEBHOOK_URL, TEAMS_WEBHOOK_URL or MATTERMOST_WEBHOOK_URL, and
+	// the rest their own settings. ntfy, pushover and telegram only push
+	// urgent sections.
+	Type string `yaml:"type"`
+	// Name identifies the destination in errors. Defaults to Type.
+	Name string `yaml:"name"`
+	// Capabilities limits the destination to briefings from these commands
+	// (e.g., task-digest). Empty means all of them.
+	Capabilities []string `yaml:"capabilities"`
+	// Path appends markdown, json or yaml briefings to a file instead of
+	// printing them to stdout.
+	Path string `yaml:"path"`
+}
+
+// DisplayName returns the name used for the destination in errors.
+func (o OutputConfig) DisplayName() string {
+	if o.Name == "" {
+		return o.Type
+	}
+	return o.Name
+}
+
+// NtfyConfig configures push notifications through ntfy. An access token
+// for protected topics comes from the NTFY_TOKEN env var.
+type NtfyConfig struct {
+	// Server defaults to https://ntfy.sh.
+	Server string `yaml:"server"`
+	Topic  string `yaml:"topic"`
+}
+
+// PushoverConfig configures push notifications through Pushover. The
+// application token comes from the PUSHOVER_TOKEN env var.
+type PushoverConfig struct {
+	// User is the user or group key to notify.
+	User string `yaml:"user"`
+	// Device limits notifications to one of the user's devices.
+	Device string `yaml:"device"`
+}
+
+// TelegramConfig configures messages from a Telegram bot. The bot token
+// comes from the TELEGRAM_BOT_TOKEN env var.
+type TelegramConfig struct {
+	// ChatID is the chat the bot messages, such as your user ID.
+	ChatID string `yaml:"chat_id"`
+}
+
+// JournalConfig configures the journal output, which keeps briefings in a
+// Markdown vault such as Obsidian or Logseq.
+type JournalConfig struct {
+	// Dir receives one Markdown file per day, e.g. "vault/Daily".
+	Dir string `yaml:"dir"`
+	// FileFormat is the Go time layout of file names, without the .md
+	// extension. Defaults to "2006-01-02"; Logseq uses "2006_01_02".
+	FileFormat string `yaml:"file_format"`
+	// Tags are set in the frontmatter of new files. Defaults to [sam].
+	Tags []string `yaml:"tags"`
 }
 
 // Area represents a project or area of interest for calendar recommendations.
@@ -42,6 +443,91 @@ type Area struct {
 	Keywords []string `yaml:"keywords"`
 }
 
+// EmailTasksConfig controls how email-tasks turns flagged Gmail threads into
+// Todoist tasks.
+type EmailTasksConfig struct {
+	// Label marks threads to act on. Empty means starred threads.
+	Label string `yaml:"label"`
+	// ProjectID receives the tasks. Defaults to the Todoist inbox.
+	ProjectID string `yaml:"project_id"`
+	// RemoveLabel unflags a thread (removes the label or star) once its task
+	// exists. It needs the gmail.modify scope. Without it, unflag threads
+	// yourself when completing their task, or the task is created again.
+	RemoveLabel bool `yaml:"remove_label"`
+	// DueRules date threads with no date in their subject or snippet.
+	// The first matching rule wins.
+	DueRules []EmailDueRule `yaml:"due_rules"`
+	// DefaultDue is the Todoist due string (e.g., "today") for threads no
+	// rule matches. Empty leaves them undated.
+	DefaultDue string `yaml:"default_due"`
+}
+
+// EmailDueRule sets a due date for matching threads. Every condition that is
+// set must match; list conditions match when any entry does.
+type EmailDueRule struct {
+	SenderDomains []string `yaml:"sender_domains"`
+	Keywords      []string `yaml:"keywords"`
+	// Due is a Todoist due string such as "tomorrow" or "in 3 days".
+	Due string `yaml:"due"`
+}
+
+// FollowUpsConfig controls which sent threads count as waiting for a reply.
+type FollowUpsConfig struct {
+	// AfterBusinessDays lists threads whose last message you sent at least
+	// this many business days ago. Defaults to 3.
+	AfterBusinessDays int `yaml:"after_business_days"`
+	// LookbackDays limits the search to threads active in this many days.
+	// Defaults to 30.
+	LookbackDays int `yaml:"lookback_days"`
+	// ProjectID receives follow-up tasks. Defaults to the Todoist inbox.
+	ProjectID string `yaml:"project_id"`
+}
+
+// RSVP responses an invitation rule can suggest.
+const (
+	ResponseAccept    = "accept"
+	ResponseDecline   = "decline"
+	ResponseTentative = "tentative"
+)
+
+// InvitationsConfig controls which emailed invitations are listed and how
+// they are answered.
+type InvitationsConfig struct {
+	// Query narrows the inbox messages searched for invitations, in Gmail
+	// search syntax. Defaults to "newer_than:30d".
+	Query string `yaml:"query"`
+	// Rules are checked in order and the first match suggests a response.
+	// Invitations matching no rule get no suggestion and are never answered.
+	Rules []RSVPRule `yaml:"rules"`
+}
+
+// RSVPRule suggests a response for matching invitations. Every condition
+// that is set must match; list conditions match when any entry does.
+type RSVPRule struct {
+	// Response is one of accept, decline or tentative.
+	Response         string   `yaml:"response"`
+	OrganizerDomains []string `yaml:"organizer_domains"`
+	// Keywords match the event title.
+	Keywords []string `yaml:"keywords"`
+	// Areas match the name of the area the event belongs to.
+	Areas []string `yaml:"areas"`
+	// Conflict, when set, matches only events that do (true) or do not
+	// (false) overlap another event you have not declined.
+	Conflict *bool `yaml:"conflict"`
+}
+
+// NewslettersConfig controls which inbox mail newsletter-digest collects.
+type NewslettersConfig struct {
+	// Query narrows the inbox threads considered, in Gmail search syntax.
+	// Defaults to "newer_than:7d".
+	Query string `yaml:"query"`
+	// Senders are addresses or domains whose mail counts as a newsletter
+	// even without List-Unsubscribe or List-Id headers.
+	Senders []string `yaml:"senders"`
+	// MaxHeadlines caps the links listed per newsletter. Defaults to 5.
+	MaxHeadlines int `yaml:"max_headlines"`
+}
+
 // Load reads and parses a YAML config file from the given path.
 func Load(path string) (Config, error) {
 	data, err := os.ReadFile(path)
@@ -65,6 +551,14 @@ type Secrets struct {
 	GoogleCredentials string
 	TodoistAPIToken   string
 	SlackWebhookURL   string
+	GitHubToken       string
+	CalDAVUsername    string
+	CalDAVPassword    string
+	JiraEmail         string
+	JiraAPIToken      string
+	LinearAPIKey      string
+	IMAPUsername      string
+	IMAPPassword      string
 }
 
 // EnvVar defines a required environment variable for a capability.
@@ -85,6 +579,29 @@ var slackEnv = []EnvVar{
 	{Name: "SLACK_WEBHOOK_URL", Capability: "slack"},
 }
 
+var githubEnv = []EnvVar{
+	{Name: "GITHUB_TOKEN", Capability: "github"},
+}
+
+var caldavEnv = []EnvVar{
+	{Name: "CALDAV_USERNAME", Capability: "caldav"},
+	{Name: "CALDAV_PASSWORD", Capability: "caldav"},
+}
+
+var jiraEnv = []EnvVar{
+	{Name: "JIRA_EMAIL", Capability: "jira"},
+	{Name: "JIRA_API_TOKEN", Capability: "jira"},
+}
+
+var linearEnv = []EnvVar{
+	{Name: "LINEAR_API_KEY", Capability: "linear"},
+}
+
+var imapEnv = []EnvVar{
+	{Name: "IMAP_USERNAME", Capability: "imap"},
+	{Name: "IMAP_PASSWORD", Capability: "imap"},
+}
+
 // ResolveSecrets reads required environment variables and returns Secrets.
 // Only the env vars needed by the given capabilities are checked.
 func ResolveSecrets(capabilities ...string) (Secrets, error) {
@@ -109,6 +626,14 @@ func ResolveSecrets(capabilities ...string) (Secrets, error) {
 		GoogleCredentials: values["GOOGLE_CREDENTIALS"],
 		TodoistAPIToken:   values["TODOIST_API_TOKEN"],
 		SlackWebhookURL:   values["SLACK_WEBHOOK_URL"],
+		GitHubToken:       values["GITHUB_TOKEN"],
+		CalDAVUsername:    values["CALDAV_USERNAME"],
+		CalDAVPassword:    values["CALDAV_PASSWORD"],
+		JiraEmail:         values["JIRA_EMAIL"],
+		JiraAPIToken:      values["JIRA_API_TOKEN"],
+		LinearAPIKey:      values["LINEAR_API_KEY"],
+		IMAPUsername:      values["IMAP_USERNAME"],
+		IMAPPassword:      values["IMAP_PASSWORD"],
 	}, nil
 }
 
@@ -122,11 +647,52 @@ func requiredEnvVars(capabilities []string) []EnvVar {
 			result = append(result, todoistEnv...)
 		case "slack":
 			result = append(result, slackEnv...)
+		case "github":
+			result = append(result, githubEnv...)
+		case "caldav":
+			result = append(result, caldavEnv...)
+		case "jira":
+			result = append(result, jiraEnv...)
+		case "linear":
+			result = append(result, linearEnv...)
+		case "imap":
+			result = append(result, imapEnv...)
 		}
 	}
 	return dedupEnvVars(result)
 }
 
+// EnvFor expands capability names that depend on configuration into the
+// names ResolveSecrets understands: "tasks" and "calendar-tasks" become the
+// configured task backend, "issues" the configured work trackers and "mail"
+// the configured mail backend.
+func (c Config) EnvFor(capabilities ...string) []string {
+	var result []string
+	for _, cap := range capabilities {
+		switch cap {
+		case "tasks":
+			result = appendBackendEnv(result, c.Tasks.BackendName())
+		case "calendar-tasks":
+			result = appendBackendEnv(result, c.Tasks.CalendarBackendName())
+		case "issues":
+			result = append(result, c.Tasks.Issues...)
+		case "mail":
+			result = append(result, c.Mail.BackendName())
+		default:
+			result = append(result, cap)
+		}
+	}
+	return result
+}
+
+// appendBackendEnv adds the backend unless it needs no credentials.
+func appendBackendEnv(result []string, backend string) []string {
+	if backend == TaskBackendFile {
+		return result
+	}
+	return append(result, backend)
+}
+
 func dedupEnvVars(vars []EnvVar) []EnvVar {
 	seen := make(map[string]bool)
 	var result []EnvVar
@@ -150,10 +716,138 @@ func (c Config) ValidateFor(capability string) error {
 		if c.Todoist.ProjectID == "" {
 			return fmt.Errorf("todoist.project_id is required for the todoist capability")
 		}
+	case "tasks":
+		return c.validateTaskBackend("tasks.backend", c.Tasks.BackendName())
+	case "calendar-tasks":
+		return c.validateTaskBackend("tasks.calendar_backend", c.Tasks.CalendarBackendName())
+	case "issues":
+		for _, source := range c.Tasks.Issues {
+			switch source {
+			case TaskBackendJira:
+				// Reading only needs the site; project_key is for creating issues.
+				if c.Tasks.Jira.Site == "" {
+					return fmt.Errorf("tasks.jira.site is required to read jira issues")
+				}
+			case TaskBackendLinear:
+			default:
+				return fmt.Errorf("unknown tasks.issues entry %q (want jira or linear)", source)
+			}
+		}
+	case "mail":
+		switch c.Mail.BackendName() {
+		case MailBackendGmail:
+		case MailBackendIMAP:
+			if c.Mail.IMAP.Host == "" || c.Mail.IMAP.Address == "" {
+				return fmt.Errorf("mail.imap.host and mail.imap.address are required for the imap mail backend")
+			}
+		default:
+			return fmt.Errorf("unknown mail.backend %q (want gmail or imap)", c.Mail.Backend)
+		}
+	case "smtp":
+		if c.SMTP.Host == "" || c.SMTP.From == "" || len(c.SMTP.To) == 0 {
+			return fmt.Errorf("smtp.host, smtp.from and smtp.to are required to deliver briefings by email")
+		}
+		switch c.SMTP.TLSMode() {
+		case SMTPStartTLS, SMTPTLS, SMTPNoTLS:
+		default:
+			return fmt.Errorf("unknown smtp.tls %q (want starttls, tls or none)", c.SMTP.TLS)
+		}
+	case "outputs":
+		for i, out := range c.Outputs {
+			switch out.Type {
+			case OutputSlack, OutputDiscord, OutputTeams, OutputMattermost, OutputMarkdown, OutputJSON, OutputYAML:
+			case OutputEmail:
+				if err := c.ValidateFor("smtp"); err != nil {
+					return fmt.Errorf("outputs[%d]: %w", i, err)
+				}
+			case OutputJournal, OutputNtfy, OutputPushover, OutputTelegram:
+				if err := c.ValidateFor(out.Type); err != nil {
+					return fmt.Errorf("outputs[%d]: %w", i, err)
+				}
+			default:
+				return fmt.Errorf("outputs[%d].type must be one of slack, discord, teams, mattermost, email, journal, ntfy, pushover, telegram, markdown, json or yaml, got %q", i, out.Type)
+			}
+			if out.Path != "" && out.Type != OutputMarkdown && out.Type != OutputJSON && out.Type != OutputYAML {
+				return fmt.Errorf("outputs[%d].path is only supported for markdown, json and yaml", i)
+			}
+		}
+	case "journal":
+		if c.Journal.Dir == "" {
+			return fmt.Errorf("journal.dir is required for the journal output")
+		}
+	case "ntfy":
+		if c.Ntfy.Topic == "" {
+			return fmt.Errorf("ntfy.topic is required for the ntfy output")
+		}
+	case "pushover":
+		if c.Pushover.User == "" {
+			return fmt.Errorf("pushover.user is required for the pushover output")
+		}
+	case "telegram":
+		if c.Telegram.ChatID == "" {
+			return fmt.Errorf("telegram.chat_id is required for the telegram output")
+		}
 	case "review-projects":
 		if c.Todoist.KanbanBoardID == "" {
 			return fmt.Errorf("todoist.kanban_board_id is required for the review-projects capability")
 		}
+	case "capacity":
+		start, end := c.Capacity.Workday()
+		var bounds []time.Time
+		for _, field := range []struct{ name, value string }{
+			{"capacity.workday_start", start},
+			{"capacity.workday_end", end},
+		} {
+			t, err := time.Parse("15:04", field.value)
+			if err != nil {
+				return fmt.Errorf("%s must be in HH:MM format, got %q", field.name, field.value)
+			}
+			bounds = append(bounds, t)
+		}
+		if !bounds[1].After(bounds[0]) {
+			return fmt.Errorf("capacity.workday_end must be after capacity.workday_start")
+		}
+	case "email-triage":
+		for i, rule := range c.EmailTriage.Rules {
+			switch rule.Bucket {
+			case BucketNeedsReply, BucketWaiting, BucketFYI, BucketNewsletter, BucketNotification:
+			default:
+				return fmt.Errorf("email_triage.rules[%d].bucket must be one of needs_reply, waiting, fyi, newsletter or notification, got %q", i, rule.Bucket)
+			}
+			if rule.Recipient != "" && rule.Recipient != "to" && rule.Recipient != "cc" {
+				return fmt.Errorf("email_triage.rules[%d].recipient must be to or cc, got %q", i, rule.Recipient)
+			}
+		}
+		for i, action := range c.EmailTriage.Actions {
+			switch action.Bucket {
+			case BucketNeedsReply, BucketWaiting, BucketFYI, BucketNewsletter, BucketNotification:
+			default:
+				return fmt.Errorf("email_triage.actions[%d].bucket must be one of needs_reply, waiting, fyi, newsletter or notification, got %q", i, action.Bucket)
+			}
+			if !action.Archive && action.Label == "" && !action.MarkRead {
+				return fmt.Errorf("email_triage.actions[%d] must set archive, label or mark_read", i)
+			}
+		}
+		if len(c.EmailTriage.Actions) > 0 && c.Mail.BackendName() != MailBackendGmail {
+			return fmt.Errorf("email_triage.actions need the gmail mail backend")
+		}
+	case "email-tasks":
+		for i, rule := range c.EmailTasks.DueRules {
+			if rule.Due == "" {
+				return fmt.Errorf("email_tasks.due_rules[%d].due is required", i)
+			}
+		}
+		if c.EmailTasks.RemoveLabel && c.Mail.BackendName() != MailBackendGmail {
+			return fmt.Errorf("email_tasks.remove_label needs the gmail mail backend")
+		}
+	case "invitations":
+		for i, rule := range c.Invitations.Rules {
+			switch rule.Response {
+			case ResponseAccept, ResponseDecline, ResponseTentative:
+			default:
+				return fmt.Errorf("invitations.rules[%d].response must be one of accept, decline or tentative, got %q", i, rule.Response)
+			}
+		}
 	case "calendar-recommendations":
 		if len(c.Areas) == 0 {
 			return fmt.Errorf("areas is required for the calendar-recommendations capability")
@@ -161,3 +855,38 @@ func (c Config) ValidateFor(capability string) error {
 	}
 	return nil
 }
+
+// validateTaskBackend checks the settings a task backend needs. field names
+// the config key that selected the backend, for error messages.
+func (c Config) validateTaskBackend(field, backend string) error {
+	switch backend {
+	case TaskBackendTodoist:
+		return c.ValidateFor("todoist")
+	case TaskBackendFile:
+		if c.Tasks.File.Path == "" {
+			return fmt.Errorf("tasks.file.path is required for the file task backend")
+		}
+		if f := c.Tasks.File.Format; f != "" && f != "markdown" && f != "todotxt" {
+			return fmt.Errorf("tasks.file.format must be markdown or todotxt, got %q", f)
+		}
+	case TaskBackendGitHub:
+		if !strings.Contains(c.Tasks.GitHub.Repo, "/") {
+			return fmt.Errorf("tasks.github.repo must be in owner/name format")
+		}
+	case TaskBackendCalDAV:
+		if c.Tasks.CalDAV.URL == "" {
+			return fmt.Errorf("tasks.caldav.url is required for the caldav task backend")
+		}
+	case TaskBackendJira:
+		if c.Tasks.Jira.Site == "" || c.Tasks.Jira.ProjectKey == "" {
+			return fmt.Errorf("tasks.jira.site and tasks.jira.project_key are required for the jira task backend")
+		}
+	case TaskBackendLinear:
+		if c.Tasks.Linear.TeamID == "" {
+			return fmt.Errorf("tasks.linear.team_id is required for the linear task backend")
+		}
+	default:
+		return fmt.Errorf("unknown %s %q (want todoist, file, github, caldav, jira or linear)", field, backend)
+	}
+	return nil
+}
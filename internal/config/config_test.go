@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/sergekukharev/agent-samwise/internal/config"
@@ -136,3 +137,318 @@ func writeTestConfig(t *testing.T, content string) string {
 	}
 	return path
 }
+
+func TestValidateFor_Capacity_InvalidWorkday(t *testing.T) {
+	cfg := config.Config{
+		Capacity: config.CapacityConfig{WorkdayStart: "9am"},
+	}
+	if err := cfg.ValidateFor("capacity"); err == nil {
+		t.Fatal("expected validation error for malformed workday_start")
+	}
+}
+
+func TestValidateFor_Capacity_WorkdayEndsBeforeStart(t *testing.T) {
+	cfg := config.Config{
+		Capacity: config.CapacityConfig{WorkdayStart: "18:00", WorkdayEnd: "09:00"},
+	}
+	if err := cfg.ValidateFor("capacity"); err == nil {
+		t.Fatal("expected validation error for workday_end before workday_start")
+	}
+
+	// An end before the default start is rejected too.
+	cfg.Capacity = config.CapacityConfig{WorkdayEnd: "08:00"}
+	if err := cfg.ValidateFor("capacity"); err == nil {
+		t.Fatal("expected validation error for workday_end before the default start")
+	}
+
+	cfg.Capacity = config.CapacityConfig{WorkdayStart: "07:30"}
+	if err := cfg.ValidateFor("capacity"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCapacityConfig_Workday(t *testing.T) {
+	if start, end := (config.CapacityConfig{}).Workday(); start != "09:00" || end != "17:00" {
+		t.Errorf("Workday() = %s, %s, want the 09:00 to 17:00 default", start, end)
+	}
+	if start, end := (config.CapacityConfig{WorkdayStart: "07:30"}).Workday(); start != "07:30" || end != "17:00" {
+		t.Errorf("Workday() = %s, %s, want the configured start with the default end", start, end)
+	}
+}
+
+func TestValidateFor_EmailTriage_Rules(t *testing.T) {
+	cfg := config.Config{EmailTriage: config.EmailTriageConfig{Rules: []config.TriageRule{
+		{Bucket: "needs_reply", Recipient: "to"},
+	}}}
+	if err := cfg.ValidateFor("email-triage"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.EmailTriage.Rules[0].Bucket = "later"
+	if err := cfg.ValidateFor("email-triage"); err == nil {
+		t.Fatal("expected error for unknown bucket")
+	}
+
+	cfg.EmailTriage.Rules[0] = config.TriageRule{Bucket: "fyi", Recipient: "bcc"}
+	if err := cfg.ValidateFor("email-triage"); err == nil {
+		t.Fatal("expected error for unknown recipient field")
+	}
+}
+
+func TestValidateFor_EmailTriage_Actions(t *testing.T) {
+	cfg := config.Config{EmailTriage: config.EmailTriageConfig{Actions: []config.TriageAction{
+		{Bucket: "newsletter", OlderThanDays: 7, Archive: true},
+	}}}
+	if err := cfg.ValidateFor("email-triage"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.EmailTriage.Actions[0].Bucket = "promotions"
+	if err := cfg.ValidateFor("email-triage"); err == nil {
+		t.Fatal("expected error for unknown bucket")
+	}
+
+	cfg.EmailTriage.Actions[0] = config.TriageAction{Bucket: "fyi"}
+	if err := cfg.ValidateFor("email-triage"); err == nil {
+		t.Fatal("expected error for action that changes nothing")
+	}
+}
+
+func TestValidateFor_Invitations(t *testing.T) {
+	cfg := config.Config{Invitations: config.InvitationsConfig{Rules: []config.RSVPRule{
+		{Response: "tentative", Keywords: []string{"all hands"}},
+	}}}
+	if err := cfg.ValidateFor("invitations"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Invitations.Rules[0].Response = "maybe"
+	if err := cfg.ValidateFor("invitations"); err == nil {
+		t.Fatal("expected error for unknown response")
+	}
+}
+
+func TestValidateFor_SMTP(t *testing.T) {
+	cfg := config.Config{SMTP: config.SMTPConfig{Host: "smtp.example.com", From: "sam@example.com"}}
+	if err := cfg.ValidateFor("smtp"); err == nil {
+		t.Fatal("expected error without recipients")
+	}
+
+	cfg.SMTP.To = []string{"me@example.com"}
+	if err := cfg.ValidateFor("smtp"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.SMTP.TLS = "ssl"
+	if err := cfg.ValidateFor("smtp"); err == nil {
+		t.Fatal("expected error for unknown tls mode")
+	}
+}
+
+func TestValidateFor_Outputs(t *testing.T) {
+	cfg := config.Config{Outputs: []config.OutputConfig{{Type: "slack"}, {Type: "markdown", Path: "briefings.md"}}}
+	if err := cfg.ValidateFor("outputs"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Outputs = append(cfg.Outputs, config.OutputConfig{Type: "email"})
+	if err := cfg.ValidateFor("outputs"); err == nil {
+		t.Error("expected email output to need smtp config")
+	}
+
+	cfg.Outputs = []config.OutputConfig{{Type: "slack", Path: "slack.md"}}
+	if err := cfg.ValidateFor("outputs"); err == nil {
+		t.Error("expected error for path on slack output")
+	}
+
+	cfg.Outputs = []config.OutputConfig{{Type: "pager"}}
+	if err := cfg.ValidateFor("outputs"); err == nil {
+		t.Error("expected error for unknown output type")
+	}
+}
+
+func TestValidateFor_NotificationOutputs(t *testing.T) {
+	cfg := config.Config{Outputs: []config.OutputConfig{{Type: "ntfy"}, {Type: "pushover"}, {Type: "telegram"}}}
+	if err := cfg.ValidateFor("outputs"); err == nil || !strings.Contains(err.Error(), "outputs[0]: ntfy.topic is required") {
+		t.Errorf("error = %v, want the missing ntfy topic", err)
+	}
+
+	cfg.Ntfy.Topic = "sam"
+	if err := cfg.ValidateFor("outputs"); err == nil || !strings.Contains(err.Error(), "pushover.user") {
+		t.Errorf("error = %v, want the missing pushover user", err)
+	}
+
+	cfg.Pushover.User = "u123"
+	if err := cfg.ValidateFor("outputs"); err == nil || !strings.Contains(err.Error(), "telegram.chat_id") {
+		t.Errorf("error = %v, want the missing telegram chat", err)
+	}
+
+	cfg.Telegram.ChatID = "42"
+	if err := cfg.ValidateFor("outputs"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateFor_Tasks_DefaultsToTodoist(t *testing.T) {
+	cfg := config.Config{}
+	if err := cfg.ValidateFor("tasks"); err == nil {
+		t.Fatal("expected todoist.project_id to be required for the default backend")
+	}
+}
+
+func TestValidateFor_Tasks_FileBackend(t *testing.T) {
+	cfg := config.Config{Tasks: config.TasksConfig{Backend: "file"}}
+	if err := cfg.ValidateFor("tasks"); err == nil {
+		t.Fatal("expected error for missing tasks.file.path")
+	}
+
+	cfg.Tasks.File.Path = "tasks.md"
+	if err := cfg.ValidateFor("tasks"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateFor_Tasks_UnknownBackend(t *testing.T) {
+	cfg := config.Config{Tasks: config.TasksConfig{Backend: "trello"}}
+	if err := cfg.ValidateFor("tasks"); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestEnvFor_ExpandsTaskBackend(t *testing.T) {
+	tests := []struct {
+		backend string
+		want    []string
+	}{
+		{"", []string{"calendar", "todoist"}},
+		{"github", []string{"calendar", "github"}},
+		{"caldav", []string{"calendar", "caldav"}},
+		{"file", []string{"calendar"}},
+	}
+
+	for _, tt := range tests {
+		cfg := config.Config{Tasks: config.TasksConfig{Backend: tt.backend}}
+		got := cfg.EnvFor("calendar", "tasks")
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("backend %q: EnvFor = %v, want %v", tt.backend, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFor_CalendarTasks_RoutesToOwnBackend(t *testing.T) {
+	cfg := config.Config{Tasks: config.TasksConfig{
+		Backend:         "file",
+		CalendarBackend: "jira",
+		File:            config.FileTasksConfig{Path: "tasks.md"},
+	}}
+	if err := cfg.ValidateFor("tasks"); err != nil {
+		t.Errorf("personal backend: unexpected error: %v", err)
+	}
+	if err := cfg.ValidateFor("calendar-tasks"); err == nil {
+		t.Fatal("expected error for missing tasks.jira settings")
+	}
+
+	cfg.Tasks.Jira = config.JiraTasksConfig{Site: "https://example.atlassian.net", ProjectKey: "OPS"}
+	if err := cfg.ValidateFor("calendar-tasks"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateFor_Issues(t *testing.T) {
+	cfg := config.Config{Tasks: config.TasksConfig{Issues: []string{"linear", "jira"}}}
+	if err := cfg.ValidateFor("issues"); err == nil {
+		t.Fatal("expected error for missing tasks.jira.site")
+	}
+
+	cfg.Tasks.Jira.Site = "https://example.atlassian.net"
+	if err := cfg.ValidateFor("issues"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.Tasks.Issues = []string{"github"}
+	if err := cfg.ValidateFor("issues"); err == nil {
+		t.Fatal("expected error for unsupported issue source")
+	}
+}
+
+func TestEnvFor_CalendarTasksAndIssues(t *testing.T) {
+	cfg := config.Config{Tasks: config.TasksConfig{
+		CalendarBackend: "linear",
+		Issues:          []string{"jira"},
+	}}
+	got := cfg.EnvFor("calendar", "calendar-tasks", "tasks", "issues")
+	want := "calendar,linear,todoist,jira"
+	if strings.Join(got, ",") != want {
+		t.Errorf("EnvFor = %v, want %s", got, want)
+	}
+}
+
+func TestResolveSecrets_Jira(t *testing.T) {
+	t.Setenv("JIRA_EMAIL", "me@example.com")
+	t.Setenv("JIRA_API_TOKEN", "jira-token")
+
+	secrets, err := config.ResolveSecrets("jira")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secrets.JiraEmail != "me@example.com" || secrets.JiraAPIToken != "jira-token" {
+		t.Errorf("secrets = %+v, want jira credentials", secrets)
+	}
+}
+
+func TestResolveSecrets_CalDAV(t *testing.T) {
+	t.Setenv("CALDAV_USERNAME", "sam")
+	t.Setenv("CALDAV_PASSWORD", "")
+
+	_, err := config.ResolveSecrets("caldav")
+	if err == nil {
+		t.Fatal("expected error for missing CALDAV_PASSWORD")
+	}
+	if !strings.Contains(err.Error(), "CALDAV_PASSWORD") {
+		t.Errorf("error should mention CALDAV_PASSWORD, got: %v", err)
+	}
+}
+
+func TestValidateFor_Mail(t *testing.T) {
+	cfg := config.Config{}
+	if err := cfg.ValidateFor("mail"); err != nil {
+		t.Errorf("gmail backend should need no mail settings, got: %v", err)
+	}
+
+	cfg.Mail.Backend = "imap"
+	if err := cfg.ValidateFor("mail"); err == nil {
+		t.Fatal("expected error for missing mail.imap.host")
+	}
+
+	cfg.Mail.IMAP = config.IMAPConfig{Host: "imap.fastmail.com", Address: "me@example.com"}
+	if err := cfg.ValidateFor("mail"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.EmailTriage.Actions = []config.TriageAction{{Bucket: "newsletter", Archive: true}}
+	if err := cfg.ValidateFor("email-triage"); err == nil {
+		t.Error("expected error for triage actions on imap")
+	}
+
+	cfg.Mail.Backend = "pop3"
+	if err := cfg.ValidateFor("mail"); err == nil {
+		t.Error("expected error for unknown mail backend")
+	}
+}
+
+func TestEnvFor_Mail(t *testing.T) {
+	t.Setenv("IMAP_USERNAME", "me@example.com")
+	t.Setenv("IMAP_PASSWORD", "app-password")
+
+	cfg := config.Config{Mail: config.MailConfig{Backend: "imap"}}
+	secrets, err := config.ResolveSecrets(cfg.EnvFor("mail")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secrets.IMAPUsername != "me@example.com" || secrets.IMAPPassword != "app-password" {
+		t.Errorf("secrets = %+v, want imap credentials", secrets)
+	}
+	if got := (config.Config{}).EnvFor("mail"); strings.Join(got, ",") != "gmail" {
+		t.Errorf("EnvFor(mail) = %v, want gmail by default", got)
+	}
+}
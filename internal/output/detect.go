@@ -3,20 +3,170 @@ package output
 import (
 	"fmt"
 	"os"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
 )
 
 // DetectPresenter returns the appropriate Presenter based on the execution context.
-// In GitHub Actions, it requires SLACK_WEBHOOK_URL and returns a SlackPresenter.
+// An explicit format (json, yaml or markdown) prints to stdout, and "email"
+// sends through the configured SMTP server.
+// Otherwise, configured outputs are delivered to through a MultiPresenter.
+// In GitHub Actions, it returns a SlackBotPresenter when SLACK_BOT_TOKEN is set,
+// a SlackPresenter when SLACK_WEBHOOK_URL is set, then a Discord, Teams or
+// Mattermost presenter when their webhook URL is set, and otherwise an
+// SMTPPresenter when smtp.host is configured.
+// In GitHub Actions runs that provide GITHUB_STEP_SUMMARY or GITHUB_OUTPUT,
+// the chosen presenter is wrapped in a GitHubActionsPresenter.
 // Locally, it returns a TerminalPresenter.
-func DetectPresenter() (Presenter, error) {
+func DetectPresenter(format string, cfg config.Config) (Presenter, error) {
+	switch format {
+	case FormatJSON:
+		return NewJSONPresenter(), nil
+	case FormatYAML:
+		return NewYAMLPresenter(), nil
+	case FormatMarkdown:
+		return NewTerminalPresenter(), nil
+	case FormatEmail:
+		return newSMTPPresenter(cfg)
+	case "":
+	default:
+		return nil, fmt.Errorf("unknown output format %q (want json, yaml, markdown or email)", format)
+	}
+
+	if len(cfg.Outputs) > 0 {
+		return withGitHubActions(newMultiPresenter(cfg))
+	}
+
 	if os.Getenv("GITHUB_ACTIONS") != "true" {
 		return NewTerminalPresenter(), nil
 	}
 
-	webhookURL := os.Getenv("SLACK_WEBHOOK_URL")
-	if webhookURL == "" {
-		return nil, fmt.Errorf("running in GitHub Actions but SLACK_WEBHOOK_URL is not set")
+	slack, err := newSlackPresenter(cfg)
+	if err != nil || slack != nil {
+		return withGitHubActions(slack, err)
+	}
+
+	for _, w := range chatWebhooks {
+		if url := os.Getenv(w.env); url != "" {
+			return withGitHubActions(w.presenter(url), nil)
+		}
 	}
 
-	return NewSlackPresenter(webhookURL), nil
+	if cfg.SMTP.Host != "" {
+		return withGitHubActions(newSMTPPresenter(cfg))
+	}
+
+	return nil, fmt.Errorf("running in GitHub Actions but none of SLACK_BOT_TOKEN, SLACK_WEBHOOK_URL, DISCORD_WEBHOOK_URL, TEAMS_WEBHOOK_URL or MATTERMOST_WEBHOOK_URL is set, and smtp.host is not configured")
+}
+
+// chatWebhooks are the chat platforms other than Slack that briefings can
+// be posted to, in detection order, with the env var holding the webhook URL.
+var chatWebhooks = []struct {
+	output    string
+	env       string
+	presenter func(url string) Presenter
+}{
+	{config.OutputDiscord, "DISCORD_WEBHOOK_URL", func(url string) Presenter { return NewDiscordPresenter(url) }},
+	{config.OutputTeams, "TEAMS_WEBHOOK_URL", func(url string) Presenter { return NewTeamsPresenter(url) }},
+	{config.OutputMattermost, "MATTERMOST_WEBHOOK_URL", func(url string) Presenter { return NewMattermostPresenter(url) }},
+}
+
+// withGitHubActions wraps p to report to the current GitHub Actions run,
+// when there is one with a job summary or step outputs.
+func withGitHubActions(p Presenter, err error) (Presenter, error) {
+	if err != nil || os.Getenv("GITHUB_ACTIONS") != "true" {
+		return p, err
+	}
+	if os.Getenv("GITHUB_STEP_SUMMARY") == "" && os.Getenv("GITHUB_OUTPUT") == "" {
+		return p, nil
+	}
+	return NewGitHubActionsPresenter(p), nil
+}
+
+// newMultiPresenter creates a destination for each configured output.
+func newMultiPresenter(cfg config.Config) (Presenter, error) {
+	if err := cfg.ValidateFor("outputs"); err != nil {
+		return nil, err
+	}
+
+	multi := &MultiPresenter{}
+	for _, out := range cfg.Outputs {
+		var p Presenter
+		var err error
+		switch out.Type {
+		case config.OutputSlack:
+			if p, err = newSlackPresenter(cfg); err == nil && p == nil {
+				err = fmt.Errorf("neither SLACK_BOT_TOKEN nor SLACK_WEBHOOK_URL is set")
+			}
+		case config.OutputEmail:
+			p, err = newSMTPPresenter(cfg)
+		case config.OutputJournal:
+			p = NewJournalPresenter(cfg.Journal, cfg.Areas)
+		case config.OutputNtfy:
+			p = NewNtfyPresenter(cfg.Ntfy, os.Getenv("NTFY_TOKEN"))
+		case config.OutputPushover:
+			if token := os.Getenv("PUSHOVER_TOKEN"); token != "" {
+				p = NewPushoverPresenter(cfg.Pushover, token)
+			} else {
+				err = fmt.Errorf("PUSHOVER_TOKEN is not set")
+			}
+		case config.OutputTelegram:
+			if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+				p = NewTelegramPresenter(cfg.Telegram, token)
+			} else {
+				err = fmt.Errorf("TELEGRAM_BOT_TOKEN is not set")
+			}
+		case config.OutputDiscord, config.OutputTeams, config.OutputMattermost:
+			for _, w := range chatWebhooks {
+				if w.output != out.Type {
+					continue
+				}
+				if url := os.Getenv(w.env); url != "" {
+					p = w.presenter(url)
+				} else {
+					err = fmt.Errorf("%s is not set", w.env)
+				}
+			}
+		default:
+			if out.Path != "" {
+				p = &FilePresenter{Path: out.Path, Format: out.Type}
+			} else {
+				p, err = DetectPresenter(out.Type, cfg)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", out.DisplayName(), err)
+		}
+		multi.Destinations = append(multi.Destinations, Destination{
+			Name:         out.DisplayName(),
+			Capabilities: out.Capabilities,
+			Presenter:    p,
+		})
+	}
+	return multi, nil
+}
+
+// newSlackPresenter returns a SlackBotPresenter when SLACK_BOT_TOKEN is set,
+// a SlackPresenter when SLACK_WEBHOOK_URL is set, and nil otherwise.
+func newSlackPresenter(cfg config.Config) (Presenter, error) {
+	if token := os.Getenv("SLACK_BOT_TOKEN"); token != "" {
+		if cfg.Slack.Channel == "" && cfg.Slack.User == "" {
+			return nil, fmt.Errorf("SLACK_BOT_TOKEN is set but neither slack.channel nor slack.user is configured")
+		}
+		return NewSlackBotPresenter(token, cfg.Slack.Channel, cfg.Slack.User), nil
+	}
+
+	if webhookURL := os.Getenv("SLACK_WEBHOOK_URL"); webhookURL != "" {
+		return NewSlackPresenter(webhookURL), nil
+	}
+	return nil, nil
+}
+
+// newSMTPPresenter validates the smtp config and reads the credentials from
+// SMTP_USERNAME and SMTP_PASSWORD.
+func newSMTPPresenter(cfg config.Config) (Presenter, error) {
+	if err := cfg.ValidateFor("smtp"); err != nil {
+		return nil, err
+	}
+	return NewSMTPPresenter(cfg.SMTP, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
 }
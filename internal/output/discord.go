@@ -0,0 +1,219 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Discord webhook limits.
+const (
+	discordMaxContent     = 2000
+	discordMaxEmbeds      = 10
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxFields      = 25
+	discordMaxFieldName   = 256
+	discordMaxFieldValue  = 1024
+	// discordMaxEmbedTotal caps the characters of all embeds in a message.
+	discordMaxEmbedTotal = 6000
+)
+
+// DiscordPresenter delivers briefings via a Discord webhook, with each
+// section as an embed.
+type DiscordPresenter struct {
+	WebhookURL string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+	// Now returns the time of the briefing. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewDiscordPresenter creates a presenter that posts to the given webhook URL.
+func NewDiscordPresenter(webhookURL string) *DiscordPresenter {
+	return &DiscordPresenter{
+		WebhookURL: webhookURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type discordPayload struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Timestamp   string         `json:"timestamp,omitempty"`
+	Fields      []discordField `json:"fields,omitempty"`
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// size is the number of characters that count towards discordMaxEmbedTotal.
+func (e discordEmbed) size() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	for _, f := range e.Fields {
+		n += f.size()
+	}
+	return n
+}
+
+func (f discordField) size() int {
+	return utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+}
+
+// Present posts the briefing as one or more messages, in order.
+func (p *DiscordPresenter) Present(briefing Briefing) error {
+	for _, payload := range buildDiscordMessages(briefing, presentTime(p.Now)) {
+		if err := p.post(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildDiscordMessages renders the title as the message content and each
+// section as embeds, grouped into messages within Discord's embed limits.
+func buildDiscordMessages(briefing Briefing, now time.Time) []discordPayload {
+	var embeds []discordEmbed
+	for _, section := range briefing.Sections {
+		embeds = append(embeds, discordSectionEmbeds(section)...)
+	}
+	if len(embeds) > 0 {
+		embeds[0].Timestamp = now.UTC().Format(time.RFC3339)
+	}
+
+	messages := []discordPayload{{Content: truncateRunes("**"+briefing.Title+"**", discordMaxContent)}}
+	size := 0
+	for _, embed := range embeds {
+		last := &messages[len(messages)-1]
+		if len(last.Embeds) == discordMaxEmbeds || (len(last.Embeds) > 0 && size+embed.size() > discordMaxEmbedTotal) {
+			messages = append(messages, discordPayload{})
+			last, size = &messages[len(messages)-1], 0
+		}
+		last.Embeds = append(last.Embeds, embed)
+		size += embed.size()
+	}
+	return messages
+}
+
+// discordSectionEmbeds renders a section as an embed titled with its
+// heading, coloured by its most severe status, with facts as inline fields.
+// Long descriptions and many fields continue in further embeds.
+func discordSectionEmbeds(section Section) []discordEmbed {
+	var text []string
+	var fields []discordField
+	for _, item := range section.Items {
+		if facts, ok := item.(Facts); ok {
+			for _, f := range facts {
+				fields = append(fields, discordField{
+					Name:   truncateRunes(f.Label, discordMaxFieldName),
+					Value:  truncateRunes(f.Value, discordMaxFieldValue),
+					Inline: true,
+				})
+			}
+			continue
+		}
+		if t := discordItemText(item); t != "" {
+			text = append(text, t)
+		}
+	}
+
+	color := 0
+	if level := sectionLevel(section); level != "" {
+		c, _ := strconv.ParseInt(strings.TrimPrefix(statusColors[level], "#"), 16, 32)
+		color = int(c)
+	}
+
+	embeds := []discordEmbed{{Title: truncateRunes(section.Heading, discordMaxTitle), Color: color}}
+	for i, chunk := range splitSlackText(strings.Join(text, "\n\n"), discordMaxDescription) {
+		if i > 0 {
+			embeds = append(embeds, discordEmbed{Color: color})
+		}
+		embeds[len(embeds)-1].Description = chunk
+	}
+
+	for _, f := range fields {
+		last := &embeds[len(embeds)-1]
+		if len(last.Fields) == discordMaxFields || last.size()+f.size() > discordMaxEmbedTotal {
+			embeds = append(embeds, discordEmbed{Color: color})
+			last = &embeds[len(embeds)-1]
+		}
+		last.Fields = append(last.Fields, f)
+	}
+	return embeds
+}
+
+// discordItemText renders an item as Discord markdown.
+func discordItemText(item Item) string {
+	switch it := item.(type) {
+	case List:
+		var lines []string
+		if it.Title != "" {
+			lines = append(lines, it.Title)
+		}
+		bullet := "- "
+		if it.Checklist {
+			bullet = "- ☐ "
+		}
+		for _, entry := range it.Entries {
+			lines = append(lines, bullet+entry)
+		}
+		return strings.Join(lines, "\n")
+	case Table:
+		return "```\n" + tableText(it) + "\n```"
+	case TimeRange:
+		return fmt.Sprintf("**%s**: %s", it.Label, discordTimeRange(it))
+	}
+	return itemMarkdown(item)
+}
+
+// discordTimeRange renders timed ranges in each reader's own time zone.
+func discordTimeRange(r TimeRange) string {
+	if r.AllDay {
+		return formatTimeRange(r)
+	}
+	end := fmt.Sprintf("<t:%d:t>", r.End.Unix())
+	if from, to := r.Start.Local(), r.End.Local(); from.YearDay() != to.YearDay() || from.Year() != to.Year() {
+		end = fmt.Sprintf("<t:%d:f>", r.End.Unix())
+	}
+	return fmt.Sprintf("<t:%d:f>–%s", r.Start.Unix(), end)
+}
+
+func (p *DiscordPresenter) post(payload discordPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling discord payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating discord request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending discord message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Discord answers 204 No Content, or 200 with ?wait=true.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("discord webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the version of the machine-readable briefing document.
+// It changes only when fields are removed or change meaning; new fields may
+// be added within a version.
+const SchemaVersion = 1
+
+// Output formats selectable with --output.
+const (
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatMarkdown = "markdown"
+	// FormatEmail is not a document format: it sends the briefing by email.
+	FormatEmail = "email"
+)
+
+// document is the machine-readable form of a Briefing.
+type document struct {
+	SchemaVersion int               `json:"schema_version" yaml:"schema_version"`
+	Capability    string            `json:"capability,omitempty" yaml:"capability,omitempty"`
+	Title         string            `json:"title" yaml:"title"`
+	GeneratedAt   time.Time         `json:"generated_at" yaml:"generated_at"`
+	Sections      []documentSection `json:"sections" yaml:"sections"`
+	Results       any               `json:"results,omitempty" yaml:"results,omitempty"`
+}
+
+type documentSection struct {
+	Heading string         `json:"heading" yaml:"heading"`
+	Urgent  bool           `json:"urgent,omitempty" yaml:"urgent,omitempty"`
+	Items   []documentItem `json:"items" yaml:"items"`
+}
+
+// documentItem holds any kind of item, tagged by Type, with only the
+// fields of that kind set.
+type documentItem struct {
+	Type      string         `json:"type" yaml:"type"`
+	Title     string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Text      string         `json:"text,omitempty" yaml:"text,omitempty"`
+	Entries   []string       `json:"entries,omitempty" yaml:"entries,omitempty"`
+	Checklist bool           `json:"checklist,omitempty" yaml:"checklist,omitempty"`
+	Facts     []documentFact `json:"facts,omitempty" yaml:"facts,omitempty"`
+	Columns   []string       `json:"columns,omitempty" yaml:"columns,omitempty"`
+	Rows      [][]string     `json:"rows,omitempty" yaml:"rows,omitempty"`
+	URL       string         `json:"url,omitempty" yaml:"url,omitempty"`
+	Level     Level          `json:"level,omitempty" yaml:"level,omitempty"`
+	Label     string         `json:"label,omitempty" yaml:"label,omitempty"`
+	Start     *time.Time     `json:"start,omitempty" yaml:"start,omitempty"`
+	End       *time.Time     `json:"end,omitempty" yaml:"end,omitempty"`
+	AllDay    bool           `json:"all_day,omitempty" yaml:"all_day,omitempty"`
+}
+
+type documentFact struct {
+	Label string `json:"label" yaml:"label"`
+	Value string `json:"value" yaml:"value"`
+}
+
+func newDocument(briefing Briefing, now time.Time) document {
+	doc := document{
+		SchemaVersion: SchemaVersion,
+		Capability:    briefing.Capability,
+		Title:         briefing.Title,
+		GeneratedAt:   now.UTC().Truncate(time.Second),
+		Sections:      []documentSection{},
+		Results:       briefing.Results,
+	}
+	for _, section := range briefing.Sections {
+		s := documentSection{Heading: section.Heading, Urgent: section.Urgent, Items: []documentItem{}}
+		for _, item := range section.Items {
+			s.Items = append(s.Items, newDocumentItem(item))
+		}
+		doc.Sections = append(doc.Sections, s)
+	}
+	return doc
+}
+
+func newDocumentItem(item Item) documentItem {
+	switch it := item.(type) {
+	case Paragraph:
+		return documentItem{Type: "paragraph", Text: string(it)}
+	case List:
+		return documentItem{Type: "list", Title: it.Title, Entries: it.Entries, Checklist: it.Checklist}
+	case Facts:
+		d := documentItem{Type: "facts"}
+		for _, f := range it {
+			d.Facts = append(d.Facts, documentFact{Label: f.Label, Value: f.Value})
+		}
+		return d
+	case Table:
+		return documentItem{Type: "table", Columns: it.Columns, Rows: it.Rows}
+	case Link:
+		return documentItem{Type: "link", Text: it.Text, URL: it.URL}
+	case Status:
+		return documentItem{Type: "status", Level: it.Level, Text: it.Text}
+	case TimeRange:
+		return documentItem{Type: "time_range", Label: it.Label, Start: &it.Start, End: &it.End, AllDay: it.AllDay}
+	}
+	return documentItem{Type: fmt.Sprintf("%T", item)}
+}
+
+// JSONPresenter prints briefings as JSON documents, for scripts and
+// dashboards.
+type JSONPresenter struct {
+	Writer io.Writer
+	// Now returns the generation time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewJSONPresenter creates a presenter that writes to stdout.
+func NewJSONPresenter() *JSONPresenter {
+	return &JSONPresenter{Writer: os.Stdout}
+}
+
+func (p *JSONPresenter) Present(briefing Briefing) error {
+	enc := json.NewEncoder(p.Writer)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(newDocument(briefing, presentTime(p.Now))); err != nil {
+		return fmt.Errorf("encoding briefing as json: %w", err)
+	}
+	return nil
+}
+
+// YAMLPresenter prints briefings as YAML documents, with the same schema
+// as JSONPresenter.
+type YAMLPresenter struct {
+	Writer io.Writer
+	// Now returns the generation time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewYAMLPresenter creates a presenter that writes to stdout.
+func NewYAMLPresenter() *YAMLPresenter {
+	return &YAMLPresenter{Writer: os.Stdout}
+}
+
+func (p *YAMLPresenter) Present(briefing Briefing) error {
+	enc := yaml.NewEncoder(p.Writer)
+	enc.SetIndent(2)
+	if err := enc.Encode(newDocument(briefing, presentTime(p.Now))); err != nil {
+		return fmt.Errorf("encoding briefing as yaml: %w", err)
+	}
+	return enc.Close()
+}
+
+func presentTime(now func() time.Time) time.Time {
+	if now != nil {
+		return now()
+	}
+	return time.Now()
+}
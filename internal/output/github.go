@@ -0,0 +1,204 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// annotationCommands are the workflow commands that annotate statuses by
+// level. Info and OK statuses are not annotated.
+var annotationCommands = map[Level]string{
+	LevelWarning: "warning",
+	LevelError:   "error",
+}
+
+// GitHubActionsPresenter reports briefings to the GitHub Actions run that
+// delivers them: it writes each briefing to the job summary, annotates
+// warnings, errors and failed deliveries, and sets step outputs from the
+// capability's results. Delivery itself is left to Next, such as Slack.
+// Step outputs are not prefixed, so each capability should run in its own
+// workflow step; a later run in the same step overwrites them.
+type GitHubActionsPresenter struct {
+	Next Presenter
+	// SummaryPath is the job summary file, from GITHUB_STEP_SUMMARY.
+	SummaryPath string
+	// OutputPath is the step outputs file, from GITHUB_OUTPUT.
+	OutputPath string
+	// Commands receives workflow commands such as annotations. Defaults to
+	// stdout.
+	Commands io.Writer
+
+	// delivery is the error Next last returned, already annotated.
+	delivery error
+}
+
+// NewGitHubActionsPresenter creates a presenter that reports to the current
+// run and delivers through next.
+func NewGitHubActionsPresenter(next Presenter) *GitHubActionsPresenter {
+	return &GitHubActionsPresenter{
+		Next:        next,
+		SummaryPath: os.Getenv("GITHUB_STEP_SUMMARY"),
+		OutputPath:  os.Getenv("GITHUB_OUTPUT"),
+		Commands:    os.Stdout,
+	}
+}
+
+// Present delivers the briefing through Next, then reports it to the run.
+// Reporting problems are annotated rather than returned, so they never
+// fail a run whose briefing was delivered.
+func (p *GitHubActionsPresenter) Present(briefing Briefing) error {
+	err := p.Next.Present(briefing)
+	p.delivery = err
+
+	summary := briefingMarkdown(briefing)
+	if err != nil {
+		summary += "\n" + deliverySummary(err)
+	}
+	if werr := appendFile(p.SummaryPath, summary+"\n"); werr != nil {
+		p.annotate(LevelWarning, "Job summary", werr.Error())
+	}
+
+	outputs := [][2]string{{"title", briefing.Title}, {"capability", briefing.Capability}}
+	outputs = append(outputs, resultOutputs(briefing.Results)...)
+	var lines strings.Builder
+	for _, o := range outputs {
+		fmt.Fprintf(&lines, "%s=%s\n", o[0], strings.NewReplacer("\r", " ", "\n", " ").Replace(o[1]))
+	}
+	if werr := appendFile(p.OutputPath, lines.String()); werr != nil {
+		p.annotate(LevelWarning, "Step outputs", werr.Error())
+	}
+
+	for _, section := range briefing.Sections {
+		for _, item := range section.Items {
+			if s, ok := item.(Status); ok {
+				p.annotate(s.Level, section.Heading, s.Text)
+			}
+		}
+	}
+
+	var delivery *DeliveryError
+	switch {
+	case errors.As(err, &delivery):
+		// Partial deliveries are warnings; nothing delivered is an error.
+		level := LevelError
+		if delivery.Partial() {
+			level = LevelWarning
+		}
+		for _, f := range delivery.Failed {
+			p.annotate(level, "Delivery to "+f.Name+" failed", f.Err.Error())
+		}
+	case err != nil:
+		p.annotate(LevelError, "Delivery failed", err.Error())
+	}
+	return err
+}
+
+// Fail annotates an error that ended the run, unless Present already
+// annotated it as a failed delivery.
+func (p *GitHubActionsPresenter) Fail(err error) {
+	if p.delivery != nil && errors.Is(err, p.delivery) {
+		return
+	}
+	p.annotate(LevelError, "Sam failed", err.Error())
+	if werr := appendFile(p.SummaryPath, fmt.Sprintf("> %s **Sam failed:** %s\n", statusEmoji[LevelError], err)); werr != nil {
+		p.annotate(LevelWarning, "Job summary", werr.Error())
+	}
+}
+
+// annotate emits a ::warning:: or ::error:: workflow command.
+func (p *GitHubActionsPresenter) annotate(level Level, title, message string) {
+	command, ok := annotationCommands[level]
+	if !ok {
+		return
+	}
+	w := p.Commands
+	if w == nil {
+		w = os.Stdout
+	}
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	data := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	fmt.Fprintf(w, "::%s title=%s::%s\n", command, property.Replace(title), data.Replace(message))
+}
+
+// deliverySummary lists failed deliveries for the job summary.
+func deliverySummary(err error) string {
+	var delivery *DeliveryError
+	if !errors.As(err, &delivery) {
+		return fmt.Sprintf("> %s **Delivery failed:** %s\n", statusEmoji[LevelError], err)
+	}
+	var b strings.Builder
+	for _, f := range delivery.Failed {
+		fmt.Fprintf(&b, "> %s **Delivery to %s failed:** %s\n", statusEmoji[LevelError], f.Name, f.Err)
+	}
+	return b.String()
+}
+
+// resultOutputs flattens capability results into step outputs: numbers,
+// strings and booleans as they are, and lists as <name>_count. A list of
+// results becomes count.
+func resultOutputs(results any) [][2]string {
+	v := reflect.ValueOf(results)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return [][2]string{{"count", strconv.Itoa(v.Len())}}
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	var outputs [][2]string
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fv := v.Field(i)
+		switch fv.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			outputs = append(outputs, [2]string{name + "_count", strconv.Itoa(fv.Len())})
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			outputs = append(outputs, [2]string{name, fmt.Sprint(fv.Interface())})
+		}
+	}
+	return outputs
+}
+
+// appendFile appends text to the file at path, doing nothing when path is
+// empty.
+func appendFile(path, text string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	if _, err := io.WriteString(f, text); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
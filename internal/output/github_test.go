@@ -0,0 +1,144 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+type syncResults struct {
+	Created []string `json:"created,omitempty"`
+	Skipped []string `json:"skipped,omitempty"`
+	Minutes int      `json:"planned_minutes"`
+}
+
+func newActionsPresenter(t *testing.T, next output.Presenter) (*output.GitHubActionsPresenter, *bytes.Buffer) {
+	t.Helper()
+	dir := t.TempDir()
+	var commands bytes.Buffer
+	return &output.GitHubActionsPresenter{
+		Next:        next,
+		SummaryPath: filepath.Join(dir, "summary.md"),
+		OutputPath:  filepath.Join(dir, "output"),
+		Commands:    &commands,
+	}, &commands
+}
+
+func TestGitHubActionsPresenter_SummaryOutputsAndAnnotations(t *testing.T) {
+	var delivered []output.Briefing
+	next := presenterFunc(func(b output.Briefing) error {
+		delivered = append(delivered, b)
+		return nil
+	})
+	p, commands := newActionsPresenter(t, next)
+
+	briefing := output.Briefing{
+		Title:      "Calendar Sync",
+		Capability: "calendar-sync",
+		Sections: []output.Section{{Heading: "Sync, today", Items: []output.Item{
+			output.Status{Level: output.LevelOK, Text: "2 tasks created"},
+			output.Status{Level: output.LevelWarning, Text: "1 event skipped: 100% booked"},
+		}}},
+		Results: syncResults{Created: []string{"1", "2"}, Minutes: 90},
+	}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(delivered) != 1 {
+		t.Errorf("delivered %d briefings, want Next to receive the briefing", len(delivered))
+	}
+
+	summary, _ := os.ReadFile(p.SummaryPath)
+	if !strings.HasPrefix(string(summary), "# Calendar Sync\n\n## Sync, today\n\n✅ 2 tasks created") {
+		t.Errorf("summary = %q, want the briefing as markdown", summary)
+	}
+
+	outputs, _ := os.ReadFile(p.OutputPath)
+	want := "title=Calendar Sync\ncapability=calendar-sync\ncreated_count=2\nskipped_count=0\nplanned_minutes=90\n"
+	if string(outputs) != want {
+		t.Errorf("outputs = %q, want %q", outputs, want)
+	}
+
+	if got := commands.String(); got != "::warning title=Sync%2C today::1 event skipped: 100%25 booked\n" {
+		t.Errorf("commands = %q, want a warning annotation", got)
+	}
+}
+
+func TestGitHubActionsPresenter_PartialDelivery(t *testing.T) {
+	failed := &output.DeliveryError{
+		Failed:    []output.DestinationError{{Name: "email", Err: errors.New("connection refused")}},
+		Delivered: 1,
+	}
+	p, commands := newActionsPresenter(t, presenterFunc(func(output.Briefing) error { return failed }))
+
+	err := p.Present(testBriefing)
+	if !errors.Is(err, failed) {
+		t.Fatalf("error = %v, want the delivery error", err)
+	}
+	if got := commands.String(); got != "::warning title=Delivery to email failed::connection refused\n" {
+		t.Errorf("commands = %q, want a warning for the partial delivery", got)
+	}
+	summary, _ := os.ReadFile(p.SummaryPath)
+	if !strings.Contains(string(summary), "> ❌ **Delivery to email failed:** connection refused\n") {
+		t.Errorf("summary = %q, want the failed delivery", summary)
+	}
+
+	// The router reports the same error when the capability returns it.
+	commands.Reset()
+	p.Fail(err)
+	if commands.Len() != 0 {
+		t.Errorf("commands = %q, want the delivery failure annotated once", commands)
+	}
+
+	p.Fail(errors.New("fetching calendar events: 401\nunauthorized"))
+	if got := commands.String(); got != "::error title=Sam failed::fetching calendar events: 401%0Aunauthorized\n" {
+		t.Errorf("commands = %q, want an error annotation", got)
+	}
+}
+
+func TestGitHubActionsPresenter_DeliveryFailure(t *testing.T) {
+	p, commands := newActionsPresenter(t, presenterFunc(func(output.Briefing) error {
+		return errors.New("slack webhook returned status 500")
+	}))
+
+	if err := p.Present(testBriefing); err == nil {
+		t.Fatal("expected the delivery error to be returned")
+	}
+	if got := commands.String(); got != "::error title=Delivery failed::slack webhook returned status 500\n" {
+		t.Errorf("commands = %q, want an error annotation", got)
+	}
+}
+
+func TestDetectPresenter_GitHubActionsSummary(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/test")
+	t.Setenv("GITHUB_STEP_SUMMARY", filepath.Join(t.TempDir(), "summary.md"))
+	t.Setenv("GITHUB_OUTPUT", "")
+
+	p, err := output.DetectPresenter("", config.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actions, ok := p.(*output.GitHubActionsPresenter)
+	if !ok {
+		t.Fatalf("expected GitHubActionsPresenter, got %T", p)
+	}
+	if _, ok := actions.Next.(*output.SlackPresenter); !ok {
+		t.Errorf("expected delivery through Slack, got %T", actions.Next)
+	}
+
+	// Explicit formats print only.
+	if p, _ := output.DetectPresenter("json", config.Config{}); p == nil {
+		t.Error("expected a presenter for --output json")
+	} else if _, ok := p.(*output.JSONPresenter); !ok {
+		t.Errorf("expected JSONPresenter for --output json, got %T", p)
+	}
+}
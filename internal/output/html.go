@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// statusColors are the text colours of statuses in HTML.
+var statusColors = map[Level]string{
+	LevelInfo:    "#0969da",
+	LevelOK:      "#1a7f37",
+	LevelWarning: "#9a6700",
+	LevelError:   "#cf222e",
+}
+
+// Inline styles, since many mail clients ignore style sheets.
+const (
+	htmlBodyStyle    = "font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 15px; line-height: 1.5; color: #1f2328; max-width: 640px; margin: 0 auto; padding: 16px;"
+	htmlHeadingStyle = "font-size: 18px; margin: 24px 0 8px; padding-bottom: 4px; border-bottom: 1px solid #d0d7de;"
+	htmlTableStyle   = "border-collapse: collapse; margin: 8px 0;"
+	htmlCellStyle    = "border: 1px solid #d0d7de; padding: 4px 8px; text-align: left;"
+	htmlButtonStyle  = "display: inline-block; padding: 6px 12px; border-radius: 6px; background: #0969da; color: #ffffff; text-decoration: none;"
+)
+
+// briefingHTML renders a briefing as a standalone HTML document for email.
+func briefingHTML(briefing Briefing) string {
+	var b strings.Builder
+	title := html.EscapeString(briefing.Title)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head><meta charset=\"utf-8\"><title>%s</title></head>\n", title)
+	fmt.Fprintf(&b, "<body style=\"%s\">\n<h1 style=\"font-size: 22px;\">%s</h1>\n", htmlBodyStyle, title)
+	for _, section := range briefing.Sections {
+		fmt.Fprintf(&b, "<h2 style=\"%s\">%s</h2>\n", htmlHeadingStyle, html.EscapeString(section.Heading))
+		for _, item := range section.Items {
+			b.WriteString(itemHTML(item))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+func itemHTML(item Item) string {
+	switch it := item.(type) {
+	case Paragraph:
+		var lines []string
+		for _, line := range strings.Split(string(it), "\n") {
+			lines = append(lines, inlineHTML(line))
+		}
+		return "<p>" + strings.Join(lines, "<br>\n") + "</p>"
+	case List:
+		var b strings.Builder
+		if it.Title != "" {
+			fmt.Fprintf(&b, "<p style=\"margin-bottom: 0;\"><strong>%s</strong></p>\n", inlineHTML(it.Title))
+		}
+		b.WriteString("<ul>\n")
+		for _, entry := range it.Entries {
+			if it.Checklist {
+				entry = "☐ " + entry
+			}
+			fmt.Fprintf(&b, "<li>%s</li>\n", inlineHTML(entry))
+		}
+		b.WriteString("</ul>")
+		return b.String()
+	case Facts:
+		var b strings.Builder
+		fmt.Fprintf(&b, "<table style=\"%s\">\n", htmlTableStyle)
+		for _, f := range it {
+			fmt.Fprintf(&b, "<tr><th style=\"padding: 2px 12px 2px 0; text-align: left;\">%s</th><td>%s</td></tr>\n", html.EscapeString(f.Label), inlineHTML(f.Value))
+		}
+		b.WriteString("</table>")
+		return b.String()
+	case Table:
+		var b strings.Builder
+		fmt.Fprintf(&b, "<table style=\"%s\">\n<tr>", htmlTableStyle)
+		for _, c := range it.Columns {
+			fmt.Fprintf(&b, "<th style=\"%s\">%s</th>", htmlCellStyle, html.EscapeString(c))
+		}
+		b.WriteString("</tr>\n")
+		for _, row := range it.Rows {
+			b.WriteString("<tr>")
+			for _, cell := range row {
+				fmt.Fprintf(&b, "<td style=\"%s\">%s</td>", htmlCellStyle, html.EscapeString(cell))
+			}
+			b.WriteString("</tr>\n")
+		}
+		b.WriteString("</table>")
+		return b.String()
+	case Link:
+		return fmt.Sprintf("<p><a href=\"%s\" style=\"%s\">%s</a></p>", html.EscapeString(it.URL), htmlButtonStyle, html.EscapeString(it.Text))
+	case Status:
+		return fmt.Sprintf("<p style=\"color: %s; font-weight: 600;\">%s %s</p>", statusColors[it.Level], statusEmoji[it.Level], inlineHTML(it.Text))
+	case TimeRange:
+		return fmt.Sprintf("<p><strong>%s</strong>: %s</p>", html.EscapeString(it.Label), html.EscapeString(formatTimeRange(it)))
+	}
+	return ""
+}
+
+// inlineHTML converts inline markdown to HTML: links, bold, italics,
+// strikethrough and code spans.
+func inlineHTML(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range mdInlineCode.FindAllStringIndex(line, -1) {
+		b.WriteString(inlineHTMLText(line[last:span[0]]))
+		fmt.Fprintf(&b, "<code>%s</code>", html.EscapeString(line[span[0]+1:span[1]-1]))
+		last = span[1]
+	}
+	b.WriteString(inlineHTMLText(line[last:]))
+	return b.String()
+}
+
+func inlineHTMLText(text string) string {
+	// Links are replaced by placeholders so their URLs are not styled.
+	var links []string
+	text = mdLink.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		links = append(links, fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(parts[2]), html.EscapeString(parts[1])))
+		return fmt.Sprintf("\x00%d\x00", len(links)-1)
+	})
+
+	text = html.EscapeString(text)
+	text = mdItalic.ReplaceAllString(text, "${1}<em>${2}</em>")
+	text = mdBold.ReplaceAllString(text, "<strong>${1}${2}</strong>")
+	text = mdStrike.ReplaceAllString(text, "<del>${1}</del>")
+
+	for i, link := range links {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), link, 1)
+	}
+	return text
+}
@@ -0,0 +1,236 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Item is a piece of section content. Presenters render each kind of item
+// in their native format.
+type Item interface {
+	item()
+}
+
+// Paragraph is free text, with inline markdown for emphasis and links.
+type Paragraph string
+
+// List is a bullet list with an optional title. Entries may contain inline
+// markdown.
+type List struct {
+	Title   string
+	Entries []string
+	// Checklist renders the entries as unticked checkboxes.
+	Checklist bool
+}
+
+// Facts are labelled values, such as "Planned: 3h".
+type Facts []Fact
+
+// Fact is a label and its value.
+type Fact struct {
+	Label string
+	Value string
+}
+
+// Table is rows of plain-text cells under column headings.
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Link is a standalone link, rendered as a button where supported.
+type Link struct {
+	Text string
+	URL  string
+}
+
+// Level is the severity of a Status.
+type Level string
+
+const (
+	LevelInfo    Level = "info"
+	LevelOK      Level = "ok"
+	LevelWarning Level = "warning"
+	LevelError   Level = "error"
+)
+
+// Status is a short outcome or health badge, such as "Over-committed by 1h".
+type Status struct {
+	Level Level
+	Text  string
+}
+
+// TimeRange is a labelled span of time, such as working hours or a meeting.
+type TimeRange struct {
+	Label string
+	Start time.Time
+	End   time.Time
+	// AllDay ranges are shown as dates; End is exclusive.
+	AllDay bool
+}
+
+func (Paragraph) item() {}
+func (List) item()      {}
+func (Facts) item()     {}
+func (Table) item()     {}
+func (Link) item()      {}
+func (Status) item()    {}
+func (TimeRange) item() {}
+
+// statusEmoji marks a Status by level in text-based formats.
+var statusEmoji = map[Level]string{
+	LevelInfo:    "ℹ️",
+	LevelOK:      "✅",
+	LevelWarning: "⚠️",
+	LevelError:   "❌",
+}
+
+// levelSeverity orders levels from least to most severe.
+var levelSeverity = map[Level]int{
+	LevelInfo:    1,
+	LevelOK:      2,
+	LevelWarning: 3,
+	LevelError:   4,
+}
+
+// sectionLevel returns the most severe level among a section's statuses,
+// or "" when it has none. Presenters with coloured cards use it as the
+// section's colour.
+func sectionLevel(section Section) Level {
+	var level Level
+	for _, item := range section.Items {
+		if s, ok := item.(Status); ok && levelSeverity[s.Level] > levelSeverity[level] {
+			level = s.Level
+		}
+	}
+	return level
+}
+
+// briefingMarkdown renders a briefing as a markdown document with "#" for
+// the title and "##" for section headings.
+func briefingMarkdown(briefing Briefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", briefing.Title)
+	for i, section := range briefing.Sections {
+		fmt.Fprintf(&b, "## %s\n\n", section.Heading)
+		fmt.Fprintf(&b, "%s\n", sectionMarkdown(section))
+		if i < len(briefing.Sections)-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// sectionMarkdown renders a section's items as markdown, separated by
+// blank lines.
+func sectionMarkdown(section Section) string {
+	var parts []string
+	for _, item := range section.Items {
+		if md := itemMarkdown(item); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func itemMarkdown(item Item) string {
+	switch it := item.(type) {
+	case Paragraph:
+		return string(it)
+	case List:
+		var lines []string
+		if it.Title != "" {
+			lines = append(lines, it.Title)
+		}
+		bullet := "- "
+		if it.Checklist {
+			bullet = "- [ ] "
+		}
+		for _, entry := range it.Entries {
+			lines = append(lines, bullet+entry)
+		}
+		return strings.Join(lines, "\n")
+	case Facts:
+		var lines []string
+		for _, f := range it {
+			lines = append(lines, fmt.Sprintf("%s: %s", f.Label, f.Value))
+		}
+		return strings.Join(lines, "\n")
+	case Table:
+		return tableMarkdown(it)
+	case Link:
+		return fmt.Sprintf("[%s](%s)", it.Text, it.URL)
+	case Status:
+		return statusEmoji[it.Level] + " " + it.Text
+	case TimeRange:
+		return it.Label + ": " + formatTimeRange(it)
+	}
+	return ""
+}
+
+func tableMarkdown(t Table) string {
+	if len(t.Columns) == 0 {
+		return ""
+	}
+	escape := strings.NewReplacer("|", `\|`, "\n", " ")
+	row := func(cells []string) string {
+		escaped := make([]string, len(t.Columns))
+		for i := range escaped {
+			if i < len(cells) {
+				escaped[i] = escape.Replace(cells[i])
+			}
+		}
+		return "| " + strings.Join(escaped, " | ") + " |"
+	}
+
+	lines := []string{row(t.Columns), "|" + strings.Repeat(" --- |", len(t.Columns))}
+	for _, r := range t.Rows {
+		lines = append(lines, row(r))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableText renders a table as aligned columns of plain text.
+func tableText(t Table) string {
+	widths := make([]int, len(t.Columns))
+	rows := append([][]string{t.Columns}, t.Rows...)
+	for _, r := range rows {
+		for i := range widths {
+			if i < len(r) {
+				widths[i] = max(widths[i], len([]rune(r[i])))
+			}
+		}
+	}
+
+	var lines []string
+	for _, r := range rows {
+		var cells []string
+		for i, w := range widths {
+			cell := ""
+			if i < len(r) {
+				cell = r[i]
+			}
+			cells = append(cells, cell+strings.Repeat(" ", w-len([]rune(cell))))
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatTimeRange renders "Thu 12 Feb 14:00–15:00", or "Thu 12 Feb –
+// Fri 13 Feb" for all-day ranges, in local time.
+func formatTimeRange(r TimeRange) string {
+	if r.AllDay {
+		last := r.End.AddDate(0, 0, -1)
+		if !last.After(r.Start) {
+			return r.Start.Format("Mon 2 Jan")
+		}
+		return r.Start.Format("Mon 2 Jan") + " – " + last.Format("Mon 2 Jan")
+	}
+	start, end := r.Start.Local(), r.End.Local()
+	if start.Year() == end.Year() && start.YearDay() == end.YearDay() {
+		return start.Format("Mon 2 Jan 15:04") + "–" + end.Format("15:04")
+	}
+	return start.Format("Mon 2 Jan 15:04") + " – " + end.Format("Mon 2 Jan 15:04")
+}
@@ -0,0 +1,313 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+)
+
+// Markers around each capability's block in a journal file. A re-run
+// replaces the block between them and leaves the rest of the file alone.
+const (
+	journalBeginMarker = "<!-- sam:begin %s -->"
+	journalEndMarker   = "<!-- sam:end %s -->"
+)
+
+var (
+	journalBlock = regexp.MustCompile(`<!-- sam:begin (\S+) -->`)
+	// journalSkip matches text that must not get wiki-links: code spans,
+	// links, wiki-links and bare URLs.
+	journalSkip = regexp.MustCompile("`[^`]*`|\\[\\[[^\\]]*\\]\\]|\\[[^\\]]*\\]\\([^)]*\\)|https?://\\S+")
+)
+
+// JournalPresenter keeps briefings in dated Markdown files of a vault, such
+// as Obsidian or Logseq daily notes. Each capability gets a block in the
+// day's file, replaced when it runs again that day, and mentions of areas
+// become wiki-links.
+type JournalPresenter struct {
+	Dir string
+	// FileFormat is the time layout of file names. Defaults to "2006-01-02".
+	FileFormat string
+	// Tags are set in the frontmatter of new files.
+	Tags  []string
+	Areas []config.Area
+	// Now returns the time of the briefing. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewJournalPresenter creates a presenter that writes to the configured
+// vault directory, linking the given areas.
+func NewJournalPresenter(cfg config.JournalConfig, areas []config.Area) *JournalPresenter {
+	tags := cfg.Tags
+	if len(tags) == 0 {
+		tags = []string{"sam"}
+	}
+	return &JournalPresenter{Dir: cfg.Dir, FileFormat: cfg.FileFormat, Tags: tags, Areas: areas}
+}
+
+// Present writes the briefing's block into the day's file, creating it
+// with frontmatter when needed.
+func (p *JournalPresenter) Present(briefing Briefing) error {
+	now := presentTime(p.Now)
+	layout := p.FileFormat
+	if layout == "" {
+		layout = time.DateOnly
+	}
+	path := filepath.Join(p.Dir, now.Format(layout)+".md")
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading journal file: %w", err)
+	}
+	frontmatter, body := splitFrontmatter(string(existing))
+
+	id := journalBlockID(briefing)
+	block := fmt.Sprintf(journalBeginMarker, id) + "\n" + p.linkAreas(journalMarkdown(briefing)) + fmt.Sprintf(journalEndMarker, id) + "\n"
+	if body, err = replaceJournalBlock(body, id, block); err != nil {
+		return err
+	}
+
+	frontmatter, err = p.updateFrontmatter(frontmatter, body, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating journal directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte("---\n"+frontmatter+"---\n\n"+body), 0644); err != nil {
+		return fmt.Errorf("writing journal file: %w", err)
+	}
+	return nil
+}
+
+// journalBlockID names a briefing's block after its capability, or its
+// title when it has none.
+func journalBlockID(briefing Briefing) string {
+	if briefing.Capability != "" {
+		return briefing.Capability
+	}
+	return strings.Join(strings.Fields(strings.ToLower(briefing.Title)), "-")
+}
+
+// journalMarkdown renders a briefing one heading level down from
+// briefingMarkdown, so it nests under the note's own title.
+func journalMarkdown(briefing Briefing) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n", briefing.Title)
+	for _, section := range briefing.Sections {
+		fmt.Fprintf(&b, "\n### %s\n\n", section.Heading)
+		if md := sectionMarkdown(section); md != "" {
+			fmt.Fprintf(&b, "%s\n", md)
+		}
+	}
+	return b.String()
+}
+
+// splitFrontmatter separates a leading YAML frontmatter from the rest of a
+// note, with the body's leading blank lines trimmed.
+func splitFrontmatter(note string) (frontmatter, body string) {
+	if rest, ok := strings.CutPrefix(note, "---\n"); ok {
+		if i := strings.Index(rest, "\n---\n"); i >= 0 {
+			return rest[:i+1], strings.TrimLeft(rest[i+5:], "\n")
+		} else if strings.HasPrefix(rest, "---\n") {
+			return "", strings.TrimLeft(rest[4:], "\n")
+		}
+	}
+	return "", note
+}
+
+// replaceJournalBlock replaces the block with the given ID, or appends the
+// block when the body has none.
+func replaceJournalBlock(body, id, block string) (string, error) {
+	begin := fmt.Sprintf(journalBeginMarker, id)
+	start := strings.Index(body, begin)
+	if start < 0 {
+		if body != "" && !strings.HasSuffix(body, "\n\n") {
+			body = strings.TrimRight(body, "\n") + "\n\n"
+		}
+		return body + block, nil
+	}
+
+	end := fmt.Sprintf(journalEndMarker, id)
+	n := strings.Index(body[start:], end)
+	if n < 0 {
+		return "", fmt.Errorf("journal block %s has no end marker %q", id, end)
+	}
+	stop := start + n + len(end)
+	if strings.HasPrefix(body[stop:], "\n") {
+		stop++
+	}
+	return body[:start] + block + body[stop:], nil
+}
+
+// updateFrontmatter sets the date and tags of a new note, and lists the
+// capabilities with blocks and the linked areas, keeping other keys as
+// they are.
+func (p *JournalPresenter) updateFrontmatter(frontmatter, body string, now time.Time) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		return "", fmt.Errorf("parsing journal frontmatter: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return "", fmt.Errorf("journal frontmatter is not a mapping")
+	}
+
+	if frontmatterValue(m, "date") == nil {
+		setFrontmatter(m, "date", &yaml.Node{Kind: yaml.ScalarNode, Value: now.Format(time.DateOnly)})
+	}
+	if frontmatterValue(m, "tags") == nil {
+		setFrontmatter(m, "tags", flowSequence(p.Tags))
+	}
+
+	var capabilities []string
+	for _, match := range journalBlock.FindAllStringSubmatch(body, -1) {
+		capabilities = append(capabilities, match[1])
+	}
+	setFrontmatter(m, "capabilities", flowSequence(capabilities))
+
+	// Areas the user listed are kept; linked ones are added.
+	areas := map[string]bool{}
+	if existing := frontmatterValue(m, "areas"); existing != nil {
+		if existing.Kind == yaml.ScalarNode && existing.Value != "" {
+			areas[existing.Value] = true
+		}
+		for _, n := range existing.Content {
+			areas[n.Value] = true
+		}
+	}
+	for _, area := range p.Areas {
+		if strings.Contains(body, "[["+area.Name+"]]") || strings.Contains(body, "[["+area.Name+"|") || strings.Contains(body, "[["+area.Name+`\|`) {
+			areas["[["+area.Name+"]]"] = true
+		}
+	}
+	if len(areas) > 0 {
+		var names []string
+		for name := range areas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		setFrontmatter(m, "areas", flowSequence(names))
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", fmt.Errorf("encoding journal frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("encoding journal frontmatter: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func frontmatterValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setFrontmatter(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+func flowSequence(values []string) *yaml.Node {
+	seq := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, v := range values {
+		seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: v})
+	}
+	return seq
+}
+
+// linkAreas turns mentions of area names into [[Area]] and of area keywords
+// into [[Area|keyword]], outside code blocks, code spans and links.
+func (p *JournalPresenter) linkAreas(md string) string {
+	terms := map[string]string{}
+	for _, area := range p.Areas {
+		for _, kw := range area.Keywords {
+			terms[strings.ToLower(kw)] = area.Name
+		}
+	}
+	for _, area := range p.Areas {
+		terms[strings.ToLower(area.Name)] = area.Name
+	}
+	if len(terms) == 0 {
+		return md
+	}
+
+	var alternatives []string
+	for term := range terms {
+		if term != "" {
+			alternatives = append(alternatives, regexp.QuoteMeta(term))
+		}
+	}
+	// Longer terms first, so "Project Apollo" wins over "Apollo".
+	sort.Slice(alternatives, func(i, j int) bool {
+		if len(alternatives[i]) != len(alternatives[j]) {
+			return len(alternatives[i]) > len(alternatives[j])
+		}
+		return alternatives[i] < alternatives[j]
+	})
+	pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(alternatives, "|") + `)\b`)
+
+	lines := strings.Split(md, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		// Pipes separate table cells, so aliases in tables are escaped.
+		sep := "|"
+		if strings.HasPrefix(line, "|") {
+			sep = `\|`
+		}
+		link := func(text string) string {
+			return pattern.ReplaceAllStringFunc(text, func(m string) string {
+				name := terms[strings.ToLower(m)]
+				if m == name {
+					return "[[" + name + "]]"
+				}
+				return "[[" + name + sep + m + "]]"
+			})
+		}
+
+		var b strings.Builder
+		last := 0
+		for _, span := range journalSkip.FindAllStringIndex(line, -1) {
+			b.WriteString(link(line[last:span[0]]))
+			b.WriteString(line[span[0]:span[1]])
+			last = span[1]
+		}
+		b.WriteString(link(line[last:]))
+		lines[i] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,163 @@
+package output_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+func newJournal(t *testing.T) (*output.JournalPresenter, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "Daily")
+	p := output.NewJournalPresenter(config.JournalConfig{Dir: dir}, []config.Area{
+		{Name: "Work", Keywords: []string{"standup", "1:1"}},
+		{Name: "Home"},
+	})
+	p.Now = func() time.Time { return time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC) }
+	return p, filepath.Join(dir, "2026-10-16.md")
+}
+
+func readJournal(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading journal: %v", err)
+	}
+	return string(data)
+}
+
+var digestBriefing = output.Briefing{
+	Title:      "Task Digest",
+	Capability: "task-digest",
+	Sections: []output.Section{{
+		Heading: "Today",
+		Items: []output.Item{
+			output.List{Checklist: true, Entries: []string{"Prepare standup notes", "Fix the home router", "Read `work` logs at https://example.com/work"}},
+			output.Table{Columns: []string{"When", "Event"}, Rows: [][]string{{"10:00", "Standup"}}},
+		},
+	}},
+}
+
+func TestJournalPresenter_NewFile(t *testing.T) {
+	p, path := newJournal(t)
+	if err := p.Present(digestBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "---\n" +
+		"date: 2026-10-16\n" +
+		"tags: [sam]\n" +
+		"capabilities: [task-digest]\n" +
+		"areas: ['[[Home]]', '[[Work]]']\n" +
+		"---\n\n" +
+		"<!-- sam:begin task-digest -->\n" +
+		"## Task Digest\n\n" +
+		"### Today\n\n" +
+		"- [ ] Prepare [[Work|standup]] notes\n" +
+		"- [ ] Fix the [[Home|home]] router\n" +
+		"- [ ] Read `work` logs at https://example.com/work\n\n" +
+		"| When | Event |\n" +
+		"| --- | --- |\n" +
+		"| 10:00 | [[Work\\|Standup]] |\n" +
+		"<!-- sam:end task-digest -->\n"
+	if got := readJournal(t, path); got != want {
+		t.Errorf("journal =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJournalPresenter_ReplacesBlockOnRerun(t *testing.T) {
+	p, path := newJournal(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notes := "---\nmood: good\n---\n\n# Friday\n\nMy own notes.\n"
+	if err := os.WriteFile(path, []byte(notes), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Present(digestBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recap := output.Briefing{Title: "Daily Recap", Capability: "daily-recap", Sections: []output.Section{{
+		Heading: "Done", Items: []output.Item{output.Paragraph("Shipped the release")},
+	}}}
+	if err := p.Present(recap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rerun := digestBriefing
+	rerun.Sections = []output.Section{{Heading: "Today", Items: []output.Item{output.Paragraph("Nothing left")}}}
+	if err := p.Present(rerun); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := readJournal(t, path)
+	if n := strings.Count(got, "<!-- sam:begin task-digest -->"); n != 1 {
+		t.Errorf("journal has %d task-digest blocks, want 1:\n%s", n, got)
+	}
+	if strings.Contains(got, "Prepare") || !strings.Contains(got, "### Today\n\nNothing left\n<!-- sam:end task-digest -->") {
+		t.Errorf("expected the task-digest block to be replaced:\n%s", got)
+	}
+	if !strings.HasPrefix(got, "---\nmood: good\ndate: 2026-10-16\ntags: [sam]\ncapabilities: [task-digest, daily-recap]\n") {
+		t.Errorf("expected frontmatter to keep mood and list both capabilities:\n%s", got)
+	}
+	if !strings.Contains(got, "---\n\n# Friday\n\nMy own notes.\n\n<!-- sam:begin task-digest -->") {
+		t.Errorf("expected own notes to be kept before the blocks:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "<!-- sam:end task-digest -->\n\n<!-- sam:begin daily-recap -->\n## Daily Recap\n\n### Done\n\nShipped the release\n<!-- sam:end daily-recap -->\n") {
+		t.Errorf("expected the blocks to stay in order:\n%s", got)
+	}
+}
+
+func TestJournalPresenter_MissingEndMarker(t *testing.T) {
+	p, path := newJournal(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	broken := "<!-- sam:begin task-digest -->\nedited by hand\n"
+	if err := os.WriteFile(path, []byte(broken), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Present(digestBriefing); err == nil {
+		t.Fatal("expected error for a block without an end marker")
+	}
+	if got := readJournal(t, path); got != broken {
+		t.Errorf("journal was changed to:\n%s", got)
+	}
+}
+
+func TestJournalPresenter_FileFormat(t *testing.T) {
+	dir := t.TempDir()
+	p := output.NewJournalPresenter(config.JournalConfig{Dir: dir, FileFormat: "2006_01_02", Tags: []string{"daily", "sam"}}, nil)
+	p.Now = func() time.Time { return time.Date(2026, 10, 16, 7, 30, 0, 0, time.UTC) }
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := readJournal(t, filepath.Join(dir, "2026_10_16.md"))
+	if !strings.Contains(got, "tags: [daily, sam]\ncapabilities: [morning-briefing]\n") {
+		t.Errorf("expected configured tags and a block named after the title:\n%s", got)
+	}
+}
+
+func TestDetectPresenter_JournalOutput(t *testing.T) {
+	cfg := config.Config{Outputs: []config.OutputConfig{{Type: "journal"}}}
+	if _, err := output.DetectPresenter("", cfg); err == nil {
+		t.Fatal("expected error for journal output without journal.dir")
+	}
+
+	cfg.Journal.Dir = t.TempDir()
+	p, err := output.DetectPresenter("", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.(*output.MultiPresenter).Destinations[0].Presenter.(*output.JournalPresenter); !ok {
+		t.Errorf("expected JournalPresenter destination, got %T", p.(*output.MultiPresenter).Destinations[0].Presenter)
+	}
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// mattermostMaxText is the most characters Mattermost accepts in a post,
+// counting the text of its attachments.
+const mattermostMaxText = 16383
+
+// MattermostPresenter delivers briefings via a Mattermost incoming webhook,
+// with each section as a Slack-compatible message attachment.
+type MattermostPresenter struct {
+	WebhookURL string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+}
+
+// NewMattermostPresenter creates a presenter that posts to the given
+// webhook URL.
+func NewMattermostPresenter(webhookURL string) *MattermostPresenter {
+	return &MattermostPresenter{
+		WebhookURL: webhookURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type mattermostPayload struct {
+	Text        string                 `json:"text,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments,omitempty"`
+}
+
+type mattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color,omitempty"`
+	Title    string            `json:"title,omitempty"`
+	Text     string            `json:"text,omitempty"`
+	Fields   []mattermostField `json:"fields,omitempty"`
+}
+
+type mattermostField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+// size is the number of characters that count towards mattermostMaxText.
+func (a mattermostAttachment) size() int {
+	n := utf8.RuneCountInString(a.Fallback) + utf8.RuneCountInString(a.Title) + utf8.RuneCountInString(a.Text)
+	for _, f := range a.Fields {
+		n += utf8.RuneCountInString(f.Title) + utf8.RuneCountInString(f.Value)
+	}
+	return n
+}
+
+// Present posts the briefing as one or more messages, in order.
+func (p *MattermostPresenter) Present(briefing Briefing) error {
+	for _, payload := range buildMattermostMessages(briefing) {
+		if err := p.post(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildMattermostMessages renders the title as the message text and each
+// section as an attachment, grouped into posts within Mattermost's limit.
+func buildMattermostMessages(briefing Briefing) []mattermostPayload {
+	title := truncateRunes("#### "+briefing.Title, mattermostMaxText)
+	messages := []mattermostPayload{{Text: title}}
+	size := utf8.RuneCountInString(title)
+	for _, section := range briefing.Sections {
+		for _, a := range mattermostAttachments(section) {
+			last := &messages[len(messages)-1]
+			if len(last.Attachments) > 0 && size+a.size() > mattermostMaxText {
+				messages = append(messages, mattermostPayload{})
+				last, size = &messages[len(messages)-1], 0
+			}
+			last.Attachments = append(last.Attachments, a)
+			size += a.size()
+		}
+	}
+	return messages
+}
+
+// mattermostAttachments renders a section as an attachment titled with its
+// heading, coloured by its most severe status, with its items as markdown
+// and facts as short fields. Long sections continue in further attachments.
+func mattermostAttachments(section Section) []mattermostAttachment {
+	var text []string
+	var fields []mattermostField
+	for _, item := range section.Items {
+		if facts, ok := item.(Facts); ok {
+			for _, f := range facts {
+				fields = append(fields, mattermostField{Title: f.Label, Value: f.Value, Short: true})
+			}
+			continue
+		}
+		if md := itemMarkdown(item); md != "" {
+			text = append(text, md)
+		}
+	}
+
+	color := statusColors[sectionLevel(section)]
+	// Leave room for the heading, repeated as the fallback.
+	limit := mattermostMaxText - 2*utf8.RuneCountInString(section.Heading)
+	attachments := []mattermostAttachment{{Fallback: section.Heading, Title: section.Heading, Color: color}}
+	for i, chunk := range splitSlackText(strings.Join(text, "\n\n"), limit) {
+		if i > 0 {
+			attachments = append(attachments, mattermostAttachment{Fallback: section.Heading, Color: color})
+		}
+		attachments[len(attachments)-1].Text = chunk
+	}
+
+	for _, f := range fields {
+		last := &attachments[len(attachments)-1]
+		n := utf8.RuneCountInString(f.Title) + utf8.RuneCountInString(f.Value)
+		if last.size()+n > mattermostMaxText {
+			attachments = append(attachments, mattermostAttachment{Fallback: section.Heading, Color: color})
+			last = &attachments[len(attachments)-1]
+		}
+		last.Fields = append(last.Fields, f)
+	}
+	return attachments
+}
+
+func (p *MattermostPresenter) post(payload mattermostPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling mattermost payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating mattermost request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending mattermost message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mattermost webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
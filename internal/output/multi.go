@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Destination is a presenter that receives briefings from some capabilities.
+type Destination struct {
+	// Name identifies the destination in errors.
+	Name string
+	// Capabilities limits the destination to briefings from these commands.
+	// Empty means all of them.
+	Capabilities []string
+	Presenter    Presenter
+}
+
+func (d Destination) accepts(capability string) bool {
+	if len(d.Capabilities) == 0 {
+		return true
+	}
+	for _, c := range d.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// MultiPresenter delivers each briefing to every destination that accepts
+// it. A failing destination does not stop delivery to the others.
+type MultiPresenter struct {
+	Destinations []Destination
+}
+
+// DeliveryError reports the destinations a briefing could not be delivered to.
+type DeliveryError struct {
+	// Failed lists the destinations that returned an error, in delivery order.
+	Failed []DestinationError
+	// Delivered counts the destinations that received the briefing.
+	Delivered int
+}
+
+// DestinationError is the error a single destination returned.
+type DestinationError struct {
+	Name string
+	Err  error
+}
+
+func (e *DeliveryError) Error() string {
+	var msgs []string
+	for _, f := range e.Failed {
+		msgs = append(msgs, fmt.Sprintf("delivering to %s: %v", f.Name, f.Err))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Partial reports whether the briefing reached at least one destination.
+func (e *DeliveryError) Partial() bool {
+	return e.Delivered > 0
+}
+
+func (p *MultiPresenter) Present(briefing Briefing) error {
+	var result DeliveryError
+	for _, d := range p.Destinations {
+		if !d.accepts(briefing.Capability) {
+			continue
+		}
+		if err := d.Presenter.Present(briefing); err != nil {
+			result.Failed = append(result.Failed, DestinationError{Name: d.Name, Err: err})
+			continue
+		}
+		result.Delivered++
+	}
+	if len(result.Failed) > 0 {
+		return &result
+	}
+	return nil
+}
+
+// FilePresenter appends briefings to a file as markdown, json or yaml.
+type FilePresenter struct {
+	Path string
+	// Format is FormatMarkdown, FormatJSON or FormatYAML.
+	Format string
+	// Now returns the time of the briefing. Defaults to time.Now.
+	Now func() time.Time
+}
+
+func (p *FilePresenter) Present(briefing Briefing) error {
+	var buf bytes.Buffer
+	var next Presenter
+	switch p.Format {
+	case FormatJSON:
+		next = &JSONPresenter{Writer: &buf, Now: p.Now}
+	case FormatYAML:
+		// Separate documents so the file stays a valid YAML stream.
+		buf.WriteString("---\n")
+		next = &YAMLPresenter{Writer: &buf, Now: p.Now}
+	default:
+		next = &TerminalPresenter{Writer: &buf}
+	}
+	if err := next.Present(briefing); err != nil {
+		return err
+	}
+	if _, ok := next.(*TerminalPresenter); ok {
+		// A blank line between briefings keeps their headings apart.
+		buf.WriteString("\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.Path), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	f, err := os.OpenFile(p.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening output file: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("writing output file: %w", err)
+	}
+	return f.Close()
+}
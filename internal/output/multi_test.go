@@ -0,0 +1,137 @@
+package output_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+// presenterFunc adapts a function to output.Presenter.
+type presenterFunc func(output.Briefing) error
+
+func (f presenterFunc) Present(briefing output.Briefing) error { return f(briefing) }
+
+func TestMultiPresenter_FailureDoesNotStopOthers(t *testing.T) {
+	var delivered []string
+	deliver := func(name string) output.Presenter {
+		return presenterFunc(func(output.Briefing) error {
+			delivered = append(delivered, name)
+			return nil
+		})
+	}
+	failing := presenterFunc(func(output.Briefing) error { return errors.New("connection refused") })
+
+	multi := &output.MultiPresenter{Destinations: []output.Destination{
+		{Name: "slack", Presenter: failing},
+		{Name: "archive", Presenter: deliver("archive")},
+		{Name: "reviews", Capabilities: []string{"weekly-review"}, Presenter: deliver("reviews")},
+		{Name: "digests", Capabilities: []string{"task-digest"}, Presenter: deliver("digests")},
+	}}
+
+	briefing := testBriefing
+	briefing.Capability = "task-digest"
+	err := multi.Present(briefing)
+
+	var delivery *output.DeliveryError
+	if !errors.As(err, &delivery) {
+		t.Fatalf("error = %v, want a DeliveryError", err)
+	}
+	if !delivery.Partial() || delivery.Delivered != 2 {
+		t.Errorf("delivered = %d, want a partial delivery to 2 destinations", delivery.Delivered)
+	}
+	if err.Error() != "delivering to slack: connection refused" {
+		t.Errorf("error = %q", err)
+	}
+	if strings.Join(delivered, ",") != "archive,digests" {
+		t.Errorf("delivered to %v, want archive and digests", delivered)
+	}
+}
+
+func TestMultiPresenter_AllFail(t *testing.T) {
+	failing := presenterFunc(func(output.Briefing) error { return errors.New("down") })
+	multi := &output.MultiPresenter{Destinations: []output.Destination{
+		{Name: "slack", Presenter: failing},
+		{Name: "email", Presenter: failing},
+	}}
+
+	var delivery *output.DeliveryError
+	if err := multi.Present(testBriefing); !errors.As(err, &delivery) || delivery.Partial() || len(delivery.Failed) != 2 {
+		t.Errorf("error = %v, want both destinations to fail", err)
+	}
+}
+
+func TestFilePresenter_Appends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "archive", "briefings.yaml")
+	p := &output.FilePresenter{Path: path, Format: output.FormatYAML, Now: func() time.Time {
+		return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC)
+	}}
+
+	for range 2 {
+		if err := p.Present(testBriefing); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "---\n"); n != 2 {
+		t.Errorf("file has %d documents, want 2:\n%s", n, data)
+	}
+	if n := strings.Count(string(data), "title: Morning Briefing"); n != 2 {
+		t.Errorf("file has %d briefings, want 2:\n%s", n, data)
+	}
+}
+
+func TestDetectPresenter_Outputs(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/test")
+
+	cfg := config.Config{Outputs: []config.OutputConfig{
+		{Type: "slack", Capabilities: []string{"task-digest"}},
+		{Type: "markdown", Name: "archive", Path: "briefings.md"},
+		{Type: "json"},
+	}}
+	p, err := output.DetectPresenter("", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	multi, ok := p.(*output.MultiPresenter)
+	if !ok {
+		t.Fatalf("expected MultiPresenter for configured outputs, got %T", p)
+	}
+
+	var names []string
+	for _, d := range multi.Destinations {
+		names = append(names, d.Name)
+	}
+	if strings.Join(names, ",") != "slack,archive,json" {
+		t.Errorf("destinations = %v", names)
+	}
+	if _, ok := multi.Destinations[0].Presenter.(*output.SlackPresenter); !ok {
+		t.Errorf("slack destination = %T, want SlackPresenter", multi.Destinations[0].Presenter)
+	}
+	if _, ok := multi.Destinations[1].Presenter.(*output.FilePresenter); !ok {
+		t.Errorf("archive destination = %T, want FilePresenter", multi.Destinations[1].Presenter)
+	}
+
+	// An explicit format still wins over configured outputs.
+	if p, _ := output.DetectPresenter("json", cfg); p == nil {
+		t.Error("expected a presenter for --output json")
+	} else if _, ok := p.(*output.JSONPresenter); !ok {
+		t.Errorf("expected JSONPresenter for --output json, got %T", p)
+	}
+
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+	if _, err := output.DetectPresenter("", cfg); err == nil {
+		t.Error("expected error for slack output without a token or webhook")
+	}
+}
@@ -0,0 +1,154 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// urgentSections returns the sections notification presenters push.
+func urgentSections(briefing Briefing) []Section {
+	var urgent []Section
+	for _, section := range briefing.Sections {
+		if section.Urgent {
+			urgent = append(urgent, section)
+		}
+	}
+	return urgent
+}
+
+// notificationTitle names a pushed section after its briefing, e.g.
+// "Invitations: Answer today", marked by the section's most severe status.
+func notificationTitle(briefing Briefing, section Section) string {
+	title := briefing.Title + ": " + section.Heading
+	if level := sectionLevel(section); level == LevelWarning || level == LevelError {
+		title = statusEmoji[level] + " " + title
+	}
+	return title
+}
+
+// sectionLinks returns the links of a section, which notifications show as
+// buttons where the platform has them.
+func sectionLinks(section Section) []Link {
+	var links []Link
+	for _, item := range section.Items {
+		if l, ok := item.(Link); ok {
+			links = append(links, l)
+		}
+	}
+	return links
+}
+
+// notificationTags converts the tags inlineHTML writes to the ones Telegram
+// and Pushover both understand.
+var notificationTags = strings.NewReplacer("<strong>", "<b>", "</strong>", "</b>", "<em>", "<i>", "</em>", "</i>")
+
+// notificationHTML renders an item in the HTML subset of push messages:
+// bold, italics, links and line breaks, with tables preformatted.
+func notificationHTML(item Item) string {
+	switch it := item.(type) {
+	case Paragraph:
+		var lines []string
+		for _, line := range strings.Split(string(it), "\n") {
+			lines = append(lines, inlineHTML(line))
+		}
+		return notificationTags.Replace(strings.Join(lines, "\n"))
+	case List:
+		var lines []string
+		if it.Title != "" {
+			lines = append(lines, "<b>"+inlineHTML(it.Title)+"</b>")
+		}
+		bullet := "• "
+		if it.Checklist {
+			bullet = "☐ "
+		}
+		for _, entry := range it.Entries {
+			lines = append(lines, bullet+inlineHTML(entry))
+		}
+		return notificationTags.Replace(strings.Join(lines, "\n"))
+	case Facts:
+		var lines []string
+		for _, f := range it {
+			lines = append(lines, fmt.Sprintf("<b>%s</b>: %s", html.EscapeString(f.Label), inlineHTML(f.Value)))
+		}
+		return notificationTags.Replace(strings.Join(lines, "\n"))
+	case Table:
+		return "<pre>" + html.EscapeString(tableText(it)) + "</pre>"
+	case Link:
+		return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(it.URL), html.EscapeString(it.Text))
+	case Status:
+		return notificationTags.Replace(statusEmoji[it.Level] + " " + inlineHTML(it.Text))
+	case TimeRange:
+		return fmt.Sprintf("<b>%s</b>: %s", html.EscapeString(it.Label), html.EscapeString(formatTimeRange(it)))
+	}
+	return ""
+}
+
+// fitHTML returns text, an item rendered as HTML, when it is within limit
+// bytes. Otherwise the item's plain text is escaped and cut instead, as
+// cutting the HTML could leave a tag or an entity open.
+func fitHTML(text string, item Item, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	var b strings.Builder
+	for _, r := range itemMarkdown(item) {
+		escaped := html.EscapeString(string(r))
+		if b.Len()+len(escaped) > limit-len("…") {
+			break
+		}
+		b.WriteString(escaped)
+	}
+	return b.String() + "…"
+}
+
+// joinWithin joins parts with blank lines into chunks of at most limit
+// bytes. Parts longer than limit on their own are cut, so HTML parts must
+// be fitted with fitHTML first.
+func joinWithin(parts []string, limit int) []string {
+	var chunks []string
+	current := ""
+	for _, part := range parts {
+		if len(part) > limit {
+			part = cutBytes(part, limit)
+		}
+		if current != "" && len(current)+2+len(part) > limit {
+			chunks = append(chunks, current)
+			current = ""
+		}
+		if current != "" {
+			current += "\n\n"
+		}
+		current += part
+	}
+	if current != "" {
+		chunks = append(chunks, current)
+	}
+	return chunks
+}
+
+// cutBytes shortens s to at most n bytes, ending with an ellipsis when it
+// was cut.
+func cutBytes(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	n -= len("…")
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "…"
+}
+
+// redactURL drops the request URL from HTTP client errors, for APIs that
+// carry a token in the URL.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,386 @@
+package output_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+// urgentBriefing has one routine and two urgent sections, one of them
+// reporting an error.
+var urgentBriefing = output.Briefing{
+	Title: "Invitations",
+	Sections: []output.Section{
+		{Heading: "Pending Invitations (3)", Items: []output.Item{output.Paragraph("Nothing to push")}},
+		{Heading: "Answer Today", Urgent: true, Items: []output.Item{
+			output.List{Entries: []string{"**Retro** at 15:00"}},
+			output.Status{Level: output.LevelWarning, Text: "1 of 1 conflict with your calendar"},
+			output.Link{Text: "Open calendar", URL: "https://calendar.google.com"},
+			output.Link{Text: "Open mail", URL: "https://mail.google.com"},
+		}},
+		{Heading: "Sync", Urgent: true, Items: []output.Item{
+			output.Status{Level: output.LevelError, Text: "Calendar sync failed"},
+		}},
+	},
+}
+
+// notifyServer records the requests made to it and answers with status and
+// response.
+func notifyServer(t *testing.T, status int, response string) (*httptest.Server, *[]*http.Request, *[][]byte) {
+	t.Helper()
+	var requests []*http.Request
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests, &bodies
+}
+
+type ntfyMessage struct {
+	Topic    string   `json:"topic"`
+	Title    string   `json:"title"`
+	Message  string   `json:"message"`
+	Priority int      `json:"priority"`
+	Tags     []string `json:"tags"`
+	Markdown bool     `json:"markdown"`
+	Click    string   `json:"click"`
+	Actions  []struct {
+		Action string `json:"action"`
+		Label  string `json:"label"`
+		URL    string `json:"url"`
+	} `json:"actions"`
+}
+
+func TestNtfyPresenter_PushesUrgentSections(t *testing.T) {
+	srv, requests, bodies := notifyServer(t, http.StatusOK, "{}")
+	p := output.NewNtfyPresenter(config.NtfyConfig{Server: srv.URL + "/", Topic: "sam"}, "tk_secret")
+	p.HTTPClient = srv.Client()
+
+	if err := p.Present(urgentBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[ntfyMessage](t, *bodies)
+	if len(messages) != 2 {
+		t.Fatalf("pushed %d messages, want one per urgent section", len(messages))
+	}
+	for _, r := range *requests {
+		if r.URL.Path != "/" || r.Header.Get("Authorization") != "Bearer tk_secret" {
+			t.Errorf("request to %s with auth %q, want the server root with the token", r.URL.Path, r.Header.Get("Authorization"))
+		}
+	}
+
+	today := messages[0]
+	if today.Topic != "sam" || today.Title != "Invitations: Answer Today" || !today.Markdown {
+		t.Errorf("message = %+v", today)
+	}
+	if today.Priority != 4 || strings.Join(today.Tags, ",") != "warning" {
+		t.Errorf("priority = %d, tags = %v, want high with a warning tag", today.Priority, today.Tags)
+	}
+	if today.Message != "- **Retro** at 15:00\n\n⚠️ 1 of 1 conflict with your calendar" {
+		t.Errorf("message = %q", today.Message)
+	}
+	if today.Click != "https://calendar.google.com" || len(today.Actions) != 2 || today.Actions[1].Label != "Open mail" || today.Actions[1].Action != "view" {
+		t.Errorf("click = %q, actions = %+v, want the links as view actions", today.Click, today.Actions)
+	}
+
+	if sync := messages[1]; sync.Priority != 5 || strings.Join(sync.Tags, ",") != "rotating_light" {
+		t.Errorf("priority = %d, tags = %v, want max for an error", sync.Priority, sync.Tags)
+	}
+}
+
+func TestNtfyPresenter_NothingUrgent(t *testing.T) {
+	srv, requests, _ := notifyServer(t, http.StatusOK, "{}")
+	p := output.NewNtfyPresenter(config.NtfyConfig{Server: srv.URL, Topic: "sam"}, "")
+	p.HTTPClient = srv.Client()
+
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*requests) != 0 {
+		t.Errorf("made %d requests, want none without urgent sections", len(*requests))
+	}
+}
+
+func TestNtfyPresenter_Error(t *testing.T) {
+	srv, _, _ := notifyServer(t, http.StatusForbidden, `{"code":40301}`)
+	p := output.NewNtfyPresenter(config.NtfyConfig{Server: srv.URL, Topic: "sam"}, "")
+	p.HTTPClient = srv.Client()
+
+	err := p.Present(urgentBriefing)
+	if err == nil || !strings.Contains(err.Error(), `pushing "Answer Today": ntfy returned status 403`) {
+		t.Errorf("error = %v, want the failed section and status", err)
+	}
+}
+
+func TestPushoverPresenter_PushesUrgentSections(t *testing.T) {
+	srv, requests, bodies := notifyServer(t, http.StatusOK, `{"status":1,"request":"r1"}`)
+	p := output.NewPushoverPresenter(config.PushoverConfig{User: "u123", Device: "phone"}, "a456")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	if err := p.Present(urgentBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("pushed %d messages, want one per urgent section", len(*requests))
+	}
+	if r := (*requests)[0]; r.URL.Path != "/1/messages.json" || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+		t.Errorf("request to %s as %q, want a form posted to /1/messages.json", r.URL.Path, r.Header.Get("Content-Type"))
+	}
+
+	form, err := url.ParseQuery(string((*bodies)[0]))
+	if err != nil {
+		t.Fatalf("invalid form: %v", err)
+	}
+	want := map[string]string{
+		"token":     "a456",
+		"user":      "u123",
+		"device":    "phone",
+		"title":     "⚠️ Invitations: Answer Today",
+		"message":   "• <b>Retro</b> at 15:00\n\n⚠️ 1 of 1 conflict with your calendar\n\n<a href=\"https://calendar.google.com\">Open calendar</a>\n\n<a href=\"https://mail.google.com\">Open mail</a>",
+		"html":      "1",
+		"priority":  "0",
+		"url":       "https://calendar.google.com",
+		"url_title": "Open calendar",
+	}
+	for key, value := range want {
+		if got := form.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+
+	sync, _ := url.ParseQuery(string((*bodies)[1]))
+	if sync.Get("priority") != "1" {
+		t.Errorf("priority = %q, want high for an error", sync.Get("priority"))
+	}
+}
+
+func TestPushoverPresenter_TruncatesMessage(t *testing.T) {
+	srv, _, bodies := notifyServer(t, http.StatusOK, `{"status":1}`)
+	p := output.NewPushoverPresenter(config.PushoverConfig{User: "u123"}, "a456")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	briefing := output.Briefing{Title: "Weekly Review", Sections: []output.Section{{
+		Heading: "Overdue",
+		Urgent:  true,
+		Items: []output.Item{
+			output.Paragraph(strings.Repeat("a", 800)),
+			output.Paragraph(strings.Repeat("b", 800)),
+		},
+	}}}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	form, _ := url.ParseQuery(string((*bodies)[0]))
+	message := form.Get("message")
+	if len([]rune(message)) > 1024 || !strings.HasSuffix(message, "\n\n…") || strings.Contains(message, "b") {
+		t.Errorf("message = %q, want the first paragraph within 1024 characters", message)
+	}
+}
+
+func TestPushoverPresenter_Error(t *testing.T) {
+	srv, _, _ := notifyServer(t, http.StatusBadRequest, `{"user":"invalid","errors":["user identifier is invalid"],"status":0}`)
+	p := output.NewPushoverPresenter(config.PushoverConfig{User: "u123"}, "a456")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	err := p.Present(urgentBriefing)
+	if err == nil || !strings.Contains(err.Error(), "pushover returned status 400: user identifier is invalid") {
+		t.Errorf("error = %v, want Pushover's errors", err)
+	}
+}
+
+type telegramMessage struct {
+	ChatID             string `json:"chat_id"`
+	Text               string `json:"text"`
+	ParseMode          string `json:"parse_mode"`
+	LinkPreviewOptions struct {
+		IsDisabled bool `json:"is_disabled"`
+	} `json:"link_preview_options"`
+	ReplyMarkup *struct {
+		InlineKeyboard [][]struct {
+			Text string `json:"text"`
+			URL  string `json:"url"`
+		} `json:"inline_keyboard"`
+	} `json:"reply_markup"`
+}
+
+func TestTelegramPresenter_PushesUrgentSections(t *testing.T) {
+	srv, requests, bodies := notifyServer(t, http.StatusOK, `{"ok":true,"result":{}}`)
+	p := output.NewTelegramPresenter(config.TelegramConfig{ChatID: "42"}, "123:ABC")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	if err := p.Present(urgentBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*requests) != 2 {
+		t.Fatalf("sent %d messages, want one per urgent section", len(*requests))
+	}
+	if path := (*requests)[0].URL.Path; path != "/bot123:ABC/sendMessage" {
+		t.Errorf("request to %s, want sendMessage for the bot", path)
+	}
+
+	messages := decodeBodies[telegramMessage](t, *bodies)
+	today := messages[0]
+	if today.ChatID != "42" || today.ParseMode != "HTML" || !today.LinkPreviewOptions.IsDisabled {
+		t.Errorf("message = %+v", today)
+	}
+	want := "<b>⚠️ Invitations: Answer Today</b>\n\n• <b>Retro</b> at 15:00\n\n⚠️ 1 of 1 conflict with your calendar"
+	if today.Text != want {
+		t.Errorf("text = %q, want %q", today.Text, want)
+	}
+	if today.ReplyMarkup == nil || len(today.ReplyMarkup.InlineKeyboard) != 2 || today.ReplyMarkup.InlineKeyboard[1][0].URL != "https://mail.google.com" {
+		t.Errorf("reply_markup = %+v, want a button per link", today.ReplyMarkup)
+	}
+	if messages[1].ReplyMarkup != nil {
+		t.Errorf("reply_markup = %+v, want none without links", messages[1].ReplyMarkup)
+	}
+}
+
+func TestTelegramPresenter_SplitsLongSections(t *testing.T) {
+	srv, _, bodies := notifyServer(t, http.StatusOK, `{"ok":true}`)
+	p := output.NewTelegramPresenter(config.TelegramConfig{ChatID: "42"}, "123:ABC")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	section := output.Section{Heading: "Overdue", Urgent: true}
+	for range 10 {
+		section.Items = append(section.Items, output.Paragraph(strings.Repeat("Follow up with the vendor. ", 40)))
+	}
+	section.Items = append(section.Items, output.Link{Text: "Open board", URL: "https://example.com"})
+	briefing := output.Briefing{Title: "Weekly Review", Sections: []output.Section{section}}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[telegramMessage](t, *bodies)
+	if len(messages) < 2 {
+		t.Fatalf("sent %d messages, want the long section split", len(messages))
+	}
+	for i, msg := range messages {
+		if len(msg.Text) > 4096 {
+			t.Errorf("message %d has %d bytes, Telegram allows 4096 characters", i, len(msg.Text))
+		}
+		if last := i == len(messages)-1; last != (msg.ReplyMarkup != nil) {
+			t.Errorf("message %d reply_markup = %+v, want buttons on the last message only", i, msg.ReplyMarkup)
+		}
+	}
+}
+
+func TestTelegramPresenter_CutsLongItemsOutsideTags(t *testing.T) {
+	srv, _, bodies := notifyServer(t, http.StatusOK, `{"ok":true}`)
+	p := output.NewTelegramPresenter(config.TelegramConfig{ChatID: "42"}, "123:ABC")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	long := output.Paragraph(strings.Repeat("**Pay** the [invoice](https://example.com/i) & fees. ", 120))
+	briefing := output.Briefing{Title: "Weekly Review", Sections: []output.Section{
+		{Heading: "Overdue", Urgent: true, Items: []output.Item{long}},
+	}}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entities := regexp.MustCompile(`&(amp|lt|gt|quot|#39);`)
+	for i, msg := range decodeBodies[telegramMessage](t, *bodies) {
+		if len(msg.Text) > 4096 {
+			t.Errorf("message %d has %d bytes, Telegram allows 4096 characters", i, len(msg.Text))
+		}
+		if strings.Count(msg.Text, "<b>") != strings.Count(msg.Text, "</b>") || strings.Count(msg.Text, "<a ") != strings.Count(msg.Text, "</a>") {
+			t.Errorf("message %d = %q, want every tag closed", i, msg.Text)
+		}
+		if strings.Contains(entities.ReplaceAllString(msg.Text, ""), "&") {
+			t.Errorf("message %d ends in a cut entity: %q", i, msg.Text[len(msg.Text)-20:])
+		}
+	}
+}
+
+func TestTelegramPresenter_ErrorHidesToken(t *testing.T) {
+	srv, _, _ := notifyServer(t, http.StatusUnauthorized, `{"ok":false,"error_code":401,"description":"Unauthorized"}`)
+	p := output.NewTelegramPresenter(config.TelegramConfig{ChatID: "42"}, "123:ABC")
+	p.BaseURL = srv.URL
+	p.HTTPClient = srv.Client()
+
+	err := p.Present(urgentBriefing)
+	if err == nil || !strings.Contains(err.Error(), "telegram returned status 401: Unauthorized") {
+		t.Errorf("error = %v, want Telegram's description", err)
+	}
+
+	srv.Close()
+	err = p.Present(urgentBriefing)
+	if err == nil || strings.Contains(err.Error(), "123:ABC") {
+		t.Errorf("error = %v, want a connection error without the bot token", err)
+	}
+}
+
+func TestDetectPresenter_NotificationOutputs(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("NTFY_TOKEN", "")
+	t.Setenv("PUSHOVER_TOKEN", "a456")
+	t.Setenv("TELEGRAM_BOT_TOKEN", "123:ABC")
+
+	cfg := config.Config{
+		Ntfy:     config.NtfyConfig{Topic: "sam"},
+		Pushover: config.PushoverConfig{User: "u123"},
+		Telegram: config.TelegramConfig{ChatID: "42"},
+		Outputs:  []config.OutputConfig{{Type: "ntfy"}, {Type: "pushover"}, {Type: "telegram"}},
+	}
+	p, err := output.DetectPresenter("", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	multi := p.(*output.MultiPresenter)
+	if ntfy, ok := multi.Destinations[0].Presenter.(*output.NtfyPresenter); !ok || ntfy.Server != "https://ntfy.sh" {
+		t.Errorf("ntfy destination = %+v, want ntfy.sh by default", multi.Destinations[0].Presenter)
+	}
+	if _, ok := multi.Destinations[1].Presenter.(*output.PushoverPresenter); !ok {
+		t.Errorf("pushover destination = %T, want PushoverPresenter", multi.Destinations[1].Presenter)
+	}
+	if _, ok := multi.Destinations[2].Presenter.(*output.TelegramPresenter); !ok {
+		t.Errorf("telegram destination = %T, want TelegramPresenter", multi.Destinations[2].Presenter)
+	}
+
+	t.Setenv("TELEGRAM_BOT_TOKEN", "")
+	if _, err := output.DetectPresenter("", cfg); err == nil || !strings.Contains(err.Error(), "TELEGRAM_BOT_TOKEN is not set") {
+		t.Errorf("error = %v, want the missing bot token", err)
+	}
+}
+
+func TestJSONPresenter_Urgent(t *testing.T) {
+	var buf strings.Builder
+	if err := (&output.JSONPresenter{Writer: &buf}).Present(urgentBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc struct {
+		Sections []struct {
+			Urgent bool `json:"urgent"`
+		} `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(buf.String()), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if len(doc.Sections) != 3 || doc.Sections[0].Urgent || !doc.Sections[1].Urgent {
+		t.Errorf("sections = %+v, want urgent flags", doc.Sections)
+	}
+	if strings.Count(buf.String(), `"urgent"`) != 2 {
+		t.Errorf("json = %s, want urgent omitted from routine sections", buf.String())
+	}
+}
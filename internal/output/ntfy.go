@@ -0,0 +1,158 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+)
+
+// ntfy limits.
+const (
+	// ntfyMaxMessage is the default message size limit of ntfy servers, in
+	// bytes. Larger messages become attachments.
+	ntfyMaxMessage = 4096
+	ntfyMaxActions = 3
+)
+
+// ntfy priorities. Urgent sections are pushed as high, or as max when they
+// report an error.
+const (
+	ntfyPriorityHigh = 4
+	ntfyPriorityMax  = 5
+)
+
+// ntfyTags are the emoji tags shown with notifications.
+var ntfyTags = map[Level]string{
+	LevelWarning: "warning",
+	LevelError:   "rotating_light",
+}
+
+// NtfyPresenter pushes the urgent sections of briefings to an ntfy topic.
+type NtfyPresenter struct {
+	// Server is the ntfy server URL, such as https://ntfy.sh.
+	Server string
+	Topic  string
+	// Token is an access token for protected topics.
+	Token      string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+}
+
+// NewNtfyPresenter creates a presenter that publishes to the configured
+// topic, authenticating with token when it is set.
+func NewNtfyPresenter(cfg config.NtfyConfig, token string) *NtfyPresenter {
+	server := cfg.Server
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	return &NtfyPresenter{
+		Server:     strings.TrimSuffix(server, "/"),
+		Topic:      cfg.Topic,
+		Token:      token,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type ntfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority"`
+	Tags     []string     `json:"tags,omitempty"`
+	Markdown bool         `json:"markdown"`
+	Click    string       `json:"click,omitempty"`
+	Actions  []ntfyAction `json:"actions,omitempty"`
+}
+
+type ntfyAction struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// Present publishes a notification for each urgent section.
+func (p *NtfyPresenter) Present(briefing Briefing) error {
+	for _, section := range urgentSections(briefing) {
+		if err := p.publish(buildNtfyMessage(p.Topic, briefing, section)); err != nil {
+			return fmt.Errorf("pushing %q: %w", section.Heading, err)
+		}
+	}
+	return nil
+}
+
+// buildNtfyMessage renders a section as markdown, with its links as view
+// actions and its first link opened by tapping the notification.
+func buildNtfyMessage(topic string, briefing Briefing, section Section) ntfyMessage {
+	level := sectionLevel(section)
+	msg := ntfyMessage{
+		Topic:    topic,
+		Title:    briefing.Title + ": " + section.Heading,
+		Priority: ntfyPriorityHigh,
+		Markdown: true,
+	}
+	if level == LevelError {
+		msg.Priority = ntfyPriorityMax
+	}
+	if tag, ok := ntfyTags[level]; ok {
+		msg.Tags = []string{tag}
+	}
+
+	var parts []string
+	for _, item := range section.Items {
+		if _, ok := item.(Link); ok {
+			continue
+		}
+		if md := itemMarkdown(item); md != "" {
+			parts = append(parts, md)
+		}
+	}
+	if chunks := joinWithin(parts, ntfyMaxMessage-len("\n\n…")); len(chunks) > 0 {
+		msg.Message = chunks[0]
+		if len(chunks) > 1 {
+			msg.Message += "\n\n…"
+		}
+	}
+
+	for i, link := range sectionLinks(section) {
+		if i == 0 {
+			msg.Click = link.URL
+		}
+		if i < ntfyMaxActions {
+			msg.Actions = append(msg.Actions, ntfyAction{Action: "view", Label: link.Text, URL: link.URL})
+		}
+	}
+	return msg
+}
+
+func (p *NtfyPresenter) publish(msg ntfyMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshalling ntfy message: %w", err)
+	}
+
+	// Publishing JSON goes to the server root, with the topic in the body.
+	req, err := http.NewRequest(http.MethodPost, p.Server+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating ntfy request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending ntfy message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ntfy returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -4,12 +4,20 @@ package output
 type Briefing struct {
 	Title    string
 	Sections []Section
+	// Capability is the command that produced the briefing. The router sets it.
+	Capability string
+	// Results are capability-specific data for machine-readable output, such
+	// as the IDs of created tasks. Presenters for people ignore them.
+	Results any
 }
 
 // Section is a titled block of content within a Briefing.
 type Section struct {
 	Heading string
-	Body    string
+	Items   []Item
+	// Urgent sections need attention sooner than the next briefing is read.
+	// Notification presenters push only urgent sections.
+	Urgent bool
 }
 
 // Presenter delivers a Briefing to the user.
@@ -3,19 +3,25 @@ package output_test
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/sergekukharev/agent-samwise/internal/config"
 	"github.com/sergekukharev/agent-samwise/internal/output"
 )
 
 var testBriefing = output.Briefing{
 	Title: "Morning Briefing",
 	Sections: []output.Section{
-		{Heading: "Calendar", Body: "3 events today"},
-		{Heading: "Tasks", Body: "2 items overdue\n1 due today"},
+		{Heading: "Calendar", Items: []output.Item{output.Paragraph("3 events today")}},
+		{Heading: "Tasks", Items: []output.Item{output.Paragraph("2 items overdue\n1 due today")}},
 	},
 }
 
@@ -49,6 +55,7 @@ func TestTerminalPresenter(t *testing.T) {
 type stubHTTPClient struct {
 	request    *http.Request
 	body       []byte
+	bodies     [][]byte // every request body, in order
 	statusCode int
 }
 
@@ -56,12 +63,56 @@ func (c *stubHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	c.request = req
 	body, _ := io.ReadAll(req.Body)
 	c.body = body
+	c.bodies = append(c.bodies, body)
 	return &http.Response{
 		StatusCode: c.statusCode,
 		Body:       io.NopCloser(strings.NewReader("ok")),
 	}, nil
 }
 
+// slackMessage mirrors the Block Kit payload for assertions.
+type slackMessage struct {
+	Text   string `json:"text"`
+	Blocks []struct {
+		Type string `json:"type"`
+		Text struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"text"`
+		Fields []struct {
+			Text string `json:"text"`
+		} `json:"fields"`
+		Elements []struct {
+			Type string          `json:"type"`
+			Text json.RawMessage `json:"text"`
+			URL  string          `json:"url"`
+		} `json:"elements"`
+	} `json:"blocks"`
+}
+
+func presentToSlack(t *testing.T, briefing output.Briefing) []slackMessage {
+	t.Helper()
+	client := &stubHTTPClient{statusCode: http.StatusOK}
+	p := &output.SlackPresenter{
+		WebhookURL: "https://hooks.slack.com/test",
+		HTTPClient: client,
+		Now:        func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) },
+	}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var messages []slackMessage
+	for _, body := range client.bodies {
+		var msg slackMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("invalid JSON payload: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
 func TestSlackPresenter_PayloadFormat(t *testing.T) {
 	client := &stubHTTPClient{statusCode: http.StatusOK}
 	p := &output.SlackPresenter{
@@ -77,36 +128,119 @@ func TestSlackPresenter_PayloadFormat(t *testing.T) {
 		t.Error("expected Content-Type application/json")
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(client.body, &payload); err != nil {
-		t.Fatalf("invalid JSON payload: %v", err)
+	messages := presentToSlack(t, testBriefing)
+	if len(messages) != 1 {
+		t.Fatalf("messages = %d, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.Text != "Morning Briefing" {
+		t.Errorf("fallback text = %q, want title", msg.Text)
+	}
+
+	var types []string
+	for _, b := range msg.Blocks {
+		types = append(types, b.Type)
+	}
+	if got := strings.Join(types, ","); got != "header,context,divider,section,divider,section" {
+		t.Fatalf("block types = %s, want header, context, then a divider and section per briefing section", got)
+	}
+
+	if msg.Blocks[0].Text.Type != "plain_text" || msg.Blocks[0].Text.Text != "Morning Briefing" {
+		t.Errorf("header = %+v, want plain-text title", msg.Blocks[0].Text)
 	}
+	var context string
+	json.Unmarshal(msg.Blocks[1].Elements[0].Text, &context)
+	if context != "<!date^1770708600^{date_short_pretty} at {time}|10 Feb 2026 07:30 UTC>" {
+		t.Errorf("context = %q, want localised timestamp", context)
+	}
+	if got := msg.Blocks[3].Text.Text; got != "*Calendar*\n3 events today" {
+		t.Errorf("calendar section = %q", got)
+	}
+	if got := msg.Blocks[5].Text.Text; got != "*Tasks*\n2 items overdue\n1 due today" {
+		t.Errorf("tasks section = %q", got)
+	}
+}
+
+func TestSlackPresenter_MarkdownToMrkdwn(t *testing.T) {
+	body := "### Top picks\n" +
+		"- **Budget** review with [Ada & Grace](https://example.com/doc?a=1&b=2)\n" +
+		"  * nested *italic* and __bold__ and ~~gone~~\n" +
+		"- [ ] open task\n" +
+		"- [x] done task\n" +
+		"Keep `**code**` and 3 < 4"
 
-	blocks, ok := payload["blocks"].([]interface{})
-	if !ok {
-		t.Fatal("missing blocks array")
+	messages := presentToSlack(t, output.Briefing{Title: "T", Sections: []output.Section{{Heading: "Notes", Items: []output.Item{output.Paragraph(body)}}}})
+
+	want := "*Notes*\n" +
+		"*Top picks*\n" +
+		"• *Budget* review with <https://example.com/doc?a=1&b=2|Ada &amp; Grace>\n" +
+		"  • nested _italic_ and *bold* and ~gone~\n" +
+		"☐ open task\n" +
+		"☑ done task\n" +
+		"Keep `**code**` and 3 &lt; 4"
+	if got := messages[0].Blocks[3].Text.Text; got != want {
+		t.Errorf("mrkdwn =\n%s\nwant\n%s", got, want)
 	}
+}
+
+func TestSlackPresenter_MeetingButtons(t *testing.T) {
+	body := "- 10:00 [Standup](https://meet.google.com/abc-defg-hij)\n- 14:00 Review https://acme.zoom.us/j/123\n- [Doc](https://docs.example.com/x)"
+	messages := presentToSlack(t, output.Briefing{Title: "Today", Sections: []output.Section{{Heading: "Calendar", Items: []output.Item{output.Paragraph(body)}}}})
 
-	// Single section block with full markdown content
-	if len(blocks) != 1 {
-		t.Errorf("blocks count = %d, want 1", len(blocks))
+	blocks := messages[0].Blocks
+	actions := blocks[len(blocks)-1]
+	if actions.Type != "actions" || len(actions.Elements) != 2 {
+		t.Fatalf("last block = %+v, want actions with two buttons", actions)
+	}
+	var label struct{ Text string }
+	json.Unmarshal(actions.Elements[0].Text, &label)
+	if actions.Elements[0].Type != "button" || label.Text != "Join Google Meet" || actions.Elements[0].URL != "https://meet.google.com/abc-defg-hij" {
+		t.Errorf("first button = %+v (%q), want Google Meet link", actions.Elements[0], label.Text)
+	}
+	if actions.Elements[1].URL != "https://acme.zoom.us/j/123" {
+		t.Errorf("second button url = %q, want Zoom link", actions.Elements[1].URL)
 	}
+}
+
+func TestSlackPresenter_SplitsLongBriefings(t *testing.T) {
+	var lines []string
+	for i := 0; i < 120; i++ {
+		lines = append(lines, fmt.Sprintf("- item %03d %s", i, strings.Repeat("x", 40)))
+	}
+	long := output.Section{Heading: "Long", Items: []output.Item{output.Paragraph(strings.Join(lines, "\n"))}}
 
-	section := blocks[0].(map[string]interface{})
-	if section["type"] != "section" {
-		t.Errorf("block type = %q, want section", section["type"])
+	var sections []output.Section
+	for i := 0; i < 30; i++ {
+		sections = append(sections, long)
 	}
+	messages := presentToSlack(t, output.Briefing{Title: "Big", Sections: sections})
 
-	text := section["text"].(map[string]interface{})
-	content := text["text"].(string)
-	if !strings.Contains(content, "*Morning Briefing*") {
-		t.Error("missing markdown title in Slack payload")
+	if len(messages) < 2 {
+		t.Fatalf("messages = %d, want the briefing split across messages", len(messages))
 	}
-	if !strings.Contains(content, "*Calendar*") {
-		t.Error("missing Calendar heading in Slack payload")
+	total := 0
+	for _, msg := range messages {
+		if len(msg.Blocks) > 50 {
+			t.Errorf("message has %d blocks, Slack allows 50", len(msg.Blocks))
+		}
+		if msg.Blocks[len(msg.Blocks)-1].Type == "divider" {
+			t.Error("message should not end with a divider")
+		}
+		for _, b := range msg.Blocks {
+			if b.Type != "section" {
+				continue
+			}
+			total++
+			if n := utf8.RuneCountInString(b.Text.Text); n > 3000 {
+				t.Errorf("section has %d characters, Slack allows 3000", n)
+			}
+			if strings.HasSuffix(b.Text.Text, "\n") || strings.HasPrefix(b.Text.Text, "\n") {
+				t.Error("section should be split between lines")
+			}
+		}
 	}
-	if !strings.Contains(content, "3 events today") {
-		t.Error("missing Calendar body in Slack payload")
+	if total <= len(sections) {
+		t.Errorf("sections = %d, want long sections split into several blocks", total)
 	}
 }
 
@@ -129,7 +263,7 @@ func TestSlackPresenter_NonOKStatus(t *testing.T) {
 func TestDetectPresenter_Local(t *testing.T) {
 	t.Setenv("GITHUB_ACTIONS", "")
 
-	p, err := output.DetectPresenter()
+	p, err := output.DetectPresenter("", config.Config{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -140,9 +274,10 @@ func TestDetectPresenter_Local(t *testing.T) {
 
 func TestDetectPresenter_GitHubActionsWithWebhook(t *testing.T) {
 	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
 	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/test")
 
-	p, err := output.DetectPresenter()
+	p, err := output.DetectPresenter("", config.Config{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -153,9 +288,10 @@ func TestDetectPresenter_GitHubActionsWithWebhook(t *testing.T) {
 
 func TestDetectPresenter_GitHubActionsWithoutWebhook(t *testing.T) {
 	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
 	t.Setenv("SLACK_WEBHOOK_URL", "")
 
-	_, err := output.DetectPresenter()
+	_, err := output.DetectPresenter("", config.Config{})
 	if err == nil {
 		t.Fatal("expected error for missing webhook in GitHub Actions")
 	}
@@ -163,3 +299,344 @@ func TestDetectPresenter_GitHubActionsWithoutWebhook(t *testing.T) {
 		t.Errorf("error should mention SLACK_WEBHOOK_URL, got: %v", err)
 	}
 }
+
+// fakeSlackAPI serves the Web API methods SlackBotPresenter calls from an
+// in-memory channel.
+type fakeSlackAPI struct {
+	messages []fakeSlackMessage
+	calls    []string
+	nextTS   int
+}
+
+type fakeSlackMessage struct {
+	Channel  string         `json:"channel"`
+	TS       string         `json:"ts"`
+	ThreadTS string         `json:"thread_ts,omitempty"`
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+func (f *fakeSlackAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	method := strings.TrimPrefix(r.URL.Path, "/")
+	f.calls = append(f.calls, method)
+	if r.Header.Get("Authorization") != "Bearer xoxb-test" {
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "invalid_auth"})
+		return
+	}
+
+	var msg fakeSlackMessage
+	if r.Method == http.MethodPost {
+		json.NewDecoder(r.Body).Decode(&msg)
+	}
+
+	switch method {
+	case "conversations.open":
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": map[string]string{"id": "D42"}})
+	case "chat.postMessage":
+		f.nextTS++
+		msg.TS = fmt.Sprintf("1771000000.%06d", f.nextTS)
+		f.messages = append(f.messages, msg)
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "ts": msg.TS})
+	case "chat.update":
+		for i := range f.messages {
+			if f.messages[i].TS == msg.TS {
+				msg.ThreadTS = f.messages[i].ThreadTS
+				f.messages[i] = msg
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "ts": msg.TS})
+	case "chat.delete":
+		f.messages = slices.DeleteFunc(f.messages, func(m fakeSlackMessage) bool { return m.TS == msg.TS })
+		json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	case "conversations.history", "conversations.replies":
+		var result []fakeSlackMessage
+		for _, m := range f.messages {
+			if m.Channel != r.URL.Query().Get("channel") {
+				continue
+			}
+			if ts := r.URL.Query().Get("ts"); method == "conversations.replies" && m.TS != ts && m.ThreadTS != ts {
+				continue
+			}
+			if method == "conversations.history" && m.ThreadTS != "" {
+				continue
+			}
+			result = append(result, m)
+		}
+		json.NewEncoder(w).Encode(map[string]any{"ok": true, "messages": result})
+	default:
+		json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "unknown_method"})
+	}
+}
+
+func (f *fakeSlackAPI) texts() []string {
+	var texts []string
+	for _, m := range f.messages {
+		texts = append(texts, m.Text)
+	}
+	return texts
+}
+
+func newSlackBot(api *fakeSlackAPI, user string) (*output.SlackBotPresenter, func()) {
+	server := httptest.NewServer(api)
+	p := output.NewSlackBotPresenter("xoxb-test", "C01", user)
+	p.BaseURL = server.URL
+	p.Now = func() time.Time { return time.Date(2026, 2, 13, 8, 0, 0, 0, time.Local) }
+	return p, server.Close
+}
+
+func TestSlackBotPresenter_PostsThread(t *testing.T) {
+	api := &fakeSlackAPI{}
+	p, done := newSlackBot(api, "")
+	defer done()
+
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(api.texts(), ","); got != "Morning Briefing,Calendar,Tasks" {
+		t.Fatalf("messages = %s, want the title then a reply per section", got)
+	}
+	parent := api.messages[0]
+	if parent.ThreadTS != "" || parent.Channel != "C01" {
+		t.Errorf("parent = %+v, want a top-level message in C01", parent)
+	}
+	for _, reply := range api.messages[1:] {
+		if reply.ThreadTS != parent.TS {
+			t.Errorf("reply %q thread_ts = %q, want %q", reply.Text, reply.ThreadTS, parent.TS)
+		}
+	}
+}
+
+func TestSlackBotPresenter_UpdatesSameDay(t *testing.T) {
+	api := &fakeSlackAPI{}
+	p, done := newSlackBot(api, "")
+	defer done()
+
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A different briefing the same day gets its own thread.
+	if err := p.Present(output.Briefing{Title: "Daily Recap", Sections: []output.Section{{Heading: "Done", Items: []output.Item{output.Paragraph("x")}}}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parentTS := api.messages[0].TS
+
+	rerun := output.Briefing{Title: "Morning Briefing", Sections: []output.Section{{Heading: "Calendar", Items: []output.Item{output.Paragraph("4 events today")}}}}
+	if err := p.Present(rerun); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := strings.Join(api.texts(), ","); got != "Morning Briefing,Calendar,Daily Recap,Done" {
+		t.Fatalf("messages = %s, want the morning thread updated in place", got)
+	}
+	if api.messages[0].TS != parentTS || api.messages[1].ThreadTS != parentTS {
+		t.Errorf("re-run should update the existing thread, got %+v", api.messages)
+	}
+	if !slices.Contains(api.calls, "chat.update") || !slices.Contains(api.calls, "chat.delete") {
+		t.Errorf("calls = %v, want updates and a deleted stale section", api.calls)
+	}
+
+	// The next day starts a new thread.
+	p.Now = func() time.Time { return time.Date(2026, 2, 14, 8, 0, 0, 0, time.Local) }
+	api.calls = nil
+	if err := p.Present(rerun); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(api.calls, "chat.update") {
+		t.Errorf("calls = %v, want a new thread on a new day", api.calls)
+	}
+}
+
+func TestSlackBotPresenter_DirectMessage(t *testing.T) {
+	api := &fakeSlackAPI{}
+	p, done := newSlackBot(api, "U42")
+	defer done()
+
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if api.calls[0] != "conversations.open" || api.messages[0].Channel != "D42" {
+		t.Errorf("calls = %v, channel = %q, want a direct message in D42", api.calls, api.messages[0].Channel)
+	}
+}
+
+func TestSlackBotPresenter_APIError(t *testing.T) {
+	api := &fakeSlackAPI{}
+	p, done := newSlackBot(api, "")
+	defer done()
+	p.Token = "xoxb-wrong"
+
+	err := p.Present(testBriefing)
+	if err == nil || !strings.Contains(err.Error(), "invalid_auth") {
+		t.Errorf("error = %v, want the Slack API error", err)
+	}
+}
+
+func TestDetectPresenter_GitHubActionsWithBotToken(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "xoxb-test")
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/test")
+
+	p, err := output.DetectPresenter("", config.Config{Slack: config.SlackConfig{Channel: "C01"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.(*output.SlackBotPresenter); !ok {
+		t.Errorf("expected SlackBotPresenter, got %T", p)
+	}
+
+	if _, err := output.DetectPresenter("", config.Config{}); err == nil {
+		t.Error("expected error for a bot token without a channel")
+	}
+}
+
+var workStart = time.Date(2026, 2, 10, 9, 0, 0, 0, time.Local)
+
+var itemsBriefing = output.Briefing{
+	Title: "Capacity",
+	Sections: []output.Section{{
+		Heading: "Today",
+		Items: []output.Item{
+			output.Paragraph("Plan for *today*"),
+			output.List{Title: "P1", Entries: []string{"Ship release", "Fix [bug](https://example.com/1)"}},
+			output.Facts{{Label: "Planned", Value: "3h"}, {Label: "Free", Value: "2h"}},
+			output.Status{Level: output.LevelWarning, Text: "Over-committed by 1h"},
+			output.TimeRange{Label: "Working hours", Start: workStart, End: workStart.Add(8 * time.Hour)},
+			output.Table{Columns: []string{"When", "Event"}, Rows: [][]string{{"10:00", "Standup"}, {"14:00", "Design | review"}}},
+			output.Link{Text: "Open board", URL: "https://example.com/board"},
+		},
+	}},
+}
+
+func TestTerminalPresenter_Items(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&output.TerminalPresenter{Writer: &buf}).Present(itemsBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "## Today\n\n" +
+		"Plan for *today*\n\n" +
+		"P1\n- Ship release\n- Fix [bug](https://example.com/1)\n\n" +
+		"Planned: 3h\nFree: 2h\n\n" +
+		"⚠️ Over-committed by 1h\n\n" +
+		"Working hours: Tue 10 Feb 09:00–17:00\n\n" +
+		"| When | Event |\n| --- | --- |\n| 10:00 | Standup |\n| 14:00 | Design \\| review |\n\n" +
+		"[Open board](https://example.com/board)\n"
+	if got := buf.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("output =\n%s\nwant suffix\n%s", got, want)
+	}
+}
+
+func TestSlackPresenter_Items(t *testing.T) {
+	messages := presentToSlack(t, itemsBriefing)
+	blocks := messages[0].Blocks[3:]
+
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b.Type)
+	}
+	if got := strings.Join(types, ","); got != "section,section,context,section,actions" {
+		t.Fatalf("block types = %s, want text, facts, status, text and link button", got)
+	}
+
+	if got, want := blocks[0].Text.Text, "*Today*\nPlan for _today_\n\nP1\n• Ship release\n• Fix <https://example.com/1|bug>"; got != want {
+		t.Errorf("text block = %q, want %q", got, want)
+	}
+	if len(blocks[1].Fields) != 2 || blocks[1].Fields[0].Text != "*Planned*\n3h" {
+		t.Errorf("facts = %+v, want a field per fact", blocks[1].Fields)
+	}
+	var status string
+	json.Unmarshal(blocks[2].Elements[0].Text, &status)
+	if status != "⚠️ Over-committed by 1h" {
+		t.Errorf("status = %q", status)
+	}
+	end := workStart.Add(8 * time.Hour)
+	want := fmt.Sprintf("*Working hours*: <!date^%d^{date_short_pretty} {time}|%s>–<!date^%d^{time}|%s>\n\n",
+		workStart.Unix(), workStart.UTC().Format("2 Jan 2006 15:04 UTC"), end.Unix(), end.UTC().Format("15:04 UTC")) +
+		"```\nWhen   Event\n10:00  Standup\n14:00  Design | review\n```"
+	if got := blocks[3].Text.Text; got != want {
+		t.Errorf("time range and table = %q, want %q", got, want)
+	}
+	if blocks[4].Elements[0].URL != "https://example.com/board" {
+		t.Errorf("link button = %+v", blocks[4].Elements[0])
+	}
+}
+
+func TestJSONPresenter_Schema(t *testing.T) {
+	var buf bytes.Buffer
+	p := &output.JSONPresenter{Writer: &buf, Now: func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) }}
+
+	briefing := itemsBriefing
+	briefing.Capability = "capacity-check"
+	briefing.Results = map[string]int{"planned_minutes": 180}
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid json: %v\n%s", err, buf.String())
+	}
+	if doc["schema_version"] != float64(output.SchemaVersion) || doc["capability"] != "capacity-check" || doc["generated_at"] != "2026-02-10T07:30:00Z" {
+		t.Errorf("document header = %v", doc)
+	}
+	if doc["results"].(map[string]any)["planned_minutes"] != float64(180) {
+		t.Errorf("results = %v, want capability results", doc["results"])
+	}
+
+	items := doc["sections"].([]any)[0].(map[string]any)["items"].([]any)
+	var types []string
+	for _, item := range items {
+		types = append(types, item.(map[string]any)["type"].(string))
+	}
+	if got := strings.Join(types, ","); got != "paragraph,list,facts,status,time_range,table,link" {
+		t.Errorf("item types = %s", got)
+	}
+	if status := items[3].(map[string]any); status["level"] != "warning" || status["text"] != "Over-committed by 1h" {
+		t.Errorf("status = %v", status)
+	}
+}
+
+func TestYAMLPresenter(t *testing.T) {
+	var buf bytes.Buffer
+	p := &output.YAMLPresenter{Writer: &buf, Now: func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) }}
+	if err := p.Present(testBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "schema_version: 1\n" +
+		"title: Morning Briefing\n" +
+		"generated_at: 2026-02-10T07:30:00Z\n" +
+		"sections:\n" +
+		"  - heading: Calendar\n" +
+		"    items:\n" +
+		"      - type: paragraph\n" +
+		"        text: 3 events today\n"
+	if got := buf.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("yaml =\n%s\nwant prefix\n%s", got, want)
+	}
+}
+
+func TestDetectPresenter_OutputFormat(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+
+	for format, want := range map[string]output.Presenter{
+		"json":     &output.JSONPresenter{},
+		"yaml":     &output.YAMLPresenter{},
+		"markdown": &output.TerminalPresenter{},
+	} {
+		p, err := output.DetectPresenter(format, config.Config{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		if fmt.Sprintf("%T", p) != fmt.Sprintf("%T", want) {
+			t.Errorf("%s: got %T, want %T", format, p, want)
+		}
+	}
+
+	if _, err := output.DetectPresenter("xml", config.Config{}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+)
+
+// Pushover message limits, in characters.
+const (
+	pushoverMaxTitle    = 250
+	pushoverMaxMessage  = 1024
+	pushoverMaxURL      = 512
+	pushoverMaxURLTitle = 100
+)
+
+// Pushover priorities. Urgent sections are pushed as normal, or as high,
+// which bypasses quiet hours, when they report an error.
+const (
+	pushoverPriorityNormal = 0
+	pushoverPriorityHigh   = 1
+)
+
+// pushoverTags drops the tags Pushover's HTML does not support.
+var pushoverTags = strings.NewReplacer("<del>", "", "</del>", "", "<code>", "", "</code>", "", "<pre>", "", "</pre>", "")
+
+// PushoverPresenter pushes the urgent sections of briefings through
+// Pushover.
+type PushoverPresenter struct {
+	// Token is the application's API token.
+	Token string
+	// User is the user or group key to notify.
+	User       string
+	Device     string
+	BaseURL    string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+}
+
+// NewPushoverPresenter creates a presenter that notifies the configured
+// user through the application with the given token.
+func NewPushoverPresenter(cfg config.PushoverConfig, token string) *PushoverPresenter {
+	return &PushoverPresenter{
+		Token:      token,
+		User:       cfg.User,
+		Device:     cfg.Device,
+		BaseURL:    "https://api.pushover.net",
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Present sends a notification for each urgent section.
+func (p *PushoverPresenter) Present(briefing Briefing) error {
+	for _, section := range urgentSections(briefing) {
+		if err := p.send(p.message(briefing, section)); err != nil {
+			return fmt.Errorf("pushing %q: %w", section.Heading, err)
+		}
+	}
+	return nil
+}
+
+// message renders a section as Pushover HTML, with its first link as the
+// notification's supplementary URL.
+func (p *PushoverPresenter) message(briefing Briefing, section Section) url.Values {
+	priority := pushoverPriorityNormal
+	if sectionLevel(section) == LevelError {
+		priority = pushoverPriorityHigh
+	}
+
+	var parts []string
+	for _, item := range section.Items {
+		text := fitHTML(pushoverTags.Replace(notificationHTML(item)), item, pushoverMaxMessage-len("\n\n…"))
+		if text != "" {
+			parts = append(parts, text)
+		}
+	}
+	// Characters are at most as many as bytes, so this stays within the limit.
+	var text string
+	if chunks := joinWithin(parts, pushoverMaxMessage-len("\n\n…")); len(chunks) > 0 {
+		text = chunks[0]
+		if len(chunks) > 1 {
+			text += "\n\n…"
+		}
+	}
+
+	form := url.Values{
+		"token":    {p.Token},
+		"user":     {p.User},
+		"title":    {truncateRunes(notificationTitle(briefing, section), pushoverMaxTitle)},
+		"message":  {text},
+		"html":     {"1"},
+		"priority": {strconv.Itoa(priority)},
+	}
+	if p.Device != "" {
+		form.Set("device", p.Device)
+	}
+	if links := sectionLinks(section); len(links) > 0 && len(links[0].URL) <= pushoverMaxURL {
+		form.Set("url", links[0].URL)
+		form.Set("url_title", truncateRunes(links[0].Text, pushoverMaxURLTitle))
+	}
+	return form
+}
+
+func (p *PushoverPresenter) send(form url.Values) error {
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+"/1/messages.json", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("creating pushover request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending pushover message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Status int      `json:"status"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("pushover returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || result.Status != 1 {
+		return fmt.Errorf("pushover returned status %d: %s", resp.StatusCode, strings.Join(result.Errors, "; "))
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // SlackPresenter delivers briefings via a Slack Incoming Webhook.
@@ -13,6 +14,8 @@ type SlackPresenter struct {
 	HTTPClient interface {
 		Do(req *http.Request) (*http.Response, error)
 	}
+	// Now returns the time shown under the title. Defaults to time.Now.
+	Now func() time.Time
 }
 
 // NewSlackPresenter creates a presenter that posts to the given webhook URL.
@@ -23,9 +26,22 @@ func NewSlackPresenter(webhookURL string) *SlackPresenter {
 	}
 }
 
+// Present posts the briefing as one or more messages, in order.
 func (p *SlackPresenter) Present(briefing Briefing) error {
-	payload := buildSlackPayload(briefing)
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+
+	for _, payload := range buildSlackMessages(briefing, now) {
+		if err := p.post(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func (p *SlackPresenter) post(payload slackPayload) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshalling slack payload: %w", err)
@@ -49,40 +65,3 @@ func (p *SlackPresenter) Present(briefing Briefing) error {
 
 	return nil
 }
-
-type slackPayload struct {
-	Blocks []slackBlock `json:"blocks"`
-}
-
-type slackBlock struct {
-	Type string     `json:"type"`
-	Text *slackText `json:"text,omitempty"`
-}
-
-type slackText struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
-}
-
-func buildSlackPayload(briefing Briefing) slackPayload {
-	// Build the full message as markdown, delivered in a single section block.
-	// Slack's mrkdwn is close enough to standard markdown for headings, bold, lists.
-	var md string
-	md += fmt.Sprintf("*%s*\n\n", briefing.Title)
-
-	for i, section := range briefing.Sections {
-		md += fmt.Sprintf("*%s*\n%s\n", section.Heading, section.Body)
-		if i < len(briefing.Sections)-1 {
-			md += "\n"
-		}
-	}
-
-	return slackPayload{
-		Blocks: []slackBlock{
-			{
-				Type: "section",
-				Text: &slackText{Type: "mrkdwn", Text: md},
-			},
-		},
-	}
-}
@@ -0,0 +1,401 @@
+package output
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Slack Block Kit limits.
+const (
+	slackMaxBlocks      = 50
+	slackMaxSectionText = 3000
+	slackMaxHeaderText  = 150
+	slackMaxButtons     = 5
+	slackMaxActions     = 25
+	slackMaxFields      = 10
+	slackMaxFieldText   = 2000
+	slackMaxButtonText  = 75
+)
+
+// meetingHosts are the domains whose links get a "Join" button.
+var meetingHosts = map[string]string{
+	"meet.google.com":     "Google Meet",
+	"zoom.us":             "Zoom",
+	"teams.microsoft.com": "Teams",
+	"teams.live.com":      "Teams",
+	"webex.com":           "Webex",
+	"whereby.com":         "Whereby",
+}
+
+type slackPayload struct {
+	// Text is the notification fallback for clients that cannot show blocks.
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string         `json:"type"`
+	Text     *slackText     `json:"text,omitempty"`
+	Fields   []slackText    `json:"fields,omitempty"`
+	Elements []slackElement `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackElement is a context text element or an actions button.
+type slackElement struct {
+	Type string `json:"type"`
+	// Text is a string for context elements and a slackText for buttons.
+	Text any    `json:"text"`
+	URL  string `json:"url,omitempty"`
+}
+
+// buildSlackMessages renders a briefing as Block Kit messages: a header with
+// the title and time, then each section followed by buttons for its meeting
+// links, separated by dividers. Long sections are split across blocks, and
+// briefings with too many blocks across messages.
+func buildSlackMessages(briefing Briefing, now time.Time) []slackPayload {
+	blocks := slackHeaderBlocks(briefing.Title, now)
+	for _, section := range briefing.Sections {
+		blocks = append(blocks, slackBlock{Type: "divider"})
+		blocks = append(blocks, slackSectionBlocks(section)...)
+	}
+	return splitSlackBlocks(briefing.Title, blocks)
+}
+
+// slackHeaderBlocks renders the title and the time of the briefing.
+func slackHeaderBlocks(title string, now time.Time) []slackBlock {
+	return []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: truncateRunes(title, slackMaxHeaderText)}},
+		{Type: "context", Elements: []slackElement{{Type: "mrkdwn", Text: slackDate(now)}}},
+	}
+}
+
+// slackSectionBlocks renders a section's items as blocks: text items as
+// mrkdwn, facts as fields, statuses as context and links as buttons,
+// followed by buttons for the meeting links in the text.
+func slackSectionBlocks(section Section) []slackBlock {
+	var blocks []slackBlock
+	var markdown []string
+
+	// Consecutive text items share a block under the heading.
+	pending, sep := "*"+escapeSlack(section.Heading)+"*", "\n"
+	flush := func() {
+		if pending == "" {
+			return
+		}
+		for _, chunk := range splitSlackText(pending, slackMaxSectionText) {
+			blocks = append(blocks, slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: chunk}})
+		}
+		pending, sep = "", ""
+	}
+
+	for _, item := range section.Items {
+		switch it := item.(type) {
+		case Facts:
+			flush()
+			blocks = append(blocks, slackFactBlocks(it)...)
+		case Status:
+			flush()
+			text := statusEmoji[it.Level] + " " + convertInline(it.Text)
+			blocks = append(blocks, slackBlock{Type: "context", Elements: []slackElement{{Type: "mrkdwn", Text: text}}})
+		case Link:
+			flush()
+			blocks = appendSlackButtons(blocks, []slackElement{slackButton(it.Text, it.URL)})
+		default:
+			if p, ok := it.(Paragraph); ok {
+				markdown = append(markdown, string(p))
+			} else if l, ok := it.(List); ok {
+				markdown = append(markdown, l.Entries...)
+			}
+			if pending == "" {
+				pending = slackItemText(item)
+			} else {
+				pending += sep + slackItemText(item)
+			}
+			sep = "\n\n"
+		}
+	}
+	flush()
+
+	if buttons := meetingButtons(strings.Join(markdown, "\n")); len(buttons) > 0 {
+		blocks = appendSlackButtons(blocks, buttons)
+	}
+	return blocks
+}
+
+// slackItemText renders a text item as mrkdwn.
+func slackItemText(item Item) string {
+	switch it := item.(type) {
+	case Paragraph:
+		return markdownToMrkdwn(string(it))
+	case List:
+		var lines []string
+		if it.Title != "" {
+			lines = append(lines, convertInline(it.Title))
+		}
+		bullet := "•"
+		if it.Checklist {
+			bullet = "☐"
+		}
+		for _, entry := range it.Entries {
+			lines = append(lines, bullet+" "+convertInline(entry))
+		}
+		return strings.Join(lines, "\n")
+	case Table:
+		return "```\n" + escapeSlack(tableText(it)) + "\n```"
+	case TimeRange:
+		return fmt.Sprintf("*%s*: %s", escapeSlack(it.Label), slackTimeRange(it))
+	}
+	return ""
+}
+
+// slackFactBlocks renders facts as two-column fields, ten to a block.
+func slackFactBlocks(facts Facts) []slackBlock {
+	var blocks []slackBlock
+	for len(facts) > 0 {
+		n := min(len(facts), slackMaxFields)
+		block := slackBlock{Type: "section"}
+		for _, f := range facts[:n] {
+			text := fmt.Sprintf("*%s*\n%s", escapeSlack(f.Label), convertInline(f.Value))
+			block.Fields = append(block.Fields, slackText{Type: "mrkdwn", Text: truncateRunes(text, slackMaxFieldText)})
+		}
+		blocks = append(blocks, block)
+		facts = facts[n:]
+	}
+	return blocks
+}
+
+// appendSlackButtons adds buttons to the last block when it is an actions
+// block with room for them, and in a new actions block otherwise.
+func appendSlackButtons(blocks []slackBlock, buttons []slackElement) []slackBlock {
+	if n := len(blocks); n > 0 && blocks[n-1].Type == "actions" && len(blocks[n-1].Elements)+len(buttons) <= slackMaxActions {
+		blocks[n-1].Elements = append(blocks[n-1].Elements, buttons...)
+		return blocks
+	}
+	return append(blocks, slackBlock{Type: "actions", Elements: buttons})
+}
+
+func slackButton(text, url string) slackElement {
+	return slackElement{
+		Type: "button",
+		Text: slackText{Type: "plain_text", Text: truncateRunes(text, slackMaxButtonText)},
+		URL:  url,
+	}
+}
+
+// slackTimeRange renders timed ranges in each reader's own time zone.
+// All-day ranges are dates, which are the same everywhere.
+func slackTimeRange(r TimeRange) string {
+	if r.AllDay {
+		return formatTimeRange(r)
+	}
+	start := fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", r.Start.Unix(), r.Start.UTC().Format("2 Jan 2006 15:04 UTC"))
+	end := fmt.Sprintf("<!date^%d^{time}|%s>", r.End.Unix(), r.End.UTC().Format("15:04 UTC"))
+	if from, to := r.Start.Local(), r.End.Local(); from.YearDay() != to.YearDay() || from.Year() != to.Year() {
+		end = fmt.Sprintf("<!date^%d^{date_short_pretty} {time}|%s>", r.End.Unix(), r.End.UTC().Format("2 Jan 2006 15:04 UTC"))
+	}
+	return start + "–" + end
+}
+
+// splitSlackBlocks groups blocks into messages within Slack's block limit,
+// each with text as its notification fallback.
+func splitSlackBlocks(text string, blocks []slackBlock) []slackPayload {
+	var messages []slackPayload
+	for len(blocks) > 0 {
+		n := min(len(blocks), slackMaxBlocks)
+		// Don't end a message on a divider.
+		if n < len(blocks) && blocks[n-1].Type == "divider" {
+			n--
+		}
+		messages = append(messages, slackPayload{Text: text, Blocks: blocks[:n]})
+		blocks = blocks[n:]
+	}
+	return messages
+}
+
+// slackDate renders t in each reader's own time zone, falling back to UTC.
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", t.Unix(), t.UTC().Format("2 Jan 2006 15:04 UTC"))
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s()<>|\]]+`)
+
+// meetingButtons returns a "Join" link button for each distinct meeting
+// link in a markdown body.
+func meetingButtons(body string) []slackElement {
+	var buttons []slackElement
+	seen := make(map[string]bool)
+	for _, link := range urlPattern.FindAllString(body, -1) {
+		name, ok := meetingService(link)
+		if !ok || seen[link] {
+			continue
+		}
+		seen[link] = true
+		buttons = append(buttons, slackButton("Join "+name, link))
+		if len(buttons) == slackMaxButtons {
+			break
+		}
+	}
+	return buttons
+}
+
+func meetingService(link string) (string, bool) {
+	rest := link[strings.Index(link, "://")+3:]
+	host, _, _ := strings.Cut(rest, "/")
+	for domain, name := range meetingHosts {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+var (
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdBold       = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdItalic     = regexp.MustCompile(`(^|[^\w*])\*([^*\s](?:[^*]*[^*\s])?)\*`)
+	mdStrike     = regexp.MustCompile(`~~(.+?)~~`)
+	mdHeading    = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+	mdListItem   = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdInlineCode = regexp.MustCompile("`[^`]*`")
+)
+
+// markdownToMrkdwn converts the markdown capabilities write to Slack's
+// mrkdwn: bold, italics, strikethrough, links, headings and bullet lists.
+// Inline code is left as is.
+func markdownToMrkdwn(md string) string {
+	lines := strings.Split(md, "\n")
+	inCode := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			lines[i] = "*" + convertInline(m[1]) + "*"
+			continue
+		}
+		if m := mdListItem.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + listBullet(&m[2]) + " " + convertInline(m[2])
+			continue
+		}
+		lines[i] = convertInline(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// listBullet returns the bullet for a list item, consuming a task checkbox.
+func listBullet(item *string) string {
+	switch {
+	case strings.HasPrefix(*item, "[ ] "):
+		*item = (*item)[4:]
+		return "☐"
+	case strings.HasPrefix(*item, "[x] "), strings.HasPrefix(*item, "[X] "):
+		*item = (*item)[4:]
+		return "☑"
+	}
+	return "•"
+}
+
+// convertInline converts the inline markup of a line outside code spans.
+func convertInline(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, span := range mdInlineCode.FindAllStringIndex(line, -1) {
+		b.WriteString(convertInlineText(line[last:span[0]]))
+		b.WriteString(line[span[0]:span[1]])
+		last = span[1]
+	}
+	b.WriteString(convertInlineText(line[last:]))
+	return b.String()
+}
+
+func convertInlineText(text string) string {
+	// Links are replaced by placeholders so their text and URLs are not
+	// escaped twice or styled.
+	var links []string
+	text = mdLink.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		links = append(links, fmt.Sprintf("<%s|%s>", parts[2], escapeSlack(strings.ReplaceAll(parts[1], "|", "¦"))))
+		return fmt.Sprintf("\x00%d\x00", len(links)-1)
+	})
+
+	text = escapeSlack(text)
+	text = mdItalic.ReplaceAllString(text, "${1}_${2}_")
+	text = mdBold.ReplaceAllString(text, "*${1}${2}*")
+	text = mdStrike.ReplaceAllString(text, "~${1}~")
+
+	for i, link := range links {
+		text = strings.Replace(text, fmt.Sprintf("\x00%d\x00", i), link, 1)
+	}
+	return text
+}
+
+// escapeSlack escapes the characters Slack treats as control sequences.
+func escapeSlack(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// splitSlackText splits text into chunks of at most limit characters,
+// breaking between lines where possible.
+func splitSlackText(text string, limit int) []string {
+	var chunks, current []string
+	size := 0
+	flush := func() {
+		if len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current, size = nil, 0
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		for utf8.RuneCountInString(line) > limit {
+			flush()
+			head := truncateRunesExact(line, limit)
+			chunks = append(chunks, head)
+			line = line[len(head):]
+		}
+		n := utf8.RuneCountInString(line)
+		if len(current) > 0 && size+1+n > limit {
+			flush()
+		}
+		if len(current) > 0 {
+			size++
+		}
+		current = append(current, line)
+		size += n
+	}
+	flush()
+	return chunks
+}
+
+// truncateRunes shortens s to at most n runes, ending with an ellipsis when
+// it was cut.
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return truncateRunesExact(s, n-1) + "…"
+}
+
+func truncateRunesExact(s string, n int) string {
+	i := 0
+	for pos := range s {
+		if i == n {
+			return s[:pos]
+		}
+		i++
+	}
+	return s
+}
@@ -0,0 +1,311 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Message metadata event types marking the messages Sam posts, so a re-run
+// can find and update them.
+const (
+	slackBriefingEvent = "sam_briefing"
+	slackSectionEvent  = "sam_briefing_section"
+)
+
+// SlackBotPresenter delivers briefings with a Slack bot token: the title is
+// posted as a parent message and each section as a reply in its thread.
+// Running again on the same day updates that day's thread instead of
+// posting a new one.
+type SlackBotPresenter struct {
+	Token string
+	// Channel is the ID of the channel to post in.
+	Channel string
+	// User is the ID of a user to send the briefing to as a direct message
+	// instead of posting in Channel.
+	User       string
+	BaseURL    string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+	// Now returns the time of the briefing. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewSlackBotPresenter creates a presenter that posts to channel, or to a
+// direct message with user when set, as the bot the token belongs to.
+func NewSlackBotPresenter(token, channel, user string) *SlackBotPresenter {
+	return &SlackBotPresenter{
+		Token:      token,
+		Channel:    channel,
+		User:       user,
+		BaseURL:    "https://slack.com/api",
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type slackMetadata struct {
+	EventType    string            `json:"event_type"`
+	EventPayload map[string]string `json:"event_payload"`
+}
+
+// slackChatMessage is a chat.postMessage or chat.update request.
+type slackChatMessage struct {
+	Channel  string         `json:"channel"`
+	TS       string         `json:"ts,omitempty"`
+	ThreadTS string         `json:"thread_ts,omitempty"`
+	Metadata *slackMetadata `json:"metadata,omitempty"`
+	slackPayload
+}
+
+// slackHistoryMessage is a message returned by conversations.history and
+// conversations.replies.
+type slackHistoryMessage struct {
+	TS       string         `json:"ts"`
+	Metadata *slackMetadata `json:"metadata"`
+}
+
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (r slackResponse) err() error {
+	if !r.OK {
+		return fmt.Errorf("slack API error: %s", r.Error)
+	}
+	return nil
+}
+
+// Present posts or updates the briefing's parent message, then its replies.
+func (p *SlackBotPresenter) Present(briefing Briefing) error {
+	now := time.Now()
+	if p.Now != nil {
+		now = p.Now()
+	}
+
+	channel, err := p.conversation()
+	if err != nil {
+		return err
+	}
+
+	metadata := &slackMetadata{
+		EventType:    slackBriefingEvent,
+		EventPayload: map[string]string{"title": briefing.Title, "date": now.Format("2006-01-02")},
+	}
+	parent := slackChatMessage{
+		Channel:      channel,
+		Metadata:     metadata,
+		slackPayload: slackPayload{Text: briefing.Title, Blocks: slackHeaderBlocks(briefing.Title, now)},
+	}
+
+	ts, err := p.findBriefing(channel, metadata, now)
+	if err != nil {
+		return err
+	}
+
+	var existing []string
+	if ts == "" {
+		if ts, err = p.postMessage("chat.postMessage", parent); err != nil {
+			return fmt.Errorf("posting briefing: %w", err)
+		}
+	} else {
+		parent.TS = ts
+		if _, err := p.postMessage("chat.update", parent); err != nil {
+			return fmt.Errorf("updating briefing: %w", err)
+		}
+		if existing, err = p.findReplies(channel, ts); err != nil {
+			return err
+		}
+	}
+
+	var replies []slackPayload
+	for _, section := range briefing.Sections {
+		replies = append(replies, splitSlackBlocks(section.Heading, slackSectionBlocks(section))...)
+	}
+
+	for i, reply := range replies {
+		msg := slackChatMessage{
+			Channel:      channel,
+			ThreadTS:     ts,
+			Metadata:     &slackMetadata{EventType: slackSectionEvent, EventPayload: map[string]string{}},
+			slackPayload: reply,
+		}
+		method := "chat.postMessage"
+		if i < len(existing) {
+			msg.TS, msg.ThreadTS = existing[i], ""
+			method = "chat.update"
+		}
+		if _, err := p.postMessage(method, msg); err != nil {
+			return fmt.Errorf("posting section %q: %w", reply.Text, err)
+		}
+	}
+
+	// Sections that are gone since the last run.
+	for _, stale := range existing[min(len(replies), len(existing)):] {
+		var resp slackResponse
+		if err := p.post("chat.delete", map[string]string{"channel": channel, "ts": stale}, &resp); err != nil {
+			return fmt.Errorf("deleting stale section: %w", err)
+		}
+		if err := resp.err(); err != nil {
+			return fmt.Errorf("deleting stale section: %w", err)
+		}
+	}
+	return nil
+}
+
+// conversation returns the channel to post in, opening a direct message
+// when User is set.
+func (p *SlackBotPresenter) conversation() (string, error) {
+	if p.User == "" {
+		return p.Channel, nil
+	}
+
+	var resp struct {
+		slackResponse
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := p.post("conversations.open", map[string]string{"users": p.User}, &resp); err != nil {
+		return "", fmt.Errorf("opening direct message: %w", err)
+	}
+	if err := resp.err(); err != nil {
+		return "", fmt.Errorf("opening direct message: %w", err)
+	}
+	return resp.Channel.ID, nil
+}
+
+// findBriefing returns the timestamp of the parent message posted today for
+// the same briefing, or "" when there is none.
+func (p *SlackBotPresenter) findBriefing(channel string, metadata *slackMetadata, now time.Time) (string, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	params := url.Values{
+		"channel":              {channel},
+		"oldest":               {strconv.FormatInt(midnight.Unix(), 10)},
+		"include_all_metadata": {"true"},
+		"limit":                {"200"},
+	}
+
+	var found string
+	err := p.history("conversations.history", params, func(msg slackHistoryMessage) {
+		if found == "" && msg.Metadata != nil && msg.Metadata.EventType == metadata.EventType &&
+			msg.Metadata.EventPayload["title"] == metadata.EventPayload["title"] &&
+			msg.Metadata.EventPayload["date"] == metadata.EventPayload["date"] {
+			found = msg.TS
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("finding today's briefing: %w", err)
+	}
+	return found, nil
+}
+
+// findReplies returns the timestamps of the section replies in a briefing's
+// thread, oldest first.
+func (p *SlackBotPresenter) findReplies(channel, ts string) ([]string, error) {
+	params := url.Values{
+		"channel":              {channel},
+		"ts":                   {ts},
+		"include_all_metadata": {"true"},
+		"limit":                {"200"},
+	}
+
+	var replies []string
+	err := p.history("conversations.replies", params, func(msg slackHistoryMessage) {
+		if msg.TS != ts && msg.Metadata != nil && msg.Metadata.EventType == slackSectionEvent {
+			replies = append(replies, msg.TS)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing briefing replies: %w", err)
+	}
+	return replies, nil
+}
+
+// history calls a conversations method, following its cursor through all
+// pages of messages.
+func (p *SlackBotPresenter) history(method string, params url.Values, visit func(slackHistoryMessage)) error {
+	for {
+		var resp struct {
+			slackResponse
+			Messages         []slackHistoryMessage `json:"messages"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := p.get(method, params, &resp); err != nil {
+			return err
+		}
+		if err := resp.err(); err != nil {
+			return err
+		}
+		for _, msg := range resp.Messages {
+			visit(msg)
+		}
+		if resp.ResponseMetadata.NextCursor == "" {
+			return nil
+		}
+		params.Set("cursor", resp.ResponseMetadata.NextCursor)
+	}
+}
+
+// postMessage sends a chat.postMessage or chat.update request and returns
+// the message timestamp.
+func (p *SlackBotPresenter) postMessage(method string, msg slackChatMessage) (string, error) {
+	var resp struct {
+		slackResponse
+		TS string `json:"ts"`
+	}
+	if err := p.post(method, msg, &resp); err != nil {
+		return "", err
+	}
+	if err := resp.err(); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+func (p *SlackBotPresenter) get(method string, params url.Values, v any) error {
+	req, err := http.NewRequest(http.MethodGet, p.BaseURL+"/"+method+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("creating slack request: %w", err)
+	}
+	return p.do(req, v)
+}
+
+func (p *SlackBotPresenter) post(method string, payload, v any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling slack payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return p.do(req, v)
+}
+
+func (p *SlackBotPresenter) do(req *http.Request, v any) error {
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("calling slack %s: %w", req.URL.Path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("decoding slack response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package output
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+)
+
+const defaultSubject = "{{.Title}}"
+
+// SMTPPresenter emails briefings as multipart messages with an HTML part
+// and a plain-text markdown part.
+type SMTPPresenter struct {
+	Host string
+	Port int
+	// TLS is config.SMTPStartTLS, config.SMTPTLS or config.SMTPNoTLS.
+	TLS      string
+	Username string
+	Password string
+	From     string
+	To       []string
+	Subject  *template.Template
+	// Now returns the time the message is sent. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// subjectData is what the subject template is rendered with.
+type subjectData struct {
+	Title      string
+	Capability string
+	Date       time.Time
+}
+
+// NewSMTPPresenter creates a presenter that sends through the configured
+// server, authenticating when a username is given.
+func NewSMTPPresenter(cfg config.SMTPConfig, username, password string) (*SMTPPresenter, error) {
+	text := cfg.Subject
+	if text == "" {
+		text = defaultSubject
+	}
+	subject, err := template.New("subject").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing smtp.subject: %w", err)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+		if cfg.TLSMode() == config.SMTPTLS {
+			port = 465
+		}
+	}
+
+	return &SMTPPresenter{
+		Host:     cfg.Host,
+		Port:     port,
+		TLS:      cfg.TLSMode(),
+		Username: username,
+		Password: password,
+		From:     cfg.From,
+		To:       cfg.To,
+		Subject:  subject,
+	}, nil
+}
+
+func (p *SMTPPresenter) Present(briefing Briefing) error {
+	now := presentTime(p.Now)
+
+	var subject strings.Builder
+	if err := p.Subject.Execute(&subject, subjectData{Title: briefing.Title, Capability: briefing.Capability, Date: now}); err != nil {
+		return fmt.Errorf("rendering email subject: %w", err)
+	}
+
+	msg, err := p.message(briefing, strings.TrimSpace(subject.String()), now)
+	if err != nil {
+		return err
+	}
+	return p.send(msg)
+}
+
+// message builds a multipart/alternative message. Clients show the last
+// part they can display, so HTML comes after plain text.
+func (p *SMTPPresenter) message(briefing Briefing, subject string, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", briefingMarkdown(briefing)},
+		{"text/html; charset=utf-8", briefingHTML(briefing)},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating email part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("writing email part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("writing email part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("finishing email: %w", err)
+	}
+
+	var msg bytes.Buffer
+	_, domain, _ := strings.Cut(envelopeAddress(p.From), "@")
+	for _, h := range [][2]string{
+		{"From", p.From},
+		{"To", strings.Join(p.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", now.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%d.sam@%s>", now.UnixNano(), domain)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	} {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+func (p *SMTPPresenter) send(msg []byte) error {
+	addr := net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	tlsConfig := &tls.Config{ServerName: p.Host}
+
+	var conn net.Conn
+	var err error
+	if p.TLS == config.SMTPTLS {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, 30*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to smtp server: %w", err)
+	}
+
+	c, err := smtp.NewClient(conn, p.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("starting smtp session: %w", err)
+	}
+	defer c.Close()
+
+	if p.TLS == config.SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", p.Host)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("starting smtp TLS: %w", err)
+		}
+	}
+
+	if p.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", p.Username, p.Password, p.Host)); err != nil {
+			return fmt.Errorf("authenticating to smtp server: %w", err)
+		}
+	}
+
+	if err := c.Mail(envelopeAddress(p.From)); err != nil {
+		return fmt.Errorf("setting email sender: %w", err)
+	}
+	for _, to := range p.To {
+		if err := c.Rcpt(envelopeAddress(to)); err != nil {
+			return fmt.Errorf("adding email recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("sending email: %w", err)
+	}
+	return c.Quit()
+}
+
+// envelopeAddress returns the bare address of "Sam <sam@example.com>".
+func envelopeAddress(addr string) string {
+	if a, err := mail.ParseAddress(addr); err == nil {
+		return a.Address
+	}
+	return addr
+}
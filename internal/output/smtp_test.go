@@ -0,0 +1,206 @@
+package output_test
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+// smtpSink is an in-process SMTP server that records one message.
+type smtpSink struct {
+	listener net.Listener
+	auth     string
+	from     string
+	to       []string
+	data     chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	s := &smtpSink{listener: l, data: make(chan string, 1)}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			creds, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+			s.auth = string(creds)
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = strings.Trim(strings.TrimPrefix(line, "MAIL FROM:"), "<>")
+			reply("250 OK")
+		case "RCPT":
+			s.to = append(s.to, strings.Trim(strings.TrimPrefix(line, "RCPT TO:"), "<>"))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.data <- data.String()
+			reply("250 OK: queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPPresenter_SendsMultipartBriefing(t *testing.T) {
+	sink := newSMTPSink(t)
+	p, err := output.NewSMTPPresenter(config.SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    sink.port(),
+		TLS:     config.SMTPNoTLS,
+		From:    "Sam <sam@example.com>",
+		To:      []string{"ada@example.com", "grace@example.com"},
+		Subject: `{{.Title}} · {{.Date.Format "Mon 2 Jan"}}`,
+	}, "sam", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p.Now = func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) }
+
+	if err := p.Present(itemsBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sink.auth != "\x00sam\x00secret" {
+		t.Errorf("auth = %q, want PLAIN credentials", sink.auth)
+	}
+	if sink.from != "sam@example.com" || strings.Join(sink.to, ",") != "ada@example.com,grace@example.com" {
+		t.Errorf("envelope = %s -> %v", sink.from, sink.to)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(<-sink.data))
+	if err != nil {
+		t.Fatalf("parsing message: %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Capacity · Tue 10 Feb" {
+		t.Errorf("subject = %q, want rendered template", subject)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, want multipart/alternative", mediaType)
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			break
+		}
+		body, _ := io.ReadAll(part)
+		ct, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[ct] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+
+	if text := parts["text/plain"]; !strings.Contains(text, "# Capacity\n\n## Today\n\nPlan for *today*") {
+		t.Errorf("plain text part = %q, want markdown", text)
+	}
+	html := parts["text/html"]
+	for _, want := range []string{
+		"<h1 style=\"font-size: 22px;\">Capacity</h1>",
+		"<p>Plan for <em>today</em></p>",
+		"<li>Fix <a href=\"https://example.com/1\">bug</a></li>",
+		"<th style=\"padding: 2px 12px 2px 0; text-align: left;\">Planned</th><td>3h</td>",
+		"⚠️ Over-committed by 1h</p>",
+		"Design | review</td>",
+		"<a href=\"https://example.com/board\"",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("html part missing %q, got:\n%s", want, html)
+		}
+	}
+}
+
+func TestSMTPPresenter_RequiresStartTLS(t *testing.T) {
+	sink := newSMTPSink(t)
+	p, err := output.NewSMTPPresenter(config.SMTPConfig{
+		Host: "127.0.0.1",
+		Port: sink.port(),
+		From: "sam@example.com",
+		To:   []string{"ada@example.com"},
+	}, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = p.Present(testBriefing)
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("error = %v, want STARTTLS to be required by default", err)
+	}
+}
+
+func TestDetectPresenter_Email(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+
+	cfg := config.Config{SMTP: config.SMTPConfig{Host: "smtp.example.com", From: "sam@example.com", To: []string{"ada@example.com"}}}
+	p, err := output.DetectPresenter("", cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	smtp, ok := p.(*output.SMTPPresenter)
+	if !ok {
+		t.Fatalf("expected SMTPPresenter without Slack, got %T", p)
+	}
+	if strconv.Itoa(smtp.Port) != "587" || smtp.TLS != config.SMTPStartTLS {
+		t.Errorf("presenter = %+v, want STARTTLS on 587 by default", smtp)
+	}
+
+	if _, err := output.DetectPresenter("email", config.Config{}); err == nil {
+		t.Error("expected error for email output without smtp config")
+	}
+}
@@ -0,0 +1,318 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Microsoft Teams webhook limits.
+const (
+	// teamsMaxPayload is the largest message Teams accepts, in bytes.
+	teamsMaxPayload = 28 * 1024
+	// teamsMaxCardBody leaves room in a message for the card envelope.
+	teamsMaxCardBody = teamsMaxPayload - 1024
+	// teamsMaxText is the longest TextBlock Sam sends, in characters.
+	teamsMaxText = 5000
+)
+
+// teamsColors are the Adaptive Card text colours of statuses.
+var teamsColors = map[Level]string{
+	LevelInfo:    "Accent",
+	LevelOK:      "Good",
+	LevelWarning: "Warning",
+	LevelError:   "Attention",
+}
+
+// TeamsPresenter delivers briefings via a Microsoft Teams incoming webhook
+// as Adaptive Cards.
+type TeamsPresenter struct {
+	WebhookURL string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+	// Now returns the time shown under the title. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewTeamsPresenter creates a presenter that posts to the given webhook URL.
+func NewTeamsPresenter(webhookURL string) *TeamsPresenter {
+	return &TeamsPresenter{
+		WebhookURL: webhookURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type teamsPayload struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+	MSTeams struct {
+		Width string `json:"width"`
+	} `json:"msteams"`
+}
+
+// teamsElement is a TextBlock, FactSet, Table or ActionSet.
+type teamsElement struct {
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`
+	Size      string `json:"size,omitempty"`
+	Weight    string `json:"weight,omitempty"`
+	Color     string `json:"color,omitempty"`
+	IsSubtle  bool   `json:"isSubtle,omitempty"`
+	Wrap      bool   `json:"wrap,omitempty"`
+	Separator bool   `json:"separator,omitempty"`
+	Spacing   string `json:"spacing,omitempty"`
+
+	Facts []teamsFact `json:"facts,omitempty"`
+
+	Columns          []teamsColumn `json:"columns,omitempty"`
+	Rows             []teamsRow    `json:"rows,omitempty"`
+	FirstRowAsHeader bool          `json:"firstRowAsHeader,omitempty"`
+
+	Actions []teamsAction `json:"actions,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type teamsColumn struct {
+	Width int `json:"width"`
+}
+
+type teamsRow struct {
+	Type  string      `json:"type"`
+	Cells []teamsCell `json:"cells"`
+}
+
+type teamsCell struct {
+	Type  string         `json:"type"`
+	Items []teamsElement `json:"items"`
+}
+
+type teamsAction struct {
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Present posts the briefing as one or more cards, in order.
+func (p *TeamsPresenter) Present(briefing Briefing) error {
+	for _, card := range buildTeamsCards(briefing, presentTime(p.Now)) {
+		payload := teamsPayload{
+			Type:        "message",
+			Attachments: []teamsAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+		}
+		if err := p.post(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// buildTeamsCards renders a briefing as Adaptive Cards: the title and time,
+// then each section's heading and items. Briefings too large for one
+// message continue in further cards.
+func buildTeamsCards(briefing Briefing, now time.Time) []teamsCard {
+	elements := []teamsElement{
+		{Type: "TextBlock", Text: briefing.Title, Size: "Large", Weight: "Bolder", Wrap: true},
+		{Type: "TextBlock", Text: teamsDate(now), IsSubtle: true, Spacing: "None", Wrap: true},
+	}
+	for _, section := range briefing.Sections {
+		elements = append(elements, teamsElement{Type: "TextBlock", Text: section.Heading, Size: "Medium", Weight: "Bolder", Wrap: true, Separator: true})
+		elements = append(elements, teamsSectionElements(section)...)
+	}
+
+	var cards []teamsCard
+	size := 0
+	for _, e := range elements {
+		data, _ := json.Marshal(e)
+		n := len(data) + 1
+		if len(cards) == 0 || (len(cards[len(cards)-1].Body) > 0 && size+n > teamsMaxCardBody) {
+			cards = append(cards, newTeamsCard())
+			size = 0
+		}
+		last := &cards[len(cards)-1]
+		last.Body = append(last.Body, e)
+		size += n
+	}
+	return cards
+}
+
+func newTeamsCard() teamsCard {
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.5",
+	}
+	card.MSTeams.Width = "Full"
+	return card
+}
+
+// teamsSectionElements renders a section's items: text as TextBlocks, facts
+// as a FactSet, tables as a Table and links as buttons.
+func teamsSectionElements(section Section) []teamsElement {
+	var elements []teamsElement
+	for _, item := range section.Items {
+		switch it := item.(type) {
+		case Facts:
+			set := teamsElement{Type: "FactSet"}
+			for _, f := range it {
+				set.Facts = append(set.Facts, teamsFact{Title: f.Label, Value: teamsMarkdown(f.Value)})
+			}
+			elements = append(elements, set)
+		case Table:
+			elements = append(elements, teamsTable(it))
+		case Link:
+			action := teamsAction{Type: "Action.OpenUrl", Title: it.Text, URL: it.URL}
+			if n := len(elements); n > 0 && elements[n-1].Type == "ActionSet" {
+				elements[n-1].Actions = append(elements[n-1].Actions, action)
+			} else {
+				elements = append(elements, teamsElement{Type: "ActionSet", Actions: []teamsAction{action}})
+			}
+		case Status:
+			elements = append(elements, teamsElement{
+				Type:   "TextBlock",
+				Text:   statusEmoji[it.Level] + " " + teamsMarkdown(it.Text),
+				Color:  teamsColors[it.Level],
+				Weight: "Bolder",
+				Wrap:   true,
+			})
+		default:
+			for _, chunk := range splitSlackText(teamsItemText(item), teamsMaxText) {
+				elements = append(elements, teamsElement{Type: "TextBlock", Text: chunk, Wrap: true})
+			}
+		}
+	}
+	return elements
+}
+
+// teamsItemText renders a text item in the markdown subset TextBlocks
+// support.
+func teamsItemText(item Item) string {
+	switch it := item.(type) {
+	case Paragraph:
+		var lines []string
+		for _, line := range strings.Split(string(it), "\n") {
+			if m := mdHeading.FindStringSubmatch(line); m != nil {
+				line = "**" + m[1] + "**"
+			} else if m := mdListItem.FindStringSubmatch(line); m != nil {
+				bullet := "- "
+				if b := listBullet(&m[2]); b != "•" {
+					bullet += b + " "
+				}
+				line = m[1] + bullet + m[2]
+			}
+			lines = append(lines, teamsMarkdown(line))
+		}
+		return strings.Join(lines, "\n")
+	case List:
+		var lines []string
+		if it.Title != "" {
+			lines = append(lines, teamsMarkdown(it.Title))
+		}
+		bullet := "- "
+		if it.Checklist {
+			bullet = "- ☐ "
+		}
+		for _, entry := range it.Entries {
+			lines = append(lines, bullet+teamsMarkdown(entry))
+		}
+		return strings.Join(lines, "\n")
+	case TimeRange:
+		return fmt.Sprintf("**%s**: %s", it.Label, teamsTimeRange(it))
+	}
+	return ""
+}
+
+// teamsMarkdown converts inline markdown to the subset TextBlocks support:
+// italics use underscores and strikethrough is dropped.
+func teamsMarkdown(line string) string {
+	line = mdItalic.ReplaceAllString(line, "${1}_${2}_")
+	return mdStrike.ReplaceAllString(line, "${1}")
+}
+
+func teamsTable(t Table) teamsElement {
+	cell := func(text string) teamsCell {
+		return teamsCell{Type: "TableCell", Items: []teamsElement{{Type: "TextBlock", Text: text, Wrap: true}}}
+	}
+
+	table := teamsElement{Type: "Table", FirstRowAsHeader: true}
+	header := teamsRow{Type: "TableRow"}
+	for _, c := range t.Columns {
+		table.Columns = append(table.Columns, teamsColumn{Width: 1})
+		header.Cells = append(header.Cells, cell(c))
+	}
+	table.Rows = append(table.Rows, header)
+	for _, r := range t.Rows {
+		row := teamsRow{Type: "TableRow"}
+		for i := range t.Columns {
+			text := ""
+			if i < len(r) {
+				text = r[i]
+			}
+			row.Cells = append(row.Cells, cell(text))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
+
+// teamsDate renders t in each reader's own time zone.
+func teamsDate(t time.Time) string {
+	ts := t.UTC().Format(time.RFC3339)
+	return fmt.Sprintf("{{DATE(%s, SHORT)}} at {{TIME(%s)}}", ts, ts)
+}
+
+// teamsTimeRange renders timed ranges in each reader's own time zone.
+func teamsTimeRange(r TimeRange) string {
+	if r.AllDay {
+		return formatTimeRange(r)
+	}
+	start, end := r.Start.UTC().Format(time.RFC3339), r.End.UTC().Format(time.RFC3339)
+	if from, to := r.Start.Local(), r.End.Local(); from.YearDay() != to.YearDay() || from.Year() != to.Year() {
+		return fmt.Sprintf("{{DATE(%s, SHORT)}} {{TIME(%s)}} – {{DATE(%s, SHORT)}} {{TIME(%s)}}", start, start, end, end)
+	}
+	return fmt.Sprintf("{{DATE(%s, SHORT)}} {{TIME(%s)}}–{{TIME(%s)}}", start, start, end)
+}
+
+func (p *TeamsPresenter) post(payload teamsPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshalling teams payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating teams request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending teams message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Connector webhooks answer 200 and Workflows webhooks 202 Accepted.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("teams webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+)
+
+// telegramMaxMessage is the Bot API's message length limit. It counts
+// characters after entities are parsed; limiting bytes of HTML is stricter.
+const telegramMaxMessage = 4096
+
+// TelegramPresenter pushes the urgent sections of briefings to a Telegram
+// chat through a bot. Telegram has no message priorities, so every pushed
+// section notifies.
+type TelegramPresenter struct {
+	// Token is the bot token, which the Bot API takes in the URL.
+	Token      string
+	ChatID     string
+	BaseURL    string
+	HTTPClient interface {
+		Do(req *http.Request) (*http.Response, error)
+	}
+}
+
+// NewTelegramPresenter creates a presenter that messages the configured
+// chat as the bot with the given token.
+func NewTelegramPresenter(cfg config.TelegramConfig, token string) *TelegramPresenter {
+	return &TelegramPresenter{
+		Token:      token,
+		ChatID:     cfg.ChatID,
+		BaseURL:    "https://api.telegram.org",
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+type telegramMessage struct {
+	ChatID             string              `json:"chat_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode"`
+	LinkPreviewOptions telegramLinkPreview `json:"link_preview_options"`
+	ReplyMarkup        *telegramKeyboard   `json:"reply_markup,omitempty"`
+}
+
+type telegramLinkPreview struct {
+	IsDisabled bool `json:"is_disabled"`
+}
+
+type telegramKeyboard struct {
+	InlineKeyboard [][]telegramButton `json:"inline_keyboard"`
+}
+
+type telegramButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// Present sends each urgent section as one or more messages, in order.
+func (p *TelegramPresenter) Present(briefing Briefing) error {
+	for _, section := range urgentSections(briefing) {
+		for _, msg := range buildTelegramMessages(p.ChatID, briefing, section) {
+			if err := p.send(msg); err != nil {
+				return fmt.Errorf("pushing %q: %w", section.Heading, err)
+			}
+		}
+	}
+	return nil
+}
+
+// buildTelegramMessages renders a section as Telegram HTML, split into
+// messages within the length limit. Links become buttons on the last
+// message.
+func buildTelegramMessages(chatID string, briefing Briefing, section Section) []telegramMessage {
+	title := notificationTitle(briefing, section)
+	parts := []string{"<b>" + fitHTML(html.EscapeString(title), Paragraph(title), telegramMaxMessage-len("<b></b>")) + "</b>"}
+	for _, item := range section.Items {
+		if _, ok := item.(Link); ok {
+			continue
+		}
+		// Telegram rejects messages with a tag left open.
+		if text := fitHTML(notificationHTML(item), item, telegramMaxMessage); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	var messages []telegramMessage
+	for _, chunk := range joinWithin(parts, telegramMaxMessage) {
+		messages = append(messages, telegramMessage{
+			ChatID:             chatID,
+			Text:               chunk,
+			ParseMode:          "HTML",
+			LinkPreviewOptions: telegramLinkPreview{IsDisabled: true},
+		})
+	}
+
+	if links := sectionLinks(section); len(links) > 0 {
+		keyboard := &telegramKeyboard{}
+		for _, link := range links {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []telegramButton{{Text: link.Text, URL: link.URL}})
+		}
+		messages[len(messages)-1].ReplyMarkup = keyboard
+	}
+	return messages
+}
+
+func (p *TelegramPresenter) send(msg telegramMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("marshalling telegram message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.BaseURL+"/bot"+p.Token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating telegram request: %w", redactURL(err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending telegram message: %w", redactURL(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	if !result.OK {
+		return fmt.Errorf("telegram returned status %d: %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
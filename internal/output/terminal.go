@@ -1,7 +1,6 @@
 package output
 
 import (
-	"fmt"
 	"io"
 	"os"
 )
@@ -17,15 +16,6 @@ func NewTerminalPresenter() *TerminalPresenter {
 }
 
 func (p *TerminalPresenter) Present(briefing Briefing) error {
-	fmt.Fprintf(p.Writer, "# %s\n\n", briefing.Title)
-
-	for i, section := range briefing.Sections {
-		fmt.Fprintf(p.Writer, "## %s\n\n", section.Heading)
-		fmt.Fprintf(p.Writer, "%s\n", section.Body)
-		if i < len(briefing.Sections)-1 {
-			fmt.Fprintln(p.Writer)
-		}
-	}
-
-	return nil
+	_, err := io.WriteString(p.Writer, briefingMarkdown(briefing))
+	return err
 }
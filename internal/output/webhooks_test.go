@@ -0,0 +1,374 @@
+package output_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sergekukharev/agent-samwise/internal/config"
+	"github.com/sergekukharev/agent-samwise/internal/output"
+)
+
+// webhookServer records the JSON bodies posted to it and answers with status.
+func webhookServer(t *testing.T, status int) (*httptest.Server, *[][]byte) {
+	t.Helper()
+	var bodies [][]byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func decodeBodies[T any](t *testing.T, bodies [][]byte) []T {
+	t.Helper()
+	var messages []T
+	for _, body := range bodies {
+		var msg T
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("invalid JSON payload: %v", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+// longBriefing has more and longer sections than any one message can hold.
+func longBriefing() output.Briefing {
+	briefing := output.Briefing{Title: "Weekly Review"}
+	for i := range 12 {
+		var entries []string
+		for j := range 200 {
+			entries = append(entries, fmt.Sprintf("Task %d of project %d with a reasonably long description", j, i))
+		}
+		var facts output.Facts
+		for j := range 30 {
+			facts = append(facts, output.Fact{Label: fmt.Sprintf("Metric %d", j), Value: strings.Repeat("x", 40)})
+		}
+		briefing.Sections = append(briefing.Sections, output.Section{
+			Heading: fmt.Sprintf("Project %d", i),
+			Items:   []output.Item{output.List{Entries: entries}, facts},
+		})
+	}
+	return briefing
+}
+
+type discordMessage struct {
+	Content string `json:"content"`
+	Embeds  []struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Color       int    `json:"color"`
+		Timestamp   string `json:"timestamp"`
+		Fields      []struct {
+			Name   string `json:"name"`
+			Value  string `json:"value"`
+			Inline bool   `json:"inline"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+func TestDiscordPresenter_Embeds(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusNoContent)
+	p := output.NewDiscordPresenter(srv.URL)
+	p.Now = func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) }
+
+	if err := p.Present(itemsBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[discordMessage](t, *bodies)
+	if len(messages) != 1 || len(messages[0].Embeds) != 1 {
+		t.Fatalf("messages = %+v, want one message with one embed", messages)
+	}
+	msg := messages[0]
+	if msg.Content != "**Capacity**" {
+		t.Errorf("content = %q, want the bold title", msg.Content)
+	}
+
+	embed := msg.Embeds[0]
+	if embed.Title != "Today" || embed.Color != 0x9a6700 || embed.Timestamp != "2026-02-10T07:30:00Z" {
+		t.Errorf("embed = %+v, want heading, warning colour and timestamp", embed)
+	}
+	for _, want := range []string{
+		"Plan for *today*",
+		"P1\n- Ship release\n- Fix [bug](https://example.com/1)",
+		"⚠️ Over-committed by 1h",
+		fmt.Sprintf("**Working hours**: <t:%d:f>–<t:%d:t>", workStart.Unix(), workStart.Add(8*time.Hour).Unix()),
+		"```\nWhen   Event\n10:00  Standup\n14:00  Design | review\n```",
+		"[Open board](https://example.com/board)",
+	} {
+		if !strings.Contains(embed.Description, want) {
+			t.Errorf("description missing %q, got:\n%s", want, embed.Description)
+		}
+	}
+	if len(embed.Fields) != 2 || embed.Fields[0].Name != "Planned" || embed.Fields[0].Value != "3h" || !embed.Fields[0].Inline {
+		t.Errorf("fields = %+v, want facts as inline fields", embed.Fields)
+	}
+}
+
+func TestDiscordPresenter_SizeLimits(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusNoContent)
+	if err := output.NewDiscordPresenter(srv.URL).Present(longBriefing()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[discordMessage](t, *bodies)
+	if len(messages) < 2 {
+		t.Fatalf("got %d messages, want the briefing split", len(messages))
+	}
+	fields := 0
+	for _, msg := range messages {
+		if len(msg.Embeds) > 10 {
+			t.Errorf("message has %d embeds, want at most 10", len(msg.Embeds))
+		}
+		total := 0
+		for _, e := range msg.Embeds {
+			if n := utf8.RuneCountInString(e.Description); n > 4096 {
+				t.Errorf("description has %d characters, want at most 4096", n)
+			}
+			if len(e.Fields) > 25 {
+				t.Errorf("embed has %d fields, want at most 25", len(e.Fields))
+			}
+			fields += len(e.Fields)
+			total += utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+			for _, f := range e.Fields {
+				total += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+			}
+		}
+		if total > 6000 {
+			t.Errorf("message embeds have %d characters, want at most 6000", total)
+		}
+	}
+	if fields != 12*30 {
+		t.Errorf("got %d fields, want every fact", fields)
+	}
+}
+
+func TestDiscordPresenter_ErrorStatus(t *testing.T) {
+	srv, _ := webhookServer(t, http.StatusBadRequest)
+	err := output.NewDiscordPresenter(srv.URL).Present(testBriefing)
+	if err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("error = %v, want the status code", err)
+	}
+}
+
+type teamsMessage struct {
+	Type        string `json:"type"`
+	Attachments []struct {
+		ContentType string          `json:"contentType"`
+		Content     json.RawMessage `json:"content"`
+	} `json:"attachments"`
+}
+
+type teamsCard struct {
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+type teamsElement struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Color string `json:"color"`
+	Facts []struct {
+		Title string `json:"title"`
+		Value string `json:"value"`
+	} `json:"facts"`
+	Rows []struct {
+		Cells []struct {
+			Items []teamsElement `json:"items"`
+		} `json:"cells"`
+	} `json:"rows"`
+	Actions []struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+		URL   string `json:"url"`
+	} `json:"actions"`
+}
+
+func presentToTeams(t *testing.T, briefing output.Briefing) ([]teamsCard, [][]byte) {
+	t.Helper()
+	srv, bodies := webhookServer(t, http.StatusAccepted)
+	p := output.NewTeamsPresenter(srv.URL)
+	p.Now = func() time.Time { return time.Date(2026, 2, 10, 7, 30, 0, 0, time.UTC) }
+	if err := p.Present(briefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var cards []teamsCard
+	for _, msg := range decodeBodies[teamsMessage](t, *bodies) {
+		if msg.Type != "message" || len(msg.Attachments) != 1 || msg.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+			t.Fatalf("message = %+v, want one adaptive card attachment", msg)
+		}
+		var card teamsCard
+		if err := json.Unmarshal(msg.Attachments[0].Content, &card); err != nil {
+			t.Fatalf("invalid card: %v", err)
+		}
+		cards = append(cards, card)
+	}
+	return cards, *bodies
+}
+
+func TestTeamsPresenter_AdaptiveCard(t *testing.T) {
+	cards, _ := presentToTeams(t, itemsBriefing)
+	if len(cards) != 1 {
+		t.Fatalf("got %d cards, want 1", len(cards))
+	}
+	card := cards[0]
+	if card.Type != "AdaptiveCard" || card.Version != "1.5" {
+		t.Errorf("card = %s %s, want AdaptiveCard 1.5", card.Type, card.Version)
+	}
+
+	var types []string
+	for _, e := range card.Body {
+		types = append(types, e.Type)
+	}
+	want := "TextBlock,TextBlock,TextBlock,TextBlock,TextBlock,FactSet,TextBlock,TextBlock,Table,ActionSet"
+	if strings.Join(types, ",") != want {
+		t.Fatalf("body = %v, want %s", types, want)
+	}
+
+	body := card.Body
+	if body[0].Text != "Capacity" || body[1].Text != "{{DATE(2026-02-10T07:30:00Z, SHORT)}} at {{TIME(2026-02-10T07:30:00Z)}}" || body[2].Text != "Today" {
+		t.Errorf("header = %q, %q, %q", body[0].Text, body[1].Text, body[2].Text)
+	}
+	if body[3].Text != "Plan for _today_" {
+		t.Errorf("paragraph = %q, want italics with underscores", body[3].Text)
+	}
+	if body[4].Text != "P1\n- Ship release\n- Fix [bug](https://example.com/1)" {
+		t.Errorf("list = %q", body[4].Text)
+	}
+	if len(body[5].Facts) != 2 || body[5].Facts[1].Title != "Free" || body[5].Facts[1].Value != "2h" {
+		t.Errorf("facts = %+v", body[5].Facts)
+	}
+	if body[6].Color != "Warning" || body[6].Text != "⚠️ Over-committed by 1h" {
+		t.Errorf("status = %+v, want warning colour", body[6])
+	}
+	start, end := workStart.UTC().Format(time.RFC3339), workStart.Add(8*time.Hour).UTC().Format(time.RFC3339)
+	if want := fmt.Sprintf("**Working hours**: {{DATE(%s, SHORT)}} {{TIME(%s)}}–{{TIME(%s)}}", start, start, end); body[7].Text != want {
+		t.Errorf("time range = %q, want %q", body[7].Text, want)
+	}
+	if rows := body[8].Rows; len(rows) != 3 || rows[2].Cells[1].Items[0].Text != "Design | review" {
+		t.Errorf("table rows = %+v, want header and two rows", rows)
+	}
+	if a := body[9].Actions; len(a) != 1 || a[0].Type != "Action.OpenUrl" || a[0].URL != "https://example.com/board" {
+		t.Errorf("actions = %+v, want a link button", a)
+	}
+}
+
+func TestTeamsPresenter_SizeLimits(t *testing.T) {
+	cards, bodies := presentToTeams(t, longBriefing())
+	if len(cards) < 2 {
+		t.Fatalf("got %d cards, want the briefing split", len(cards))
+	}
+	for _, body := range bodies {
+		if len(body) > 28*1024 {
+			t.Errorf("payload is %d bytes, want at most 28 KB", len(body))
+		}
+	}
+	if cards[0].Body[0].Text != "Weekly Review" {
+		t.Errorf("first card starts with %q, want the title", cards[0].Body[0].Text)
+	}
+}
+
+type mattermostMessage struct {
+	Text        string `json:"text"`
+	Attachments []struct {
+		Fallback string `json:"fallback"`
+		Color    string `json:"color"`
+		Title    string `json:"title"`
+		Text     string `json:"text"`
+		Fields   []struct {
+			Title string `json:"title"`
+			Value string `json:"value"`
+			Short bool   `json:"short"`
+		} `json:"fields"`
+	} `json:"attachments"`
+}
+
+func TestMattermostPresenter_Attachments(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusOK)
+	if err := output.NewMattermostPresenter(srv.URL).Present(itemsBriefing); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[mattermostMessage](t, *bodies)
+	if len(messages) != 1 || len(messages[0].Attachments) != 1 {
+		t.Fatalf("messages = %+v, want one message with one attachment", messages)
+	}
+	if messages[0].Text != "#### Capacity" {
+		t.Errorf("text = %q, want the title as a heading", messages[0].Text)
+	}
+
+	a := messages[0].Attachments[0]
+	if a.Title != "Today" || a.Fallback != "Today" || a.Color != "#9a6700" {
+		t.Errorf("attachment = %+v, want heading and warning colour", a)
+	}
+	if !strings.Contains(a.Text, "| When | Event |\n| --- | --- |") || !strings.Contains(a.Text, "[Open board](https://example.com/board)") {
+		t.Errorf("text = %q, want markdown with the table and link", a.Text)
+	}
+	if strings.Contains(a.Text, "Planned") {
+		t.Errorf("text = %q, want facts as fields only", a.Text)
+	}
+	if len(a.Fields) != 2 || a.Fields[0].Title != "Planned" || !a.Fields[0].Short {
+		t.Errorf("fields = %+v, want facts as short fields", a.Fields)
+	}
+}
+
+func TestMattermostPresenter_SizeLimits(t *testing.T) {
+	srv, bodies := webhookServer(t, http.StatusOK)
+	if err := output.NewMattermostPresenter(srv.URL).Present(longBriefing()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	messages := decodeBodies[mattermostMessage](t, *bodies)
+	if len(messages) < 2 {
+		t.Fatalf("got %d messages, want the briefing split", len(messages))
+	}
+	for _, msg := range messages {
+		total := utf8.RuneCountInString(msg.Text)
+		for _, a := range msg.Attachments {
+			total += utf8.RuneCountInString(a.Fallback) + utf8.RuneCountInString(a.Title) + utf8.RuneCountInString(a.Text)
+			for _, f := range a.Fields {
+				total += utf8.RuneCountInString(f.Title) + utf8.RuneCountInString(f.Value)
+			}
+		}
+		if total > 16383 {
+			t.Errorf("message has %d characters, want at most 16383", total)
+		}
+	}
+}
+
+func TestDetectPresenter_ChatWebhooks(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("SLACK_BOT_TOKEN", "")
+	t.Setenv("SLACK_WEBHOOK_URL", "")
+	t.Setenv("DISCORD_WEBHOOK_URL", "")
+	t.Setenv("TEAMS_WEBHOOK_URL", "https://example.webhook.office.com/test")
+	t.Setenv("MATTERMOST_WEBHOOK_URL", "https://chat.example.com/hooks/test")
+
+	p, err := output.DetectPresenter("", config.Config{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := p.(*output.TeamsPresenter); !ok {
+		t.Errorf("expected TeamsPresenter before Mattermost, got %T", p)
+	}
+
+	cfg := config.Config{Outputs: []config.OutputConfig{{Type: "mattermost"}, {Type: "discord"}}}
+	if _, err := output.DetectPresenter("", cfg); err == nil || !strings.Contains(err.Error(), "DISCORD_WEBHOOK_URL") {
+		t.Errorf("error = %v, want discord output to need DISCORD_WEBHOOK_URL", err)
+	}
+}
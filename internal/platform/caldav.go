@@ -0,0 +1,159 @@
+package platform
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CalDAVTaskClient creates tasks as VTODO resources in a CalDAV collection,
+// such as an Apple Reminders list or a Nextcloud task list.
+type CalDAVTaskClient struct {
+	collectionURL string
+	username      string
+	password      string
+	httpClient    *http.Client
+	now           func() time.Time
+	newUID        func() string
+}
+
+// NewCalDAVTaskClient creates a client for the task collection at collectionURL,
+// authenticating with HTTP basic auth.
+func NewCalDAVTaskClient(collectionURL, username, password string) *CalDAVTaskClient {
+	return &CalDAVTaskClient{
+		collectionURL: strings.TrimRight(collectionURL, "/"),
+		username:      username,
+		password:      password,
+		httpClient:    &http.Client{Timeout: 30 * time.Second},
+		now:           time.Now,
+		newUID:        func() string { return randomTaskID() + randomTaskID() },
+	}
+}
+
+// CreateTask stores the task as a new VTODO and returns its UID.
+func (c *CalDAVTaskClient) CreateTask(task TodoistTask) (string, error) {
+	uid := c.newUID()
+	body := buildVTodo(task, uid, c.now())
+
+	resourceURL := fmt.Sprintf("%s/%s.ics", c.collectionURL, uid)
+	req, err := http.NewRequest(http.MethodPut, resourceURL, strings.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating caldav request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	// Never overwrite an existing resource.
+	req.Header.Set("If-None-Match", "*")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("creating caldav task: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("CalDAV server returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	return uid, nil
+}
+
+// buildVTodo renders the task as an iCalendar VTODO (RFC 5545).
+func buildVTodo(task TodoistTask, uid string, now time.Time) string {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//agent-samwise//sam//EN",
+		"BEGIN:VTODO",
+		"UID:" + uid,
+		"DTSTAMP:" + now.UTC().Format(icalUTCFormat),
+		"SUMMARY:" + icalEscape(task.Title),
+	}
+
+	if task.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+icalEscape(task.Description))
+	}
+
+	if task.DueDateTime != nil {
+		lines = append(lines, "DUE:"+task.DueDateTime.UTC().Format(icalUTCFormat))
+	} else if task.DueDate != nil {
+		lines = append(lines, "DUE;VALUE=DATE:"+task.DueDate.Format(icalDateFormat))
+	}
+
+	if rrule, ok := recurrenceRule(task.DueString); ok && task.DueDateTime == nil {
+		// RRULE needs an anchor; recur from today when no due date is given.
+		if task.DueDate == nil {
+			lines = append(lines, "DTSTART;VALUE=DATE:"+now.Format(icalDateFormat))
+		}
+		lines = append(lines, "RRULE:"+rrule)
+	}
+
+	if p := icalPriority(task.Priority); p != 0 {
+		lines = append(lines, fmt.Sprintf("PRIORITY:%d", p))
+	}
+
+	if len(task.Labels) > 0 {
+		escaped := make([]string, len(task.Labels))
+		for i, l := range task.Labels {
+			escaped[i] = icalEscape(l)
+		}
+		lines = append(lines, "CATEGORIES:"+strings.Join(escaped, ","))
+	}
+
+	if task.ParentID != "" {
+		lines = append(lines, "RELATED-TO;RELTYPE=PARENT:"+task.ParentID)
+	}
+
+	lines = append(lines, "STATUS:NEEDS-ACTION", "END:VTODO", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(foldICalLine(line))
+		b.WriteString("\r\n")
+	}
+	return b.String()
+}
+
+// icalPriority maps Todoist priorities to iCalendar's 1 (highest) to 9 (lowest)
+// scale using the high/medium/low values Apple Reminders understands.
+func icalPriority(priority int) int {
+	switch priority {
+	case 4:
+		return 1
+	case 3:
+		return 5
+	case 2:
+		return 9
+	}
+	return 0
+}
+
+var icalWeekdays = map[string]string{
+	"monday": "MO", "tuesday": "TU", "wednesday": "WE", "thursday": "TH",
+	"friday": "FR", "saturday": "SA", "sunday": "SU",
+}
+
+// recurrenceRule translates simple Todoist recurrences ("every day",
+// "every week", "every friday") into an RRULE.
+func recurrenceRule(dueString string) (string, bool) {
+	s := strings.ToLower(strings.TrimSpace(dueString))
+	if !strings.HasPrefix(s, "every ") {
+		return "", false
+	}
+	switch rest := strings.TrimPrefix(s, "every "); rest {
+	case "day":
+		return "FREQ=DAILY", true
+	case "week":
+		return "FREQ=WEEKLY", true
+	case "month":
+		return "FREQ=MONTHLY", true
+	default:
+		if day, ok := icalWeekdays[rest]; ok {
+			return "FREQ=WEEKLY;BYDAY=" + day, true
+		}
+	}
+	return "", false
+}
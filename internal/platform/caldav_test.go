@@ -0,0 +1,114 @@
+package platform
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCalDAVTaskClient_CreateTask(t *testing.T) {
+	var method, path, ifNoneMatch, body string
+	var user, pass string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		user, pass, _ = r.BasicAuth()
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := NewCalDAVTaskClient(server.URL+"/calendars/sam/reminders/", "sam", "secret")
+	client.httpClient = server.Client()
+	client.newUID = func() string { return "uid-1" }
+	client.now = func() time.Time { return time.Date(2026, 2, 6, 7, 0, 0, 0, time.UTC) }
+
+	due := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
+	uid, err := client.CreateTask(TodoistTask{
+		Title:       "Standup, daily",
+		Description: "Agenda:\nhttps://meet.google.com/abc",
+		DueDateTime: &due,
+		Priority:    4,
+		ParentID:    "uid-0",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if uid != "uid-1" {
+		t.Errorf("uid = %q, want uid-1", uid)
+	}
+	if method != http.MethodPut || path != "/calendars/sam/reminders/uid-1.ics" {
+		t.Errorf("request = %s %s, want PUT to collection resource", method, path)
+	}
+	if ifNoneMatch != "*" {
+		t.Errorf("If-None-Match = %q, want *", ifNoneMatch)
+	}
+	if user != "sam" || pass != "secret" {
+		t.Errorf("basic auth = %q/%q, want sam/secret", user, pass)
+	}
+
+	for _, want := range []string{
+		"BEGIN:VTODO\r\n",
+		"UID:uid-1\r\n",
+		"DTSTAMP:20260206T070000Z\r\n",
+		"SUMMARY:Standup\\, daily\r\n",
+		"DESCRIPTION:Agenda:\\nhttps://meet.google.com/abc\r\n",
+		"DUE:20260206T100000Z\r\n",
+		"PRIORITY:1\r\n",
+		"RELATED-TO;RELTYPE=PARENT:uid-0\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("VTODO missing %q, got:\n%s", want, body)
+		}
+	}
+}
+
+func TestCalDAVTaskClient_ServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+	}))
+	defer server.Close()
+
+	client := NewCalDAVTaskClient(server.URL, "sam", "secret")
+	client.httpClient = server.Client()
+
+	if _, err := client.CreateTask(TodoistTask{Title: "Test"}); err == nil {
+		t.Fatal("expected error for 412 response")
+	}
+}
+
+func TestBuildVTodo_DateOnlyRecurring(t *testing.T) {
+	due := time.Date(2026, 2, 6, 0, 0, 0, 0, time.Local)
+	vtodo := buildVTodo(TodoistTask{Title: "Weekly review", DueDate: &due, DueString: "every Friday"}, "uid", time.Now())
+
+	if !strings.Contains(vtodo, "DUE;VALUE=DATE:20260206\r\n") {
+		t.Errorf("missing date-only DUE, got:\n%s", vtodo)
+	}
+	if !strings.Contains(vtodo, "RRULE:FREQ=WEEKLY;BYDAY=FR\r\n") {
+		t.Errorf("missing RRULE, got:\n%s", vtodo)
+	}
+	if strings.Contains(vtodo, "PRIORITY") {
+		t.Errorf("normal priority should be omitted, got:\n%s", vtodo)
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("ä", 60)
+	folded := foldICalLine(line)
+
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("folded line exceeds 75 octets: %d", len(part))
+		}
+	}
+	if strings.ReplaceAll(folded, "\r\n ", "") != line {
+		t.Error("unfolding did not restore the original line")
+	}
+}
@@ -14,14 +14,51 @@ const (
 
 // CalendarEvent represents a single event from a calendar.
 type CalendarEvent struct {
-	Title       string
-	StartTime   time.Time
+	ID        string
+	Title     string
+	StartTime time.Time
+	// EndTime is exclusive. All-day events run from local midnight on their
+	// first day to local midnight after their last.
+	EndTime     time.Time
 	AllDay      bool
 	MeetingLink string
 	RSVP        RSVPStatus
+	// Description is the event's agenda as plain text.
+	Description string
+	Location    string
+	Attendees   []Attendee
+	Attachments []Attachment
+	// ICalUID identifies the event across calendars, and in the iCalendar
+	// invitations sent by email.
+	ICalUID string
+}
+
+// Attendee is a guest invited to a calendar event.
+type Attendee struct {
+	Name      string
+	Email     string
+	RSVP      RSVPStatus
+	Organizer bool
+	Optional  bool
+}
+
+// Attachment is a file linked from a calendar event.
+type Attachment struct {
+	Title string
+	URL   string
 }
 
 // CalendarReader fetches events from a calendar.
 type CalendarReader interface {
 	TodayEvents(calendarID string) ([]CalendarEvent, error)
 }
+
+// CalendarRangeReader fetches events within an arbitrary time window.
+type CalendarRangeReader interface {
+	EventsBetween(calendarID string, start, end time.Time) ([]CalendarEvent, error)
+}
+
+// CalendarResponder answers event invitations on behalf of the calendar owner.
+type CalendarResponder interface {
+	RespondToEvent(calendarID, eventID string, status RSVPStatus) error
+}
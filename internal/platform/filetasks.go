@@ -0,0 +1,269 @@
+package platform
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats supported by FileTaskList.
+const (
+	FileFormatMarkdown = "markdown"
+	FileFormatTodoTxt  = "todotxt"
+)
+
+// FileTaskList writes tasks to a local Markdown checklist or todo.txt file,
+// for use without network access.
+type FileTaskList struct {
+	path   string
+	format string
+	now    func() time.Time
+	newID  func() string
+}
+
+// NewFileTaskList creates a task list backed by the file at path.
+// The format is "markdown" (default) or "todotxt".
+func NewFileTaskList(path, format string) (*FileTaskList, error) {
+	if path == "" {
+		return nil, fmt.Errorf("task file path is required")
+	}
+	if format == "" {
+		format = FileFormatMarkdown
+	}
+	if format != FileFormatMarkdown && format != FileFormatTodoTxt {
+		return nil, fmt.Errorf("unsupported task file format %q", format)
+	}
+
+	return &FileTaskList{
+		path:   path,
+		format: format,
+		now:    time.Now,
+		newID:  randomTaskID,
+	}, nil
+}
+
+// CreateTask adds the task to the file and returns a generated ID. Markdown
+// tasks carry the ID as a block reference (^id) and sub-tasks are nested
+// under their parent; todo.txt tasks use id: and parent: tags.
+func (l *FileTaskList) CreateTask(task TodoistTask) (string, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading task file: %w", err)
+	}
+
+	id := l.newID()
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	}
+
+	switch l.format {
+	case FileFormatTodoTxt:
+		lines = append(lines, l.todoTxtLine(task, id))
+	default:
+		lines, err = insertMarkdownTask(lines, task, id)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.WriteFile(l.path, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("writing task file: %w", err)
+	}
+
+	return id, nil
+}
+
+// insertMarkdownTask renders the task as a checklist item. Sub-tasks are
+// indented and placed after the parent and its existing sub-tasks.
+func insertMarkdownTask(lines []string, task TodoistTask, id string) ([]string, error) {
+	item := []string{fmt.Sprintf("- [ ] %s%s ^%s", task.Title, markdownTaskMeta(task), id)}
+	for _, line := range strings.Split(task.Description, "\n") {
+		if line != "" {
+			item = append(item, "  "+line)
+		}
+	}
+
+	if task.ParentID == "" {
+		return append(lines, item...), nil
+	}
+
+	parent := -1
+	for i, line := range lines {
+		if strings.HasSuffix(line, " ^"+task.ParentID) {
+			parent = i
+			break
+		}
+	}
+	if parent == -1 {
+		return nil, fmt.Errorf("parent task %s not found in task file", task.ParentID)
+	}
+
+	indent := leadingSpaces(lines[parent])
+	end := parent + 1
+	for end < len(lines) && lines[end] != "" && leadingSpaces(lines[end]) > indent {
+		end++
+	}
+
+	nested := make([]string, len(item))
+	for i, line := range item {
+		nested[i] = strings.Repeat(" ", indent+2) + line
+	}
+
+	result := append([]string{}, lines[:end]...)
+	result = append(result, nested...)
+	return append(result, lines[end:]...), nil
+}
+
+// markdownTaskMeta renders due date and priority after the title,
+// e.g. " (due 2026-02-06 10:00, P2, every friday)".
+func markdownTaskMeta(task TodoistTask) string {
+	var meta []string
+	if task.DueDateTime != nil {
+		meta = append(meta, "due "+task.DueDateTime.Local().Format("2006-01-02 15:04"))
+	} else if task.DueDate != nil {
+		meta = append(meta, "due "+task.DueDate.Format(time.DateOnly))
+	}
+	if task.Priority > 1 {
+		meta = append(meta, fmt.Sprintf("P%d", 5-task.Priority))
+	}
+	if task.DueString != "" && task.DueDateTime == nil {
+		meta = append(meta, task.DueString)
+	}
+	if len(meta) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(meta, ", ") + ")"
+}
+
+// todoTxtLine renders a task in todo.txt format:
+// "(A) 2026-02-06 Title description due:2026-02-06 id:abc parent:def".
+func (l *FileTaskList) todoTxtLine(task TodoistTask, id string) string {
+	var parts []string
+	if p := todoTxtPriority(task.Priority); p != "" {
+		parts = append(parts, p)
+	}
+	parts = append(parts, l.now().Format(time.DateOnly), task.Title)
+
+	if desc := strings.Join(strings.Fields(task.Description), " "); desc != "" {
+		parts = append(parts, desc)
+	}
+
+	if task.DueDateTime != nil {
+		local := task.DueDateTime.Local()
+		parts = append(parts, "due:"+local.Format(time.DateOnly), "at:"+local.Format("15:04"))
+	} else if task.DueDate != nil {
+		parts = append(parts, "due:"+task.DueDate.Format(time.DateOnly))
+	}
+	if task.DueString != "" && task.DueDateTime == nil {
+		parts = append(parts, l.todoTxtDueString(task))
+	}
+	for _, label := range task.Labels {
+		parts = append(parts, "@"+label)
+	}
+
+	parts = append(parts, "id:"+id)
+	if task.ParentID != "" {
+		parts = append(parts, "parent:"+task.ParentID)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// todoTxtDueString renders a Todoist due string in todo.txt terms: a
+// recurrence as rec: ("every friday" is rec:+1w, "every! 3 days" is
+// rec:3d), and today or tomorrow as due: when the task has no date. Other
+// due strings are kept in a when: tag, with dashes for spaces.
+func (l *FileTaskList) todoTxtDueString(task TodoistTask) string {
+	dueString := strings.ToLower(strings.TrimSpace(task.DueString))
+	if rec, ok := todoTxtRecurrence(dueString); ok {
+		return "rec:" + rec
+	}
+	if task.DueDate == nil {
+		switch dueString {
+		case "today":
+			return "due:" + l.now().Format(time.DateOnly)
+		case "tomorrow":
+			return "due:" + l.now().AddDate(0, 0, 1).Format(time.DateOnly)
+		}
+	}
+	return "when:" + strings.Join(strings.Fields(task.DueString), "-")
+}
+
+// todoTxtRecurrence maps simple Todoist recurrences to the rec: grammar,
+// [+]N followed by d, w, m or y. "every" repeats from the due date, which
+// rec: marks with "+"; "every!" repeats from completion.
+func todoTxtRecurrence(dueString string) (string, bool) {
+	strict := "+"
+	switch dueString {
+	case "daily":
+		return "+1d", true
+	case "weekly":
+		return "+1w", true
+	case "monthly":
+		return "+1m", true
+	case "yearly":
+		return "+1y", true
+	}
+
+	words := strings.Fields(dueString)
+	if len(words) < 2 || (words[0] != "every" && words[0] != "every!") {
+		return "", false
+	}
+	if words[0] == "every!" {
+		strict = ""
+	}
+	words = words[1:]
+
+	n := 1
+	if len(words) == 2 {
+		if words[0] == "other" {
+			n = 2
+		} else if v, err := strconv.Atoi(words[0]); err == nil && v > 0 {
+			n = v
+		} else {
+			return "", false
+		}
+		words = words[1:]
+	}
+	if len(words) != 1 {
+		return "", false
+	}
+
+	unit := strings.TrimSuffix(words[0], "s")
+	switch unit {
+	case "day", "week", "month", "year":
+		return fmt.Sprintf("%s%d%c", strict, n, unit[0]), true
+	case "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday",
+		"mon", "tue", "wed", "thu", "fri", "sat", "sun":
+		return fmt.Sprintf("%s%dw", strict, n), true
+	}
+	return "", false
+}
+
+// todoTxtPriority maps Todoist priorities (4 = urgent) to todo.txt letters.
+func todoTxtPriority(priority int) string {
+	switch priority {
+	case 4:
+		return "(A)"
+	case 3:
+		return "(B)"
+	case 2:
+		return "(C)"
+	}
+	return ""
+}
+
+func leadingSpaces(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+func randomTaskID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,153 @@
+package platform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestFileTaskList(t *testing.T, format, existing string) *FileTaskList {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tasks")
+	if existing != "" {
+		if err := os.WriteFile(path, []byte(existing), 0644); err != nil {
+			t.Fatalf("writing fixture: %v", err)
+		}
+	}
+
+	list, err := NewFileTaskList(path, format)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	n := 0
+	list.newID = func() string {
+		n++
+		return fmt.Sprintf("t%d", n)
+	}
+	list.now = func() time.Time { return time.Date(2026, 2, 6, 7, 0, 0, 0, time.Local) }
+	return list
+}
+
+func readTaskFile(t *testing.T, list *FileTaskList) string {
+	t.Helper()
+	data, err := os.ReadFile(list.path)
+	if err != nil {
+		t.Fatalf("reading task file: %v", err)
+	}
+	return string(data)
+}
+
+func TestFileTaskList_Markdown(t *testing.T) {
+	list := newTestFileTaskList(t, "", "# Today\n")
+
+	due := time.Date(2026, 2, 6, 10, 0, 0, 0, time.Local)
+	id, err := list.CreateTask(TodoistTask{
+		Title:       "Standup",
+		Description: "https://meet.google.com/abc",
+		DueDateTime: &due,
+		Priority:    3,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "t1" {
+		t.Errorf("id = %q, want t1", id)
+	}
+
+	if _, err := list.CreateTask(TodoistTask{Title: "Company Holiday", Priority: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "# Today\n" +
+		"- [ ] Standup (due 2026-02-06 10:00, P2) ^t1\n" +
+		"  https://meet.google.com/abc\n" +
+		"- [ ] Company Holiday ^t2\n"
+	if got := readTaskFile(t, list); got != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFileTaskList_MarkdownSubtasks(t *testing.T) {
+	list := newTestFileTaskList(t, "markdown", "")
+
+	parent, _ := list.CreateTask(TodoistTask{Title: "Weekly review", DueString: "every friday"})
+	list.CreateTask(TodoistTask{Title: "Later top-level task"})
+	if _, err := list.CreateTask(TodoistTask{Title: "Process inbox", ParentID: parent}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := list.CreateTask(TodoistTask{Title: "Review projects", ParentID: parent}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "- [ ] Weekly review (every friday) ^t1\n" +
+		"  - [ ] Process inbox ^t3\n" +
+		"  - [ ] Review projects ^t4\n" +
+		"- [ ] Later top-level task ^t2\n"
+	if got := readTaskFile(t, list); got != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFileTaskList_MarkdownUnknownParent(t *testing.T) {
+	list := newTestFileTaskList(t, "markdown", "")
+
+	if _, err := list.CreateTask(TodoistTask{Title: "Orphan", ParentID: "missing"}); err == nil {
+		t.Fatal("expected error for unknown parent")
+	}
+}
+
+func TestFileTaskList_TodoTxt(t *testing.T) {
+	list := newTestFileTaskList(t, "todotxt", "(A) 2026-02-05 Existing task\n")
+
+	due := time.Date(2026, 2, 6, 10, 0, 0, 0, time.Local)
+	parent, err := list.CreateTask(TodoistTask{
+		Title:       "Standup",
+		Description: "https://meet.google.com/abc",
+		DueDateTime: &due,
+		Priority:    4,
+		Labels:      []string{"meeting"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := list.CreateTask(TodoistTask{Title: "Prepare notes", ParentID: parent}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "(A) 2026-02-05 Existing task\n" +
+		"(A) 2026-02-06 Standup https://meet.google.com/abc due:2026-02-06 at:10:00 @meeting id:t1\n" +
+		"2026-02-06 Prepare notes id:t2 parent:t1\n"
+	if got := readTaskFile(t, list); got != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFileTaskList_TodoTxtDueStrings(t *testing.T) {
+	for dueString, want := range map[string]string{
+		"every friday":     "rec:+1w",
+		"Every 3 days":     "rec:+3d",
+		"every other week": "rec:+2w",
+		"every! month":     "rec:1m",
+		"yearly":           "rec:+1y",
+		"today":            "due:2026-02-06",
+		"tomorrow":         "due:2026-02-07",
+		"every 1st monday": "when:every-1st-monday",
+	} {
+		list := newTestFileTaskList(t, "todotxt", "")
+		if _, err := list.CreateTask(TodoistTask{Title: "Review", DueString: dueString}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := readTaskFile(t, list); got != "2026-02-06 Review "+want+" id:t1\n" {
+			t.Errorf("due string %q: file = %q, want %s", dueString, got, want)
+		}
+	}
+}
+
+func TestNewFileTaskList_UnsupportedFormat(t *testing.T) {
+	if _, err := NewFileTaskList("tasks.org", "org"); err == nil {
+		t.Fatal("expected error for unsupported format")
+	}
+}
@@ -1,78 +1,77 @@
 package platform
 
 import (
-	"crypto/rsa"
-	"crypto/x509"
+	"bytes"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 const (
-	googleTokenURL    = "https://oauth2.googleapis.com/token"
-	calendarAPIBase   = "https://www.googleapis.com/calendar/v3"
-	calendarReadScope = "https://www.googleapis.com/auth/calendar.readonly"
+	calendarAPIBase     = "https://www.googleapis.com/calendar/v3"
+	calendarReadScope   = "https://www.googleapis.com/auth/calendar.readonly"
+	calendarEventsScope = "https://www.googleapis.com/auth/calendar.events"
 )
 
-// ServiceAccountKey represents the JSON key file for a Google service account.
-type ServiceAccountKey struct {
-	ClientEmail string `json:"client_email"`
-	PrivateKey  string `json:"private_key"`
-	TokenURI    string `json:"token_uri"`
-}
-
 // GoogleCalendarClient reads events from Google Calendar using a service account.
 type GoogleCalendarClient struct {
-	credentials ServiceAccountKey
-	httpClient  *http.Client
-	accessToken string
-	tokenExpiry time.Time
+	auth       *googleAuth
+	baseURL    string
+	httpClient *http.Client
 }
 
 // NewGoogleCalendarClient creates a client from a service account JSON key.
 func NewGoogleCalendarClient(credentialsJSON string) (*GoogleCalendarClient, error) {
-	var key ServiceAccountKey
-	if err := json.Unmarshal([]byte(credentialsJSON), &key); err != nil {
-		return nil, fmt.Errorf("parsing service account credentials: %w", err)
-	}
+	return newGoogleCalendarClient(credentialsJSON, calendarReadScope)
+}
 
-	if key.ClientEmail == "" || key.PrivateKey == "" {
-		return nil, fmt.Errorf("service account credentials missing client_email or private_key")
-	}
+// NewGoogleCalendarResponder is like NewGoogleCalendarClient but requests the
+// calendar.events scope, which the service account needs to RSVP. The
+// calendar must be shared with it with permission to make changes to events.
+func NewGoogleCalendarResponder(credentialsJSON string) (*GoogleCalendarClient, error) {
+	return newGoogleCalendarClient(credentialsJSON, calendarEventsScope)
+}
 
-	if key.TokenURI == "" {
-		key.TokenURI = googleTokenURL
+func newGoogleCalendarClient(credentialsJSON, scope string) (*GoogleCalendarClient, error) {
+	key, err := parseServiceAccountKey(credentialsJSON)
+	if err != nil {
+		return nil, err
 	}
 
+	httpClient := &http.Client{Timeout: 30 * time.Second}
 	return &GoogleCalendarClient{
-		credentials: key,
-		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		auth:       newGoogleAuth(key, scope, "", httpClient),
+		baseURL:    calendarAPIBase,
+		httpClient: httpClient,
 	}, nil
 }
 
 func (c *GoogleCalendarClient) TodayEvents(calendarID string) ([]CalendarEvent, error) {
-	token, err := c.ensureToken()
-	if err != nil {
-		return nil, fmt.Errorf("authenticating with Google: %w", err)
-	}
-
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
+	return c.EventsBetween(calendarID, startOfDay, endOfDay)
+}
+
+func (c *GoogleCalendarClient) EventsBetween(calendarID string, start, end time.Time) ([]CalendarEvent, error) {
+	token, err := c.auth.token()
+	if err != nil {
+		return nil, fmt.Errorf("authenticating with Google: %w", err)
+	}
+
 	eventsURL := fmt.Sprintf("%s/calendars/%s/events?%s",
-		calendarAPIBase,
+		c.baseURL,
 		url.PathEscape(calendarID),
 		url.Values{
-			"timeMin":      {startOfDay.Format(time.RFC3339)},
-			"timeMax":      {endOfDay.Format(time.RFC3339)},
+			"timeMin":      {start.Format(time.RFC3339)},
+			"timeMax":      {end.Format(time.RFC3339)},
 			"singleEvents": {"true"},
 			"orderBy":      {"startTime"},
 		}.Encode(),
@@ -103,76 +102,78 @@ func (c *GoogleCalendarClient) TodayEvents(calendarID string) ([]CalendarEvent,
 	return parseCalendarEvents(result.Items), nil
 }
 
-func (c *GoogleCalendarClient) ensureToken() (string, error) {
-	if c.accessToken != "" && time.Now().Before(c.tokenExpiry) {
-		return c.accessToken, nil
-	}
+// RespondToEvent sets the calendar owner's response to an event they are
+// invited to and notifies the organizer.
+func (c *GoogleCalendarClient) RespondToEvent(calendarID, eventID string, status RSVPStatus) error {
+	eventURL := fmt.Sprintf("%s/calendars/%s/events/%s", c.baseURL, url.PathEscape(calendarID), url.PathEscape(eventID))
 
-	now := time.Now()
-	claims := jwt.MapClaims{
-		"iss":   c.credentials.ClientEmail,
-		"scope": calendarReadScope,
-		"aud":   c.credentials.TokenURI,
-		"iat":   now.Unix(),
-		"exp":   now.Add(time.Hour).Unix(),
+	// Attendees are kept as decoded, so fields this client does not model,
+	// such as comments and additional guests, survive the update.
+	var event struct {
+		Attendees []map[string]any `json:"attendees"`
+	}
+	if err := c.do(http.MethodGet, eventURL, nil, &event); err != nil {
+		return fmt.Errorf("fetching calendar event: %w", err)
 	}
 
-	privateKey, err := parseRSAPrivateKey(c.credentials.PrivateKey)
-	if err != nil {
-		return "", fmt.Errorf("parsing private key: %w", err)
+	found := false
+	for _, attendee := range event.Attendees {
+		if self, _ := attendee["self"].(bool); self {
+			attendee["responseStatus"] = string(status)
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("calendar %s is not invited to event %s", calendarID, eventID)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	signedJWT, err := token.SignedString(privateKey)
-	if err != nil {
-		return "", fmt.Errorf("signing JWT: %w", err)
+	// Attendees can only be replaced as a whole.
+	patch := map[string]any{"attendees": event.Attendees}
+	if err := c.do(http.MethodPatch, eventURL+"?sendUpdates=all", patch, &event); err != nil {
+		return fmt.Errorf("updating calendar event: %w", err)
 	}
+	return nil
+}
 
-	resp, err := c.httpClient.PostForm(c.credentials.TokenURI, url.Values{
-		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
-		"assertion":  {signedJWT},
-	})
+func (c *GoogleCalendarClient) do(method, endpoint string, payload, v any) error {
+	token, err := c.auth.token()
 	if err != nil {
-		return "", fmt.Errorf("exchanging JWT for token: %w", err)
+		return fmt.Errorf("authenticating with Google: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token exchange returned %d: %s", resp.StatusCode, string(body))
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshalling calendar request: %w", err)
+		}
+		body = bytes.NewReader(data)
 	}
 
-	var tokenResp struct {
-		AccessToken string `json:"access_token"`
-		ExpiresIn   int    `json:"expires_in"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("parsing token response: %w", err)
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("creating calendar request: %w", err)
 	}
-
-	c.accessToken = tokenResp.AccessToken
-	c.tokenExpiry = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
-
-	return c.accessToken, nil
-}
-
-func parseRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
-	block, _ := pem.Decode([]byte(pemStr))
-	if block == nil {
-		return nil, fmt.Errorf("no PEM block found in private key")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
-	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer resp.Body.Close()
 
-	rsaKey, ok := key.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("private key is not RSA")
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Google Calendar API returned %d: %s", resp.StatusCode, string(body))
 	}
 
-	return rsaKey, nil
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parsing calendar response: %w", err)
+	}
+	return nil
 }
 
 // Google Calendar API response types
@@ -182,12 +183,17 @@ type calendarListResponse struct {
 }
 
 type calendarEventItem struct {
-	Summary        string              `json:"summary"`
-	Start          calendarEventTime   `json:"start"`
-	End            calendarEventTime   `json:"end"`
-	ConferenceData *conferenceData     `json:"conferenceData"`
-	HangoutLink    string              `json:"hangoutLink"`
-	Attendees      []calendarAttendee  `json:"attendees"`
+	ID             string               `json:"id"`
+	ICalUID        string               `json:"iCalUID"`
+	Summary        string               `json:"summary"`
+	Description    string               `json:"description"`
+	Location       string               `json:"location"`
+	Start          calendarEventTime    `json:"start"`
+	End            calendarEventTime    `json:"end"`
+	ConferenceData *conferenceData      `json:"conferenceData"`
+	HangoutLink    string               `json:"hangoutLink"`
+	Attendees      []calendarAttendee   `json:"attendees"`
+	Attachments    []calendarAttachment `json:"attachments"`
 }
 
 type calendarEventTime struct {
@@ -206,27 +212,64 @@ type conferenceEntryPoint struct {
 
 type calendarAttendee struct {
 	Email          string `json:"email"`
-	Self           bool   `json:"self"`
+	DisplayName    string `json:"displayName,omitempty"`
+	Self           bool   `json:"self,omitempty"`
+	Organizer      bool   `json:"organizer,omitempty"`
+	Optional       bool   `json:"optional,omitempty"`
 	ResponseStatus string `json:"responseStatus"`
 }
 
+type calendarAttachment struct {
+	FileURL string `json:"fileUrl"`
+	Title   string `json:"title"`
+}
+
 func parseCalendarEvents(items []calendarEventItem) []CalendarEvent {
 	var events []CalendarEvent
 	for _, item := range items {
 		event := CalendarEvent{
+			ID:          item.ID,
+			ICalUID:     item.ICalUID,
 			Title:       item.Summary,
 			MeetingLink: extractMeetingLink(item),
 			RSVP:        extractRSVP(item.Attendees),
+			Description: descriptionText(item.Description),
+			Location:    item.Location,
+		}
+
+		for _, a := range item.Attendees {
+			rsvp, _ := parseRSVP(a.ResponseStatus)
+			event.Attendees = append(event.Attendees, Attendee{
+				Name:      a.DisplayName,
+				Email:     a.Email,
+				RSVP:      rsvp,
+				Organizer: a.Organizer,
+				Optional:  a.Optional,
+			})
+		}
+
+		for _, a := range item.Attachments {
+			event.Attachments = append(event.Attachments, Attachment{Title: a.Title, URL: a.FileURL})
 		}
 
 		if item.Start.Date != "" {
-			// All-day event
+			// All-day event, from local midnight to the midnight after its
+			// last day.
 			event.AllDay = true
+			if d, err := time.ParseInLocation(time.DateOnly, item.Start.Date, time.Local); err == nil {
+				event.StartTime = d
+			}
+			if d, err := time.ParseInLocation(time.DateOnly, item.End.Date, time.Local); err == nil {
+				event.EndTime = d
+			}
 		} else if item.Start.DateTime != "" {
 			t, err := time.Parse(time.RFC3339, item.Start.DateTime)
 			if err == nil {
 				event.StartTime = t
 			}
+			if end, err := time.Parse(time.RFC3339, item.End.DateTime); err == nil {
+				event.EndTime = end
+			}
 		}
 
 		events = append(events, event)
@@ -255,15 +298,8 @@ func extractMeetingLink(item calendarEventItem) string {
 func extractRSVP(attendees []calendarAttendee) RSVPStatus {
 	for _, a := range attendees {
 		if a.Self {
-			switch a.ResponseStatus {
-			case "accepted":
-				return RSVPAccepted
-			case "declined":
-				return RSVPDeclined
-			case "needsAction":
-				return RSVPNeedsAction
-			case "tentative":
-				return RSVPTentative
+			if rsvp, ok := parseRSVP(a.ResponseStatus); ok {
+				return rsvp
 			}
 		}
 	}
@@ -271,6 +307,33 @@ func extractRSVP(attendees []calendarAttendee) RSVPStatus {
 	return RSVPAccepted
 }
 
+func parseRSVP(responseStatus string) (RSVPStatus, bool) {
+	switch responseStatus {
+	case "accepted":
+		return RSVPAccepted, true
+	case "declined":
+		return RSVPDeclined, true
+	case "needsAction":
+		return RSVPNeedsAction, true
+	case "tentative":
+		return RSVPTentative, true
+	}
+	return RSVPNeedsAction, false
+}
+
+var (
+	htmlLineBreak = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</div>`)
+	htmlTag       = regexp.MustCompile(`<[^>]+>`)
+)
+
+// descriptionText converts the HTML Google Calendar stores in event
+// descriptions to plain text.
+func descriptionText(description string) string {
+	text := htmlLineBreak.ReplaceAllString(description, "\n")
+	text = htmlTag.ReplaceAllString(text, "")
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
 // ExtractMeetingLinkFromDescription looks for URLs in event descriptions
 // that look like meeting links (Zoom, Teams, etc.).
 func extractMeetingLinkFromDescription(description string) string {
@@ -1,8 +1,15 @@
 package platform
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
 )
 
 func TestParseCalendarEvents_TimedEvent(t *testing.T) {
@@ -41,6 +48,7 @@ func TestParseCalendarEvents_AllDayEvent(t *testing.T) {
 		{
 			Summary: "Company Holiday",
 			Start:   calendarEventTime{Date: "2026-02-06"},
+			End:     calendarEventTime{Date: "2026-02-07"},
 		},
 	}
 
@@ -53,8 +61,11 @@ func TestParseCalendarEvents_AllDayEvent(t *testing.T) {
 	if !e.AllDay {
 		t.Error("expected AllDay = true")
 	}
-	if e.StartTime != (time.Time{}) {
-		t.Errorf("expected zero StartTime for all-day event, got %v", e.StartTime)
+	if want := time.Date(2026, 2, 6, 0, 0, 0, 0, time.Local); !e.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want local midnight %v", e.StartTime, want)
+	}
+	if want := time.Date(2026, 2, 7, 0, 0, 0, 0, time.Local); !e.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want the exclusive end %v", e.EndTime, want)
 	}
 }
 
@@ -152,3 +163,168 @@ func TestExtractRSVP_TentativeStatus(t *testing.T) {
 		t.Errorf("RSVP = %q, want %q", rsvp, RSVPTentative)
 	}
 }
+
+func TestParseCalendarEvents_EndTime(t *testing.T) {
+	items := []calendarEventItem{
+		{
+			Summary: "Planning",
+			Start:   calendarEventTime{DateTime: "2026-02-06T10:00:00+01:00"},
+			End:     calendarEventTime{DateTime: "2026-02-06T11:30:00+01:00"},
+		},
+	}
+
+	events := parseCalendarEvents(items)
+	if got := events[0].EndTime.Sub(events[0].StartTime); got != 90*time.Minute {
+		t.Errorf("duration = %v, want 1h30m", got)
+	}
+}
+
+func TestParseCalendarEvents_Details(t *testing.T) {
+	items := []calendarEventItem{
+		{
+			ID:          "evt-1",
+			Summary:     "Design review",
+			Description: "<b>Agenda</b><br>1. API &amp; schema<br/>2. Rollout",
+			Location:    "Room 4",
+			Start:       calendarEventTime{DateTime: "2026-02-06T14:00:00+01:00"},
+			Attendees: []calendarAttendee{
+				{Email: "ana@example.com", DisplayName: "Ana", Organizer: true, ResponseStatus: "accepted"},
+				{Email: "bo@example.com", Optional: true, ResponseStatus: "tentative"},
+			},
+			Attachments: []calendarAttachment{
+				{Title: "Spec", FileURL: "https://drive.google.com/spec"},
+			},
+		},
+	}
+
+	e := parseCalendarEvents(items)[0]
+	if e.ID != "evt-1" || e.Location != "Room 4" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+	if e.Description != "Agenda\n1. API & schema\n2. Rollout" {
+		t.Errorf("description = %q, want plain-text agenda", e.Description)
+	}
+	if len(e.Attendees) != 2 {
+		t.Fatalf("got %d attendees, want 2", len(e.Attendees))
+	}
+	if !e.Attendees[0].Organizer || e.Attendees[0].Name != "Ana" || e.Attendees[0].RSVP != RSVPAccepted {
+		t.Errorf("unexpected organizer: %+v", e.Attendees[0])
+	}
+	if !e.Attendees[1].Optional || e.Attendees[1].RSVP != RSVPTentative {
+		t.Errorf("unexpected optional attendee: %+v", e.Attendees[1])
+	}
+	if len(e.Attachments) != 1 || e.Attachments[0].URL != "https://drive.google.com/spec" {
+		t.Errorf("unexpected attachments: %+v", e.Attachments)
+	}
+}
+
+func TestParseCalendarEvents_ICalUID(t *testing.T) {
+	events := parseCalendarEvents([]calendarEventItem{{ID: "abc123", ICalUID: "design-review-42@example.com"}})
+	if events[0].ICalUID != "design-review-42@example.com" {
+		t.Errorf("ICalUID = %q, want the iCalUID field", events[0].ICalUID)
+	}
+}
+
+func TestGoogleCalendarClient_RespondToEvent(t *testing.T) {
+	claims := jwt.MapClaims{}
+	var patched, sendUpdates string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			r.ParseForm()
+			jwt.NewParser().ParseUnverified(r.PostForm.Get("assertion"), claims)
+			w.Write([]byte(`{"access_token": "calendar-token", "expires_in": 3600}`))
+			return
+		case r.Header.Get("Authorization") != "Bearer calendar-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.URL.Path != "/calendars/me@example.com/events/abc123":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patched = string(body)
+			sendUpdates = r.URL.Query().Get("sendUpdates")
+		}
+		w.Write([]byte(`{"id": "abc123", "attendees": [
+			{"email": "ada@example.com", "organizer": true, "responseStatus": "accepted"},
+			{"email": "me@example.com", "self": true, "responseStatus": "needsAction"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGoogleCalendarResponder(testServiceAccount(t, server.URL+"/token"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+	client.auth.httpClient = server.Client()
+
+	if err := client.RespondToEvent("me@example.com", "abc123", RSVPTentative); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if claims["scope"] != calendarEventsScope {
+		t.Errorf("JWT scope = %v, want calendar.events", claims["scope"])
+	}
+	if sendUpdates != "all" {
+		t.Errorf("sendUpdates = %q, want organizer notified", sendUpdates)
+	}
+	var body struct {
+		Attendees []map[string]any `json:"attendees"`
+	}
+	if err := json.Unmarshal([]byte(patched), &body); err != nil {
+		t.Fatalf("patch body %q: %v", patched, err)
+	}
+	if len(body.Attendees) != 2 || body.Attendees[0]["responseStatus"] != "accepted" || body.Attendees[1]["responseStatus"] != "tentative" {
+		t.Errorf("attendees = %+v, want only own response changed", body.Attendees)
+	}
+}
+
+func TestGoogleCalendarClient_RespondToEvent_KeepsAttendeeFields(t *testing.T) {
+	var patched string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			w.Write([]byte(`{"access_token": "calendar-token", "expires_in": 3600}`))
+			return
+		case r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patched = string(body)
+		}
+		w.Write([]byte(`{"id": "abc123", "attendees": [
+			{"id": "a1", "email": "ada@example.com", "displayName": "Ada", "organizer": true, "responseStatus": "accepted", "comment": "Running late", "additionalGuests": 2},
+			{"email": "room@resource.calendar.google.com", "resource": true, "responseStatus": "accepted"},
+			{"email": "me@example.com", "self": true, "responseStatus": "needsAction", "comment": "Will join remotely"}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewGoogleCalendarResponder(testServiceAccount(t, server.URL+"/token"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+	client.auth.httpClient = server.Client()
+
+	if err := client.RespondToEvent("me@example.com", "abc123", RSVPAccepted); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body struct {
+		Attendees []map[string]any `json:"attendees"`
+	}
+	if err := json.Unmarshal([]byte(patched), &body); err != nil {
+		t.Fatalf("patch body %q: %v", patched, err)
+	}
+	want := []map[string]any{
+		{"id": "a1", "email": "ada@example.com", "displayName": "Ada", "organizer": true, "responseStatus": "accepted", "comment": "Running late", "additionalGuests": 2.0},
+		{"email": "room@resource.calendar.google.com", "resource": true, "responseStatus": "accepted"},
+		{"email": "me@example.com", "self": true, "responseStatus": "accepted", "comment": "Will join remotely"},
+	}
+	if !reflect.DeepEqual(body.Attendees, want) {
+		t.Errorf("attendees = %v, want %v", body.Attendees, want)
+	}
+}
@@ -0,0 +1,116 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GitHubIssuesClient creates tasks as issues in a GitHub repository.
+// GitHub issues have no due dates or priorities, so these are carried as
+// a line in the issue body and a "priority: pN" label respectively.
+type GitHubIssuesClient struct {
+	token      string
+	repo       string // owner/name
+	labels     []string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitHubIssuesClient creates a client that opens issues in repo ("owner/name"),
+// applying labels to every issue it creates.
+func NewGitHubIssuesClient(token, repo string, labels []string) *GitHubIssuesClient {
+	return &GitHubIssuesClient{
+		token:      token,
+		repo:       repo,
+		labels:     labels,
+		baseURL:    "https://api.github.com",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateTask opens an issue and returns its number.
+func (c *GitHubIssuesClient) CreateTask(task TodoistTask) (string, error) {
+	payload := githubCreateIssueRequest{
+		Title:  task.Title,
+		Body:   githubIssueBody(task),
+		Labels: append(append([]string{}, c.labels...), task.Labels...),
+	}
+	if task.Priority > 1 {
+		payload.Labels = append(payload.Labels, fmt.Sprintf("priority: p%d", 5-task.Priority))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("marshalling issue: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/repos/%s/issues", c.baseURL, c.repo), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating github request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("creating github issue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitHub API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var created githubIssue
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", fmt.Errorf("parsing github response: %w", err)
+	}
+
+	return strconv.Itoa(created.Number), nil
+}
+
+// githubIssueBody combines the description with the fields GitHub has no
+// native place for: due date, recurrence and parent issue.
+func githubIssueBody(task TodoistTask) string {
+	var parts []string
+	if task.Description != "" {
+		parts = append(parts, task.Description)
+	}
+
+	var meta []string
+	if task.DueDateTime != nil {
+		meta = append(meta, "**Due:** "+task.DueDateTime.Local().Format("2006-01-02 15:04 MST"))
+	} else if task.DueDate != nil {
+		meta = append(meta, "**Due:** "+task.DueDate.Format(time.DateOnly))
+	}
+	if task.DueString != "" && task.DueDateTime == nil {
+		meta = append(meta, "**Repeats:** "+task.DueString)
+	}
+	if task.ParentID != "" {
+		meta = append(meta, "Part of #"+task.ParentID)
+	}
+	if len(meta) > 0 {
+		parts = append(parts, strings.Join(meta, "\n"))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+type githubCreateIssueRequest struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type githubIssue struct {
+	Number int `json:"number"`
+}
@@ -0,0 +1,83 @@
+package platform
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGitHubIssuesClient_CreateTask(t *testing.T) {
+	var received githubCreateIssueRequest
+	var receivedPath, receivedAuth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		receivedAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 42, "title": "Standup"}`))
+	}))
+	defer server.Close()
+
+	client := NewGitHubIssuesClient("gh-token", "sam/tasks", []string{"sam"})
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+
+	due := time.Date(2026, 2, 6, 10, 0, 0, 0, time.Local)
+	id, err := client.CreateTask(TodoistTask{
+		Title:       "Standup",
+		Description: "https://meet.google.com/abc",
+		DueDateTime: &due,
+		Priority:    4,
+		ParentID:    "7",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "42" {
+		t.Errorf("id = %q, want 42", id)
+	}
+	if receivedPath != "/repos/sam/tasks/issues" {
+		t.Errorf("path = %q, want /repos/sam/tasks/issues", receivedPath)
+	}
+	if receivedAuth != "Bearer gh-token" {
+		t.Errorf("auth = %q, want Bearer gh-token", receivedAuth)
+	}
+	if received.Title != "Standup" {
+		t.Errorf("title = %q, want Standup", received.Title)
+	}
+	if strings.Join(received.Labels, ",") != "sam,priority: p1" {
+		t.Errorf("labels = %v, want [sam priority: p1]", received.Labels)
+	}
+	for _, want := range []string{"https://meet.google.com/abc", "**Due:** 2026-02-06 10:00", "Part of #7"} {
+		if !strings.Contains(received.Body, want) {
+			t.Errorf("body missing %q, got:\n%s", want, received.Body)
+		}
+	}
+}
+
+func TestGitHubIssuesClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	client := NewGitHubIssuesClient("gh-token", "sam/missing", nil)
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+
+	_, err := client.CreateTask(TodoistTask{Title: "Test"})
+	if err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+	if !strings.Contains(err.Error(), "404") {
+		t.Errorf("error should mention status code, got: %v", err)
+	}
+}
@@ -0,0 +1,551 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	gmailAPIBase     = "https://gmail.googleapis.com/gmail/v1/users/me"
+	gmailReadScope   = "https://www.googleapis.com/auth/gmail.readonly"
+	gmailModifyScope = "https://www.googleapis.com/auth/gmail.modify"
+
+	// defaultMaxThreads bounds how many threads a single query fetches,
+	// since each thread costs an extra request.
+	defaultMaxThreads = 200
+)
+
+// gmailMetadataHeaders are the only headers requested for each message.
+var gmailMetadataHeaders = []string{"From", "To", "Cc", "Subject", "Date", "List-Unsubscribe", "List-Id"}
+
+// GmailClient reads a mailbox through the Gmail API using a service account
+// with domain-wide delegation to impersonate the mailbox owner.
+type GmailClient struct {
+	auth       *googleAuth
+	baseURL    string
+	httpClient *http.Client
+	maxThreads int
+	labelNames map[string]string // label ID → name, loaded on first use
+}
+
+// NewGmailClient creates a client from a service account JSON key that reads
+// the mailbox of user.
+func NewGmailClient(credentialsJSON, user string) (*GmailClient, error) {
+	return newGmailClient(credentialsJSON, user, gmailReadScope)
+}
+
+// NewGmailModifier is like NewGmailClient but requests the gmail.modify
+// scope, which the service account must be granted to change threads.
+func NewGmailModifier(credentialsJSON, user string) (*GmailClient, error) {
+	return newGmailClient(credentialsJSON, user, gmailModifyScope)
+}
+
+func newGmailClient(credentialsJSON, user, scope string) (*GmailClient, error) {
+	if user == "" {
+		return nil, fmt.Errorf("gmail user to impersonate is required")
+	}
+
+	key, err := parseServiceAccountKey(credentialsJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	return &GmailClient{
+		auth:       newGoogleAuth(key, scope, user, httpClient),
+		baseURL:    gmailAPIBase,
+		httpClient: httpClient,
+		maxThreads: defaultMaxThreads,
+	}, nil
+}
+
+func (c *GmailClient) InboxThreads(query string) ([]EmailThread, error) {
+	return c.Threads(strings.TrimSpace("in:inbox " + query))
+}
+
+func (c *GmailClient) Threads(query string) ([]EmailThread, error) {
+	if c.labelNames == nil {
+		if err := c.loadLabels(); err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := c.listIDs("/threads", query)
+	if err != nil {
+		return nil, fmt.Errorf("listing gmail threads: %w", err)
+	}
+
+	threads := make([]EmailThread, 0, len(ids))
+	for _, id := range ids {
+		var item gmailThread
+		params := url.Values{"format": {"metadata"}, "metadataHeaders": gmailMetadataHeaders}
+		if err := c.get("/threads/"+url.PathEscape(id), params, &item); err != nil {
+			return nil, fmt.Errorf("fetching gmail thread %s: %w", id, err)
+		}
+		threads = append(threads, c.parseThread(item))
+	}
+
+	return threads, nil
+}
+
+// Invitations reads the invitations in inbox messages with an .ics part.
+// Gmail indexes text/calendar parts as attachments named invite.ics.
+func (c *GmailClient) Invitations(query string) ([]EmailInvitation, error) {
+	ids, err := c.listIDs("/messages", strings.TrimSpace("in:inbox filename:ics "+query))
+	if err != nil {
+		return nil, fmt.Errorf("listing gmail messages: %w", err)
+	}
+
+	var invitations []EmailInvitation
+	for _, id := range ids {
+		var msg gmailMessage
+		if err := c.get("/messages/"+url.PathEscape(id), url.Values{"format": {"full"}}, &msg); err != nil {
+			return nil, fmt.Errorf("fetching gmail message %s: %w", id, err)
+		}
+
+		for _, part := range msg.Payload.calendarParts() {
+			data, err := c.partData(msg.ID, part)
+			if err != nil {
+				return nil, err
+			}
+			if inv, ok := parseInvitation(data); ok {
+				invitations = append(invitations, EmailInvitation{
+					Invitation: inv,
+					ThreadID:   msg.ThreadID,
+					Received:   parseInternalDate(msg.InternalDate),
+				})
+			}
+		}
+	}
+	return invitations, nil
+}
+
+// MessageBody reads the first HTML and plain text parts of a message that
+// are not attachments.
+func (c *GmailClient) MessageBody(messageID string) (MessageBody, error) {
+	var msg gmailMessage
+	if err := c.get("/messages/"+url.PathEscape(messageID), url.Values{"format": {"full"}}, &msg); err != nil {
+		return MessageBody{}, fmt.Errorf("fetching gmail message %s: %w", messageID, err)
+	}
+
+	var body MessageBody
+	for _, part := range msg.Payload.bodyParts() {
+		target := &body.Text
+		if part.MimeType == "text/html" {
+			target = &body.HTML
+		}
+		if *target != "" {
+			continue
+		}
+		data, err := c.partData(msg.ID, part)
+		if err != nil {
+			return MessageBody{}, err
+		}
+		*target = data
+	}
+	return body, nil
+}
+
+// partData returns the decoded content of a message part, fetching it
+// separately when Gmail stores it as an attachment.
+func (c *GmailClient) partData(messageID string, part gmailPayload) (string, error) {
+	data := part.Body.Data
+	if part.Body.AttachmentID != "" {
+		var attachment gmailPartBody
+		path := "/messages/" + url.PathEscape(messageID) + "/attachments/" + url.PathEscape(part.Body.AttachmentID)
+		if err := c.get(path, nil, &attachment); err != nil {
+			return "", fmt.Errorf("fetching gmail attachment of message %s: %w", messageID, err)
+		}
+		data = attachment.Data
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(data)
+	if err != nil {
+		// Gmail sometimes omits the padding.
+		if decoded, err = base64.RawURLEncoding.DecodeString(data); err != nil {
+			return "", fmt.Errorf("decoding gmail message %s part: %w", messageID, err)
+		}
+	}
+	return string(decoded), nil
+}
+
+// RemoveLabel removes a label, by name, from every message in the thread.
+// System labels such as STARRED and INBOX are removed by their ID.
+func (c *GmailClient) RemoveLabel(threadID, label string) error {
+	return c.ModifyLabels(threadID, nil, []string{label})
+}
+
+// ModifyLabels adds and removes labels, by name, on every message in the
+// thread in a single request. Labels to add that do not exist yet are
+// created; labels to remove must exist.
+func (c *GmailClient) ModifyLabels(threadID string, add, remove []string) error {
+	if c.labelNames == nil {
+		if err := c.loadLabels(); err != nil {
+			return err
+		}
+	}
+
+	payload := map[string][]string{}
+	for _, label := range add {
+		labelID, ok := c.labelID(label)
+		if !ok {
+			var err error
+			if labelID, err = c.createLabel(label); err != nil {
+				return err
+			}
+		}
+		payload["addLabelIds"] = append(payload["addLabelIds"], labelID)
+	}
+	for _, label := range remove {
+		labelID, ok := c.labelID(label)
+		if !ok {
+			return fmt.Errorf("gmail label %q not found", label)
+		}
+		payload["removeLabelIds"] = append(payload["removeLabelIds"], labelID)
+	}
+
+	if err := c.do(http.MethodPost, "/threads/"+url.PathEscape(threadID)+"/modify", nil, payload, &gmailThread{}); err != nil {
+		return fmt.Errorf("modifying labels of gmail thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+func (c *GmailClient) createLabel(name string) (string, error) {
+	var label struct {
+		ID string `json:"id"`
+	}
+	payload := map[string]string{"name": name, "labelListVisibility": "labelShow", "messageListVisibility": "show"}
+	if err := c.do(http.MethodPost, "/labels", nil, payload, &label); err != nil {
+		return "", fmt.Errorf("creating gmail label %q: %w", name, err)
+	}
+	c.labelNames[label.ID] = name
+	return label.ID, nil
+}
+
+// labelID resolves a label name, case-insensitively, to its ID.
+func (c *GmailClient) labelID(name string) (string, bool) {
+	for id, n := range c.labelNames {
+		if strings.EqualFold(n, name) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// listIDs pages through the threads or messages, depending on path, that
+// match query, up to maxThreads.
+func (c *GmailClient) listIDs(path, query string) ([]string, error) {
+	var ids []string
+	pageToken := ""
+	for len(ids) < c.maxThreads {
+		params := url.Values{
+			"q":          {query},
+			"maxResults": {strconv.Itoa(min(100, c.maxThreads-len(ids)))},
+		}
+		if pageToken != "" {
+			params.Set("pageToken", pageToken)
+		}
+
+		var page gmailList
+		if err := c.get(path, params, &page); err != nil {
+			return nil, err
+		}
+		for _, item := range append(page.Threads, page.Messages...) {
+			ids = append(ids, item.ID)
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		pageToken = page.NextPageToken
+	}
+	return ids, nil
+}
+
+func (c *GmailClient) loadLabels() error {
+	var result struct {
+		Labels []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := c.get("/labels", nil, &result); err != nil {
+		return fmt.Errorf("listing gmail labels: %w", err)
+	}
+
+	c.labelNames = make(map[string]string, len(result.Labels))
+	for _, l := range result.Labels {
+		c.labelNames[l.ID] = l.Name
+	}
+	return nil
+}
+
+func (c *GmailClient) get(path string, params url.Values, v any) error {
+	return c.do(http.MethodGet, path, params, nil, v)
+}
+
+func (c *GmailClient) do(method, path string, params url.Values, payload, v any) error {
+	token, err := c.auth.token()
+	if err != nil {
+		return fmt.Errorf("authenticating with Google: %w", err)
+	}
+
+	endpoint := c.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshalling gmail request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("creating gmail request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Gmail API returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parsing gmail response: %w", err)
+	}
+	return nil
+}
+
+func (c *GmailClient) parseThread(item gmailThread) EmailThread {
+	thread := EmailThread{ID: item.ID}
+	labels := make(map[string]bool)
+
+	for _, m := range item.Messages {
+		msg := EmailMessage{
+			ID:      m.ID,
+			Snippet: html.UnescapeString(m.Snippet),
+			Subject: m.Payload.header("Subject"),
+			From:    parseEmailAddress(m.Payload.header("From")),
+			To:      parseEmailAddressList(m.Payload.header("To")),
+			Cc:      parseEmailAddressList(m.Payload.header("Cc")),
+			Date:    parseInternalDate(m.InternalDate),
+		}
+		for _, id := range m.LabelIDs {
+			name := c.labelName(id)
+			msg.Labels = append(msg.Labels, name)
+			labels[name] = true
+			switch id {
+			case "UNREAD":
+				thread.Unread = true
+			case "STARRED":
+				thread.Starred = true
+			}
+		}
+		if unsubscribe := parseListUnsubscribe(m.Payload.header("List-Unsubscribe")); len(unsubscribe) > 0 {
+			thread.ListUnsubscribe = unsubscribe
+		}
+		if listID := parseListID(m.Payload.header("List-Id")); listID != "" {
+			thread.ListID = listID
+		}
+		thread.Messages = append(thread.Messages, msg)
+	}
+
+	sort.SliceStable(thread.Messages, func(i, j int) bool {
+		return thread.Messages[i].Date.Before(thread.Messages[j].Date)
+	})
+
+	if n := len(thread.Messages); n > 0 {
+		first, last := thread.Messages[0], thread.Messages[n-1]
+		thread.Subject = first.Subject
+		thread.From = first.From
+		thread.Snippet = last.Snippet
+		thread.Date = last.Date
+	}
+
+	for name := range labels {
+		thread.Labels = append(thread.Labels, name)
+	}
+	sort.Strings(thread.Labels)
+
+	return thread
+}
+
+// labelName resolves a label ID to its display name. System labels such as
+// INBOX use their ID as name.
+func (c *GmailClient) labelName(id string) string {
+	if name, ok := c.labelNames[id]; ok {
+		return name
+	}
+	return id
+}
+
+// parseEmailAddress parses a From-style header, falling back to the raw
+// value as the address when it is not RFC 5322 compliant.
+func parseEmailAddress(value string) EmailAddress {
+	if value == "" {
+		return EmailAddress{}
+	}
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return EmailAddress{Email: strings.TrimSpace(value)}
+	}
+	return EmailAddress{Name: addr.Name, Email: strings.ToLower(addr.Address)}
+}
+
+func parseEmailAddressList(value string) []EmailAddress {
+	if value == "" {
+		return nil
+	}
+	list, err := mail.ParseAddressList(value)
+	if err != nil {
+		var result []EmailAddress
+		for _, part := range strings.Split(value, ",") {
+			result = append(result, parseEmailAddress(part))
+		}
+		return result
+	}
+
+	result := make([]EmailAddress, len(list))
+	for i, addr := range list {
+		result[i] = EmailAddress{Name: addr.Name, Email: strings.ToLower(addr.Address)}
+	}
+	return result
+}
+
+// parseListUnsubscribe extracts the <...> targets from a List-Unsubscribe
+// header (RFC 2369), e.g. "<mailto:u@example.com>, <https://example.com/u>".
+func parseListUnsubscribe(value string) []string {
+	var targets []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "<") && strings.HasSuffix(part, ">") {
+			targets = append(targets, part[1:len(part)-1])
+		}
+	}
+	return targets
+}
+
+// parseListID extracts the identifier from a List-Id header (RFC 2919), e.g.
+// "Go Weekly <weekly.golangweekly.com>".
+func parseListID(value string) string {
+	if start, end := strings.LastIndex(value, "<"), strings.LastIndex(value, ">"); start >= 0 && end > start {
+		return strings.TrimSpace(value[start+1 : end])
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseInternalDate converts Gmail's internalDate (epoch milliseconds).
+func parseInternalDate(ms string) time.Time {
+	n, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(n)
+}
+
+// Gmail API response types
+
+// gmailList is a page of threads.list or messages.list results.
+type gmailList struct {
+	Threads       []gmailListItem `json:"threads"`
+	Messages      []gmailListItem `json:"messages"`
+	NextPageToken string          `json:"nextPageToken"`
+}
+
+type gmailListItem struct {
+	ID string `json:"id"`
+}
+
+type gmailThread struct {
+	ID       string         `json:"id"`
+	Messages []gmailMessage `json:"messages"`
+}
+
+type gmailMessage struct {
+	ID           string       `json:"id"`
+	ThreadID     string       `json:"threadId"`
+	LabelIDs     []string     `json:"labelIds"`
+	Snippet      string       `json:"snippet"`
+	InternalDate string       `json:"internalDate"`
+	Payload      gmailPayload `json:"payload"`
+}
+
+// gmailPayload is a MIME part. Metadata requests only fill in Headers.
+type gmailPayload struct {
+	MimeType string         `json:"mimeType"`
+	Filename string         `json:"filename"`
+	Headers  []gmailHeader  `json:"headers"`
+	Body     gmailPartBody  `json:"body"`
+	Parts    []gmailPayload `json:"parts"`
+}
+
+type gmailPartBody struct {
+	AttachmentID string `json:"attachmentId"`
+	Data         string `json:"data"` // base64url
+}
+
+type gmailHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// calendarParts returns the text/calendar and .ics parts of the message.
+func (p gmailPayload) calendarParts() []gmailPayload {
+	var parts []gmailPayload
+	mimeType := strings.ToLower(p.MimeType)
+	if mimeType == "text/calendar" || mimeType == "application/ics" || strings.HasSuffix(strings.ToLower(p.Filename), ".ics") {
+		parts = append(parts, p)
+	}
+	for _, child := range p.Parts {
+		parts = append(parts, child.calendarParts()...)
+	}
+	return parts
+}
+
+// bodyParts returns the text/plain and text/html parts of the message that
+// are not attachments, in order.
+func (p gmailPayload) bodyParts() []gmailPayload {
+	var parts []gmailPayload
+	if (p.MimeType == "text/plain" || p.MimeType == "text/html") && p.Filename == "" {
+		parts = append(parts, p)
+	}
+	for _, child := range p.Parts {
+		parts = append(parts, child.bodyParts()...)
+	}
+	return parts
+}
+
+// header returns the first header with the given name, case-insensitively.
+func (p gmailPayload) header(name string) string {
+	for _, h := range p.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
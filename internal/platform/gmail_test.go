@@ -0,0 +1,349 @@
+package platform
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testServiceAccount returns service account credentials with a freshly
+// generated key whose token endpoint is tokenURL.
+func testServiceAccount(t *testing.T, tokenURL string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, _ := json.Marshal(ServiceAccountKey{
+		ClientEmail: "sam@project.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:    tokenURL,
+	})
+	return string(creds)
+}
+
+// newRecordedGmailServer serves the recorded Gmail API responses in
+// testdata/gmail, plus a token endpoint that records the JWT claims.
+func newRecordedGmailServer(t *testing.T, claims jwt.MapClaims, queries *[]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var file string
+		switch {
+		case r.URL.Path == "/token":
+			r.ParseForm()
+			jwt.NewParser().ParseUnverified(r.PostForm.Get("assertion"), claims)
+			w.Write([]byte(`{"access_token": "gmail-token", "expires_in": 3600}`))
+			return
+		case r.Header.Get("Authorization") != "Bearer gmail-token":
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.URL.Path == "/labels":
+			file = "labels.json"
+		case r.URL.Path == "/threads":
+			*queries = append(*queries, r.URL.Query().Get("q"))
+			file = "threads_page1.json"
+			if r.URL.Query().Get("pageToken") != "" {
+				file = "threads_page2.json"
+			}
+		case strings.HasPrefix(r.URL.Path, "/threads/"):
+			if r.URL.Query().Get("format") != "metadata" {
+				t.Errorf("thread format = %q, want metadata", r.URL.Query().Get("format"))
+			}
+			file = "thread_" + strings.TrimPrefix(r.URL.Path, "/threads/") + ".json"
+		case r.URL.Path == "/messages":
+			*queries = append(*queries, r.URL.Query().Get("q"))
+			file = "messages_invites.json"
+		case strings.Contains(r.URL.Path, "/attachments/"):
+			file = "attachment_" + path.Base(r.URL.Path) + ".json"
+		case strings.HasPrefix(r.URL.Path, "/messages/"):
+			file = "message_" + strings.TrimPrefix(r.URL.Path, "/messages/") + ".json"
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "gmail", file))
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}))
+}
+
+func newTestGmailClient(t *testing.T, server *httptest.Server) *GmailClient {
+	t.Helper()
+	client, err := NewGmailClient(testServiceAccount(t, server.URL+"/token"), "me@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+	client.auth.httpClient = server.Client()
+	return client
+}
+
+func TestGmailClient_InboxThreads(t *testing.T) {
+	claims := jwt.MapClaims{}
+	var queries []string
+	server := newRecordedGmailServer(t, claims, &queries)
+	defer server.Close()
+
+	threads, err := newTestGmailClient(t, server).InboxThreads("newer_than:7d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if claims["scope"] != gmailReadScope || claims["sub"] != "me@example.com" {
+		t.Errorf("JWT scope/sub = %v/%v, want gmail.readonly impersonating me@example.com", claims["scope"], claims["sub"])
+	}
+	if len(queries) != 2 || queries[0] != "in:inbox newer_than:7d" {
+		t.Errorf("queries = %q, want two pages of \"in:inbox newer_than:7d\"", queries)
+	}
+	if len(threads) != 3 {
+		t.Fatalf("got %d threads, want 3", len(threads))
+	}
+
+	budget := threads[0]
+	if budget.Subject != "Q1 budget" {
+		t.Errorf("subject = %q, want subject of the first message", budget.Subject)
+	}
+	if budget.From != (EmailAddress{Name: "Ada Lovelace", Email: "ada@example.com"}) {
+		t.Errorf("from = %+v, want Ada Lovelace", budget.From)
+	}
+	if budget.Snippet != "Sounds good – I'll send it over tonight." {
+		t.Errorf("snippet = %q, want unescaped latest snippet", budget.Snippet)
+	}
+	if want := time.Date(2026, 2, 10, 10, 0, 0, 0, time.UTC); !budget.Date.Equal(want) {
+		t.Errorf("date = %v, want %v", budget.Date, want)
+	}
+	if !budget.Unread || !budget.Starred {
+		t.Errorf("unread/starred = %v/%v, want true/true", budget.Unread, budget.Starred)
+	}
+	if strings.Join(budget.Labels, ",") != "Action,INBOX,STARRED,UNREAD" {
+		t.Errorf("labels = %v, want names including user label Action", budget.Labels)
+	}
+	if len(budget.Messages) != 2 || budget.Messages[1].From.Email != "grace@example.com" {
+		t.Fatalf("messages = %+v, want 2 in chronological order", budget.Messages)
+	}
+	if cc := budget.Messages[0].Cc; len(cc) != 2 || cc[1].Email != "team@example.com" {
+		t.Errorf("cc = %+v, want grace and team", cc)
+	}
+
+	newsletter := threads[1]
+	want := []string{"mailto:unsub@golangweekly.com?subject=unsubscribe", "https://golangweekly.com/unsubscribe/abc"}
+	if strings.Join(newsletter.ListUnsubscribe, " ") != strings.Join(want, " ") {
+		t.Errorf("list-unsubscribe = %v, want %v", newsletter.ListUnsubscribe, want)
+	}
+	if newsletter.ListID != "weekly.golangweekly.com" {
+		t.Errorf("list-id = %q, want weekly.golangweekly.com", newsletter.ListID)
+	}
+
+	if threads[2].From.Email != "notifications@github.com" || threads[2].Unread {
+		t.Errorf("notification thread = %+v, want read mail from github", threads[2])
+	}
+}
+
+func TestGmailClient_MaxThreads(t *testing.T) {
+	var queries []string
+	server := newRecordedGmailServer(t, jwt.MapClaims{}, &queries)
+	defer server.Close()
+
+	client := newTestGmailClient(t, server)
+	client.maxThreads = 2
+
+	threads, err := client.Threads("in:sent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(queries) != 1 || len(threads) != 2 {
+		t.Errorf("requests/threads = %d/%d, want 1/2 when capped", len(queries), len(threads))
+	}
+}
+
+func TestGmailClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.Write([]byte(`{"access_token": "gmail-token", "expires_in": 3600}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"message": "Delegation denied"}}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestGmailClient(t, server).InboxThreads("")
+	if err == nil {
+		t.Fatal("expected error for 403 response")
+	}
+	if !strings.Contains(err.Error(), "403") {
+		t.Errorf("error should mention status code, got: %v", err)
+	}
+}
+
+func TestGmailClient_RemoveLabel(t *testing.T) {
+	var modifyPath string
+	var modify struct {
+		RemoveLabelIDs []string `json:"removeLabelIds"`
+	}
+	claims := jwt.MapClaims{}
+	var queries []string
+	recorded := newRecordedGmailServer(t, claims, &queries)
+	defer recorded.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/modify") {
+			modifyPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&modify)
+			w.Write([]byte(`{"id": "18d1a2b3c4d5e6f7"}`))
+			return
+		}
+		recorded.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := NewGmailModifier(testServiceAccount(t, server.URL+"/token"), "me@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+	client.auth.httpClient = server.Client()
+
+	if err := client.RemoveLabel("18d1a2b3c4d5e6f7", "action"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if claims["scope"] != gmailModifyScope {
+		t.Errorf("JWT scope = %v, want gmail.modify", claims["scope"])
+	}
+	if modifyPath != "/threads/18d1a2b3c4d5e6f7/modify" {
+		t.Errorf("path = %q, want thread modify endpoint", modifyPath)
+	}
+	if strings.Join(modify.RemoveLabelIDs, ",") != "Label_3" {
+		t.Errorf("removeLabelIds = %v, want the ID of label Action", modify.RemoveLabelIDs)
+	}
+
+	if err := client.RemoveLabel("18d1a2b3c4d5e6f7", "Missing"); err == nil {
+		t.Error("expected error for unknown label")
+	}
+}
+
+func TestGmailClient_ModifyLabels(t *testing.T) {
+	var created map[string]string
+	var modify struct {
+		AddLabelIDs    []string `json:"addLabelIds"`
+		RemoveLabelIDs []string `json:"removeLabelIds"`
+	}
+	var queries []string
+	recorded := newRecordedGmailServer(t, jwt.MapClaims{}, &queries)
+	defer recorded.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/labels":
+			json.NewDecoder(r.Body).Decode(&created)
+			w.Write([]byte(`{"id": "Label_9", "name": "Receipts"}`))
+		case strings.HasSuffix(r.URL.Path, "/modify"):
+			json.NewDecoder(r.Body).Decode(&modify)
+			w.Write([]byte(`{"id": "18d1a2b3c4d5e6f7"}`))
+		default:
+			recorded.Config.Handler.ServeHTTP(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewGmailModifier(testServiceAccount(t, server.URL+"/token"), "me@example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.baseURL = server.URL
+	client.httpClient = server.Client()
+	client.auth.httpClient = server.Client()
+
+	if err := client.ModifyLabels("18d1a2b3c4d5e6f7", []string{"Action", "Receipts"}, []string{"INBOX", "UNREAD"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if created["name"] != "Receipts" {
+		t.Errorf("created label = %v, want missing label Receipts created", created)
+	}
+	if strings.Join(modify.AddLabelIDs, ",") != "Label_3,Label_9" {
+		t.Errorf("addLabelIds = %v, want existing and created label IDs", modify.AddLabelIDs)
+	}
+	if strings.Join(modify.RemoveLabelIDs, ",") != "INBOX,UNREAD" {
+		t.Errorf("removeLabelIds = %v, want INBOX and UNREAD", modify.RemoveLabelIDs)
+	}
+}
+
+func TestGmailClient_Invitations(t *testing.T) {
+	var queries []string
+	server := newRecordedGmailServer(t, jwt.MapClaims{}, &queries)
+	defer server.Close()
+
+	invitations, err := newTestGmailClient(t, server).Invitations("newer_than:14d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 1 || queries[0] != "in:inbox filename:ics newer_than:14d" {
+		t.Errorf("queries = %q, want inbox messages with .ics parts", queries)
+	}
+	if len(invitations) != 2 {
+		t.Fatalf("got %d invitations, want 2 (the METHOD:REPLY is skipped)", len(invitations))
+	}
+
+	review := invitations[0]
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	if review.UID != "design-review-42@example.com" || review.Sequence != 1 || review.ThreadID != "18d1b000000000a1" {
+		t.Errorf("invitation = %+v, want design review from the inline text/calendar part", review)
+	}
+	if review.Title != "Design review, platform API" || review.Location != "Room 4" {
+		t.Errorf("title/location = %q/%q, want unescaped values", review.Title, review.Location)
+	}
+	if want := time.Date(2026, 2, 12, 14, 0, 0, 0, berlin); !review.Start.Equal(want) || !review.End.Equal(want.Add(time.Hour)) {
+		t.Errorf("start/end = %v/%v, want 14:00-15:00 Berlin time", review.Start, review.End)
+	}
+	if review.Organizer != (EmailAddress{Name: "Lovelace, Ada", Email: "ada@example.com"}) {
+		t.Errorf("organizer = %+v, want Ada", review.Organizer)
+	}
+	if want := time.Date(2026, 2, 10, 10, 0, 0, 0, time.UTC); !review.Received.Equal(want) {
+		t.Errorf("received = %v, want %v", review.Received, want)
+	}
+
+	offsite := invitations[1]
+	if offsite.UID != "offsite-2026" || !offsite.AllDay || offsite.End.Sub(offsite.Start) != 48*time.Hour {
+		t.Errorf("offsite = %+v, want two-day all-day invitation from the attachment", offsite)
+	}
+}
+
+func TestGmailClient_MessageBody(t *testing.T) {
+	var queries []string
+	server := newRecordedGmailServer(t, jwt.MapClaims{}, &queries)
+	defer server.Close()
+
+	body, err := newTestGmailClient(t, server).MessageBody("18d1a2b3c4d5e6f8")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(body.HTML, `<a href="https://go.dev/blog/generics-tips">`) || strings.Contains(body.HTML, "archive") {
+		t.Errorf("html = %q, want the inline HTML part, not the attachment", body.HTML)
+	}
+	if body.Text != "This week in Go: generics tips\nhttps://go.dev/blog/generics-tips\n" {
+		t.Errorf("text = %q, want decoded plain text part", body.Text)
+	}
+}
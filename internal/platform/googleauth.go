@@ -0,0 +1,138 @@
+package platform
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const googleTokenURL = "https://oauth2.googleapis.com/token"
+
+// ServiceAccountKey represents the JSON key file for a Google service account.
+type ServiceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+func parseServiceAccountKey(credentialsJSON string) (ServiceAccountKey, error) {
+	var key ServiceAccountKey
+	if err := json.Unmarshal([]byte(credentialsJSON), &key); err != nil {
+		return ServiceAccountKey{}, fmt.Errorf("parsing service account credentials: %w", err)
+	}
+
+	if key.ClientEmail == "" || key.PrivateKey == "" {
+		return ServiceAccountKey{}, fmt.Errorf("service account credentials missing client_email or private_key")
+	}
+
+	if key.TokenURI == "" {
+		key.TokenURI = googleTokenURL
+	}
+
+	return key, nil
+}
+
+// googleAuth exchanges a signed service-account JWT for an access token
+// and caches it until it expires.
+type googleAuth struct {
+	credentials ServiceAccountKey
+	scope       string
+	// subject is the user to impersonate through domain-wide delegation.
+	// Empty means the service account acts as itself.
+	subject     string
+	httpClient  *http.Client
+	accessToken string
+	tokenExpiry time.Time
+}
+
+func newGoogleAuth(key ServiceAccountKey, scope, subject string, httpClient *http.Client) *googleAuth {
+	return &googleAuth{
+		credentials: key,
+		scope:       scope,
+		subject:     subject,
+		httpClient:  httpClient,
+	}
+}
+
+func (a *googleAuth) token() (string, error) {
+	if a.accessToken != "" && time.Now().Before(a.tokenExpiry) {
+		return a.accessToken, nil
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   a.credentials.ClientEmail,
+		"scope": a.scope,
+		"aud":   a.credentials.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+	if a.subject != "" {
+		claims["sub"] = a.subject
+	}
+
+	privateKey, err := parseRSAPrivateKey(a.credentials.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("parsing private key: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	signedJWT, err := token.SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("signing JWT: %w", err)
+	}
+
+	resp, err := a.httpClient.PostForm(a.credentials.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {signedJWT},
+	})
+	if err != nil {
+		return "", fmt.Errorf("exchanging JWT for token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("token exchange returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("parsing token response: %w", err)
+	}
+
+	a.accessToken = tokenResp.AccessToken
+	a.tokenExpiry = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+
+	return a.accessToken, nil
+}
+
+func parseRSAPrivateKey(pemStr string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemStr))
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in private key")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not RSA")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,183 @@
+package platform
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icalUTCFormat  = "20060102T150405Z"
+	icalDateFormat = "20060102"
+)
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// icalEscape escapes a TEXT value (RFC 5545 §3.3.11).
+func icalEscape(s string) string {
+	return icalEscaper.Replace(strings.ReplaceAll(s, "\r\n", "\n"))
+}
+
+// foldICalLine splits content lines longer than 75 octets (RFC 5545 §3.1),
+// never breaking inside a UTF-8 sequence.
+func foldICalLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
+
+// Invitation is the event of an iCalendar METHOD:REQUEST message, the
+// format calendar invitations are emailed in (RFC 5546).
+type Invitation struct {
+	UID string
+	// Sequence increases each time the organizer updates the event.
+	Sequence  int
+	Title     string
+	Start     time.Time
+	End       time.Time
+	AllDay    bool
+	Location  string
+	Organizer EmailAddress
+}
+
+// icalProperty is an unfolded content line such as
+// "DTSTART;TZID=Europe/Berlin:20260212T140000".
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// parseInvitation reads the first VEVENT of a METHOD:REQUEST calendar.
+// Replies, cancellations and published calendars are not invitations.
+func parseInvitation(data string) (Invitation, bool) {
+	var inv Invitation
+	method, inEvent, found := "", false, false
+
+	for _, prop := range parseICalProperties(data) {
+		switch {
+		case prop.name == "METHOD":
+			method = strings.ToUpper(prop.value)
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT"):
+			inEvent = !found
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT"):
+			if inEvent {
+				found = true
+			}
+			inEvent = false
+		case !inEvent:
+		case prop.name == "UID":
+			inv.UID = prop.value
+		case prop.name == "SEQUENCE":
+			inv.Sequence, _ = strconv.Atoi(prop.value)
+		case prop.name == "SUMMARY":
+			inv.Title = icalUnescape(prop.value)
+		case prop.name == "LOCATION":
+			inv.Location = icalUnescape(prop.value)
+		case prop.name == "DTSTART":
+			inv.Start, inv.AllDay = parseICalTime(prop)
+		case prop.name == "DTEND":
+			inv.End, _ = parseICalTime(prop)
+		case prop.name == "ORGANIZER":
+			inv.Organizer = EmailAddress{
+				Name:  strings.Trim(prop.params["CN"], `"`),
+				Email: strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(prop.value, "mailto:"), "MAILTO:")),
+			}
+		}
+	}
+
+	if method != "REQUEST" || !found || inv.UID == "" || inv.Start.IsZero() {
+		return Invitation{}, false
+	}
+	if inv.End.IsZero() {
+		inv.End = inv.Start
+		if inv.AllDay {
+			inv.End = inv.Start.AddDate(0, 0, 1)
+		}
+	}
+	return inv, true
+}
+
+// parseICalProperties unfolds content lines (RFC 5545 §3.1) and splits them
+// into name, parameters and value.
+func parseICalProperties(data string) []icalProperty {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.NewReplacer("\n ", "", "\n\t", "").Replace(data)
+
+	var props []icalProperty
+	for _, line := range strings.Split(data, "\n") {
+		head, value, ok := cutICalValue(line)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(head, ";")
+		prop := icalProperty{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: value}
+		for _, param := range parts[1:] {
+			if k, v, ok := strings.Cut(param, "="); ok {
+				prop.params[strings.ToUpper(k)] = v
+			}
+		}
+		props = append(props, prop)
+	}
+	return props
+}
+
+// cutICalValue splits a content line at the first colon outside a quoted
+// parameter value, e.g. ORGANIZER;CN="Lovelace: Ada":mailto:ada@example.com.
+func cutICalValue(line string) (head, value string, ok bool) {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			return line[:i], line[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// parseICalTime reads a DATE or DATE-TIME value in UTC, in its TZID time
+// zone, or as floating local time. It reports whether the value is a date.
+func parseICalTime(prop icalProperty) (time.Time, bool) {
+	if prop.params["VALUE"] == "DATE" || len(prop.value) == len(icalDateFormat) {
+		t, err := time.ParseInLocation(icalDateFormat, prop.value, time.Local)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return t, true
+	}
+	if strings.HasSuffix(prop.value, "Z") {
+		t, _ := time.Parse(icalUTCFormat, prop.value)
+		return t, false
+	}
+
+	loc := time.Local
+	if tzid := strings.Trim(prop.params["TZID"], `"`); tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, _ := time.ParseInLocation("20060102T150405", prop.value, loc)
+	return t, false
+}
+
+var icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func icalUnescape(s string) string {
+	return icalUnescaper.Replace(s)
+}
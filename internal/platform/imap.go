@@ -0,0 +1,1191 @@
+package platform
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	defaultIMAPPort       = 993
+	defaultIMAPSentFolder = "Sent"
+	imapInbox             = "INBOX"
+
+	// imapSnippetBytes is how much of each message's text is fetched to
+	// build its snippet.
+	imapSnippetBytes  = 1024
+	imapSnippetLength = 200
+)
+
+// IMAPClient reads a mailbox over IMAP4rev1 with TLS, for servers such as
+// Fastmail, Outlook or Dovecot.
+//
+// IMAP has no conversations or labels. Messages are grouped into threads by
+// their Message-ID, In-Reply-To and References headers, and folders and flags
+// are reported as labels: INBOX, SENT, UNREAD, STARRED, other folder names
+// and keywords.
+type IMAPClient struct {
+	addr       string
+	username   string
+	password   string
+	sentFolder string
+	tlsConfig  *tls.Config
+	timeout    time.Duration
+	maxThreads int
+	now        func() time.Time
+}
+
+// NewIMAPClient creates a client for the server at host. port defaults to 993
+// and sentFolder to "Sent".
+func NewIMAPClient(host string, port int, username, password, sentFolder string) *IMAPClient {
+	if port == 0 {
+		port = defaultIMAPPort
+	}
+	if sentFolder == "" {
+		sentFolder = defaultIMAPSentFolder
+	}
+	return &IMAPClient{
+		addr:       net.JoinHostPort(host, strconv.Itoa(port)),
+		username:   username,
+		password:   password,
+		sentFolder: sentFolder,
+		tlsConfig:  &tls.Config{ServerName: host},
+		timeout:    2 * time.Minute,
+		maxThreads: defaultMaxThreads,
+		now:        time.Now,
+	}
+}
+
+func (c *IMAPClient) InboxThreads(query string) ([]EmailThread, error) {
+	return c.Threads(strings.TrimSpace("in:inbox " + query))
+}
+
+// Threads translates a Gmail-style query to IMAP SEARCH (see parseQuery).
+// IMAP has no view of all mail, so queries without in: or label: search the
+// inbox. Messages from the inbox and sent folder over the same period are
+// read as well, so that threads include both sides of the conversation.
+func (c *IMAPClient) Threads(query string) ([]EmailThread, error) {
+	q := c.parseQuery(query)
+
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+
+	messages, err := conn.fetchFolder(q.folder, c.folderLabel(q.folder), q.searchKeys(), c.maxThreads)
+	if err != nil {
+		return nil, err
+	}
+	hits := make(map[*imapMessage]bool, len(messages))
+	for _, msg := range messages {
+		hits[msg] = true
+	}
+
+	for _, folder := range []string{imapInbox, c.sentFolder} {
+		if strings.EqualFold(folder, q.folder) {
+			continue
+		}
+		context, err := conn.fetchFolder(folder, c.folderLabel(folder), q.contextKeys(), c.maxThreads)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, context...)
+	}
+
+	groups := groupIMAPThreads(messages, hits)
+	if len(groups) > c.maxThreads {
+		groups = groups[:c.maxThreads]
+	}
+
+	var kept []*imapMessage
+	for _, group := range groups {
+		kept = append(kept, group...)
+	}
+	if err := conn.fetchSnippets(kept); err != nil {
+		return nil, err
+	}
+
+	threads := make([]EmailThread, len(groups))
+	for i, group := range groups {
+		threads[i] = imapThread(group)
+	}
+	return threads, nil
+}
+
+// Invitations reads the invitations attached to inbox messages matching
+// query. IMAP cannot search by attachment type, so the structure of every
+// matching message is checked.
+func (c *IMAPClient) Invitations(query string) ([]EmailInvitation, error) {
+	q := c.parseQuery(strings.TrimSpace("in:inbox " + query))
+
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.logout()
+
+	messages, err := conn.fetchFolder(q.folder, c.folderLabel(q.folder), q.searchKeys(), c.maxThreads)
+	if err != nil {
+		return nil, err
+	}
+
+	var invitations []EmailInvitation
+	// Newest first: fetchFolder returns messages in UID order.
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		for _, part := range msg.calendar {
+			data, err := conn.readPart(msg, part)
+			if err != nil {
+				return nil, err
+			}
+			if inv, ok := parseInvitation(data); ok {
+				invitations = append(invitations, EmailInvitation{
+					Invitation: inv,
+					ThreadID:   msg.threadID(),
+					Received:   msg.Date,
+				})
+			}
+		}
+	}
+	return invitations, nil
+}
+
+// MessageBody reads the HTML and plain text parts of an inbox message by its
+// Message-ID. Messages without one have an ID of "folder/uid".
+func (c *IMAPClient) MessageBody(messageID string) (MessageBody, error) {
+	folder, keys := "INBOX", "HEADER MESSAGE-ID "+imapAString("<"+messageID+">")
+	if i := strings.LastIndex(messageID, "/"); i >= 0 && !strings.Contains(messageID, "@") {
+		folder, keys = messageID[:i], "UID "+messageID[i+1:]
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return MessageBody{}, err
+	}
+	defer conn.logout()
+
+	messages, err := conn.fetchFolder(folder, c.folderLabel(folder), keys, 1)
+	if err != nil {
+		return MessageBody{}, err
+	}
+	if len(messages) == 0 {
+		return MessageBody{}, fmt.Errorf("message %s not found in IMAP folder %q", messageID, folder)
+	}
+	msg := messages[0]
+
+	var body MessageBody
+	if msg.html.section != "" {
+		if body.HTML, err = conn.readPart(msg, msg.html); err != nil {
+			return MessageBody{}, err
+		}
+	}
+	// The text part is HTML when there is no plain text.
+	if msg.text.mimeType == "text/plain" {
+		if body.Text, err = conn.readPart(msg, msg.text); err != nil {
+			return MessageBody{}, err
+		}
+	}
+	return body, nil
+}
+
+// folderLabel names a folder the way Gmail names the equivalent label.
+func (c *IMAPClient) folderLabel(folder string) string {
+	switch {
+	case strings.EqualFold(folder, imapInbox):
+		return "INBOX"
+	case folder == c.sentFolder:
+		return "SENT"
+	}
+	return folder
+}
+
+// imapQuery is a Gmail-style query translated to IMAP SEARCH keys.
+type imapQuery struct {
+	folder   string
+	criteria []string
+	// period holds the SINCE/BEFORE keys, which also bound the messages
+	// read from other folders to complete threads.
+	period []string
+	// utf8 is set when a criterion is not ASCII, so the search must name
+	// its charset.
+	utf8 bool
+}
+
+// parseQuery supports in:, label: (a folder), is:unread, is:read,
+// is:starred, newer_than:, older_than:, from:, to:, cc: and subject:.
+// Other terms search the message text. Values with spaces are quoted, as in
+// label:"Action Needed".
+func (c *IMAPClient) parseQuery(query string) imapQuery {
+	q := imapQuery{folder: imapInbox}
+	astring := func(s string) string {
+		if !isASCII(s) {
+			q.utf8 = true
+		}
+		return imapAString(s)
+	}
+	for _, term := range queryTerms(query) {
+		key, value, ok := strings.Cut(term, ":")
+		value = strings.Trim(value, `"`)
+		switch {
+		case !ok:
+			q.criteria = append(q.criteria, "TEXT "+astring(strings.Trim(term, `"`)))
+		case key == "in" && value == "inbox":
+			q.folder = imapInbox
+		case key == "in" && value == "sent":
+			q.folder = c.sentFolder
+		case key == "in", key == "label":
+			q.folder = value
+		case key == "is" && value == "unread":
+			q.criteria = append(q.criteria, "UNSEEN")
+		case key == "is" && value == "read":
+			q.criteria = append(q.criteria, "SEEN")
+		case key == "is" && value == "starred":
+			q.criteria = append(q.criteria, "FLAGGED")
+		case key == "newer_than", key == "older_than":
+			date, ok := c.relativeDate(value)
+			if !ok {
+				continue
+			}
+			keyword := "SINCE "
+			if key == "older_than" {
+				keyword = "BEFORE "
+			}
+			q.period = append(q.period, keyword+date.Format("2-Jan-2006"))
+		case key == "from", key == "to", key == "cc", key == "subject":
+			q.criteria = append(q.criteria, strings.ToUpper(key)+" "+astring(value))
+		default:
+			q.criteria = append(q.criteria, "TEXT "+astring(term))
+		}
+	}
+	return q
+}
+
+// queryTerms splits a query on spaces outside double quotes.
+func queryTerms(query string) []string {
+	var terms []string
+	var term strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			term.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms
+}
+
+// relativeDate resolves Gmail's "7d", "2m" or "1y" relative to now.
+func (c *IMAPClient) relativeDate(value string) (time.Time, bool) {
+	if len(value) < 2 {
+		return time.Time{}, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	now := c.now()
+	switch value[len(value)-1] {
+	case 'd':
+		return now.AddDate(0, 0, -n), true
+	case 'm':
+		return now.AddDate(0, -n, 0), true
+	case 'y':
+		return now.AddDate(-n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+func (q imapQuery) searchKeys() string {
+	keys := append(append([]string{}, q.criteria...), q.period...)
+	if len(keys) == 0 {
+		return "ALL"
+	}
+	if q.utf8 {
+		return "CHARSET UTF-8 " + strings.Join(keys, " ")
+	}
+	return strings.Join(keys, " ")
+}
+
+func (q imapQuery) contextKeys() string {
+	if len(q.period) == 0 {
+		return "ALL"
+	}
+	return strings.Join(q.period, " ")
+}
+
+// imapMessage is a fetched message with the headers needed to thread it.
+type imapMessage struct {
+	EmailMessage
+	folder          string
+	uid             string
+	inReplyTo       string
+	references      []string
+	listUnsubscribe []string
+	listID          string
+	unread          bool
+	starred         bool
+	text            imapPart
+	html            imapPart
+	calendar        []imapPart
+}
+
+// threadID returns the Message-ID of the first message in the conversation,
+// which stays the same as the thread grows.
+func (m *imapMessage) threadID() string {
+	switch {
+	case len(m.references) > 0:
+		return m.references[0]
+	case m.inReplyTo != "":
+		return m.inReplyTo
+	}
+	return m.ID
+}
+
+// imapPart locates a body part, such as the text of a message for its
+// snippet or an invitation.
+type imapPart struct {
+	section  string
+	mimeType string // e.g. "text/plain"
+	encoding string
+	charset  string
+}
+
+// groupIMAPThreads groups messages into threads, keeping only threads with
+// at least one hit. Messages are ordered chronologically and threads newest
+// first. Copies of a message in several folders are merged.
+func groupIMAPThreads(messages []*imapMessage, hits map[*imapMessage]bool) [][]*imapMessage {
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	union := func(a, b string) {
+		if b == "" {
+			return
+		}
+		if ra, rb := find(a), find(b); ra != rb {
+			parent[ra] = rb
+		}
+	}
+
+	byID := make(map[string]*imapMessage)
+	var unique []*imapMessage
+	for _, msg := range messages {
+		if existing, ok := byID[msg.ID]; ok {
+			mergeIMAPCopy(existing, msg)
+			if hits[msg] {
+				hits[existing] = true
+			}
+			continue
+		}
+		byID[msg.ID] = msg
+		unique = append(unique, msg)
+		union(msg.ID, msg.inReplyTo)
+		for _, ref := range msg.references {
+			union(msg.ID, ref)
+		}
+	}
+
+	groupsByRoot := make(map[string][]*imapMessage)
+	var roots []string
+	for _, msg := range unique {
+		root := find(msg.ID)
+		if _, ok := groupsByRoot[root]; !ok {
+			roots = append(roots, root)
+		}
+		groupsByRoot[root] = append(groupsByRoot[root], msg)
+	}
+
+	var groups [][]*imapMessage
+	for _, root := range roots {
+		group := groupsByRoot[root]
+		hit := false
+		for _, msg := range group {
+			hit = hit || hits[msg]
+		}
+		if !hit {
+			continue
+		}
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Date.Before(group[j].Date) })
+		groups = append(groups, group)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i][len(groups[i])-1].Date.After(groups[j][len(groups[j])-1].Date)
+	})
+	return groups
+}
+
+// mergeIMAPCopy folds the folder and flags of another copy of msg into it.
+func mergeIMAPCopy(msg, other *imapMessage) {
+	for _, label := range other.Labels {
+		if !slices.Contains(msg.Labels, label) {
+			msg.Labels = append(msg.Labels, label)
+		}
+	}
+	msg.unread = msg.unread || other.unread
+	msg.starred = msg.starred || other.starred
+}
+
+// imapThread builds a thread from chronologically ordered messages.
+func imapThread(group []*imapMessage) EmailThread {
+	first, last := group[0], group[len(group)-1]
+	thread := EmailThread{
+		ID:      first.ID,
+		Subject: first.Subject,
+		From:    first.From,
+		Snippet: last.Snippet,
+		Date:    last.Date,
+	}
+	thread.ID = first.threadID()
+
+	labels := make(map[string]bool)
+	for _, msg := range group {
+		thread.Messages = append(thread.Messages, msg.EmailMessage)
+		thread.Unread = thread.Unread || msg.unread
+		thread.Starred = thread.Starred || msg.starred
+		if len(msg.listUnsubscribe) > 0 {
+			thread.ListUnsubscribe = msg.listUnsubscribe
+		}
+		if msg.listID != "" {
+			thread.ListID = msg.listID
+		}
+		for _, label := range msg.Labels {
+			labels[label] = true
+		}
+	}
+	for label := range labels {
+		thread.Labels = append(thread.Labels, label)
+	}
+	sort.Strings(thread.Labels)
+	return thread
+}
+
+// imapConn is an authenticated IMAP session.
+type imapConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	tag  int
+}
+
+func (c *IMAPClient) connect() (*imapConn, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: c.timeout}, "tcp", c.addr, c.tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to IMAP server %s: %w", c.addr, err)
+	}
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	ic := &imapConn{conn: conn, r: bufio.NewReader(conn)}
+	greeting, err := ic.readFields()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("reading IMAP greeting: %w", err)
+	}
+	if len(greeting) < 2 || imapString(greeting[1]) != "OK" {
+		conn.Close()
+		return nil, fmt.Errorf("IMAP server refused connection: %v", greeting)
+	}
+
+	if _, err := ic.command("LOGIN %s %s", imapAString(c.username), imapAString(c.password)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("logging in to IMAP server: %w", err)
+	}
+	return ic, nil
+}
+
+func (c *imapConn) logout() {
+	c.command("LOGOUT")
+	c.conn.Close()
+}
+
+// fetchFolder returns the newest max messages in folder matching the search
+// keys, labelled with label.
+func (c *imapConn) fetchFolder(folder, label, keys string, max int) ([]*imapMessage, error) {
+	if _, err := c.command("EXAMINE %s", imapMailbox(folder)); err != nil {
+		return nil, fmt.Errorf("opening IMAP folder %q: %w", folder, err)
+	}
+
+	responses, err := c.command("UID SEARCH %s", keys)
+	if err != nil {
+		return nil, fmt.Errorf("searching IMAP folder %q: %w", folder, err)
+	}
+	var uids []int
+	for _, fields := range responses {
+		if len(fields) < 2 || imapString(fields[1]) != "SEARCH" {
+			continue
+		}
+		for _, v := range fields[2:] {
+			if uid, err := strconv.Atoi(imapString(v)); err == nil {
+				uids = append(uids, uid)
+			}
+		}
+	}
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	// UIDs increase as messages arrive, so the highest are the newest.
+	sort.Ints(uids)
+	if len(uids) > max {
+		uids = uids[len(uids)-max:]
+	}
+
+	responses, err = c.command("UID FETCH %s (UID FLAGS INTERNALDATE ENVELOPE BODYSTRUCTURE BODY.PEEK[HEADER.FIELDS (REFERENCES LIST-UNSUBSCRIBE LIST-ID)])", joinUIDs(uids))
+	if err != nil {
+		return nil, fmt.Errorf("fetching IMAP messages from %q: %w", folder, err)
+	}
+
+	var messages []*imapMessage
+	for _, fields := range responses {
+		if attrs, ok := fetchAttributes(fields); ok {
+			messages = append(messages, parseIMAPMessage(folder, label, attrs))
+		}
+	}
+	return messages, nil
+}
+
+// fetchSnippets reads the start of each message's text, with one FETCH per
+// folder and body section.
+func (c *imapConn) fetchSnippets(messages []*imapMessage) error {
+	type batch struct{ folder, section string }
+	batches := make(map[batch]map[string]*imapMessage)
+	var order []batch
+	for _, msg := range messages {
+		if msg.text.section == "" {
+			continue
+		}
+		b := batch{msg.folder, msg.text.section}
+		if batches[b] == nil {
+			batches[b] = make(map[string]*imapMessage)
+			order = append(order, b)
+		}
+		batches[b][msg.uid] = msg
+	}
+
+	selected := ""
+	for _, b := range order {
+		if b.folder != selected {
+			if _, err := c.command("EXAMINE %s", imapMailbox(b.folder)); err != nil {
+				return fmt.Errorf("opening IMAP folder %q: %w", b.folder, err)
+			}
+			selected = b.folder
+		}
+
+		var uids []int
+		for uid := range batches[b] {
+			n, _ := strconv.Atoi(uid)
+			uids = append(uids, n)
+		}
+		sort.Ints(uids)
+
+		bodies, err := c.fetchSection(uids, fmt.Sprintf("%s]<0.%d>", b.section, imapSnippetBytes))
+		if err != nil {
+			return fmt.Errorf("fetching IMAP message text from %q: %w", b.folder, err)
+		}
+		for uid, body := range bodies {
+			if msg, ok := batches[b][uid]; ok {
+				msg.Snippet = decodeSnippet(msg.text, body)
+			}
+		}
+	}
+	return nil
+}
+
+// readPart fetches and decodes a whole body part of a message in the
+// selected folder.
+func (c *imapConn) readPart(msg *imapMessage, part imapPart) (string, error) {
+	uid, _ := strconv.Atoi(msg.uid)
+	bodies, err := c.fetchSection([]int{uid}, part.section+"]")
+	if err != nil {
+		return "", fmt.Errorf("fetching IMAP message part from %q: %w", msg.folder, err)
+	}
+	return decodePart(part, bodies[msg.uid]), nil
+}
+
+// fetchSection fetches a body section, such as "1]" or "1]<0.1024>" for a
+// partial fetch, of messages in the selected folder, keyed by UID.
+func (c *imapConn) fetchSection(uids []int, section string) (map[string]string, error) {
+	responses, err := c.command("UID FETCH %s (BODY.PEEK[%s)", joinUIDs(uids), section)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make(map[string]string)
+	for _, fields := range responses {
+		attrs, ok := fetchAttributes(fields)
+		if !ok {
+			continue
+		}
+		var uid, body string
+		for i := 0; i+1 < len(attrs); i += 2 {
+			name := strings.ToUpper(imapString(attrs[i]))
+			switch {
+			case name == "UID":
+				uid = imapString(attrs[i+1])
+			case strings.HasPrefix(name, "BODY["):
+				body = imapString(attrs[i+1])
+			}
+		}
+		bodies[uid] = body
+	}
+	return bodies, nil
+}
+
+// command sends a tagged command and returns the untagged responses, or an
+// error unless the server completes it with OK.
+func (c *imapConn) command(format string, args ...any) ([][]any, error) {
+	c.tag++
+	tag := "A" + strconv.Itoa(c.tag)
+	cmd := fmt.Sprintf(format, args...)
+	if err := c.send(tag, tag+" "+cmd+"\r\n"); err != nil {
+		return nil, fmt.Errorf("sending IMAP command: %w", err)
+	}
+
+	var untagged [][]any
+	for {
+		fields, err := c.readFields()
+		if err != nil {
+			return nil, fmt.Errorf("reading IMAP response: %w", err)
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		switch imapString(fields[0]) {
+		case "*":
+			untagged = append(untagged, fields)
+		case tag:
+			if status := imapString(fields[1]); status != "OK" {
+				verb, _, _ := strings.Cut(cmd, " ")
+				return nil, fmt.Errorf("IMAP %s returned %s: %s", verb, status, imapString(fields[len(fields)-1]))
+			}
+			return untagged, nil
+		}
+	}
+}
+
+// imapLiteral matches the "{N}" that announces a literal of N bytes.
+var imapLiteral = regexp.MustCompile(`\{(\d+)\}\r\n`)
+
+// send writes a command line, waiting for the server's continuation request
+// before each literal in it.
+func (c *imapConn) send(tag, line string) error {
+	for {
+		loc := imapLiteral.FindStringSubmatchIndex(line)
+		if loc == nil {
+			_, err := io.WriteString(c.conn, line)
+			return err
+		}
+		if _, err := io.WriteString(c.conn, line[:loc[1]]); err != nil {
+			return err
+		}
+		if err := c.awaitContinuation(tag); err != nil {
+			return err
+		}
+		n, _ := strconv.Atoi(line[loc[2]:loc[3]])
+		if _, err := io.WriteString(c.conn, line[loc[1]:loc[1]+n]); err != nil {
+			return err
+		}
+		line = line[loc[1]+n:]
+	}
+}
+
+// awaitContinuation reads responses until the server asks for a literal
+// with "+", or rejects the command.
+func (c *imapConn) awaitContinuation(tag string) error {
+	for {
+		fields, err := c.readFields()
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		switch imapString(fields[0]) {
+		case "+":
+			return nil
+		case tag:
+			return fmt.Errorf("IMAP server refused literal: %s", imapString(fields[len(fields)-1]))
+		}
+	}
+}
+
+// readFields parses one response line into atoms and quoted strings
+// (string), NIL (nil) and parenthesized lists ([]any), reading literals
+// inline. The text after a status word such as OK is kept as one string.
+func (c *imapConn) readFields() ([]any, error) {
+	var fields []any
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case ' ', '\r':
+			continue
+		case '\n':
+			return fields, nil
+		}
+		c.r.UnreadByte()
+
+		v, err := c.readValue()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, v)
+
+		if isIMAPStatusLine(fields) {
+			text, err := c.r.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+			return append(fields, strings.TrimSpace(text)), nil
+		}
+	}
+}
+
+func isIMAPStatusLine(fields []any) bool {
+	if len(fields) == 1 {
+		return imapString(fields[0]) == "+"
+	}
+	if len(fields) != 2 {
+		return false
+	}
+	switch imapString(fields[1]) {
+	case "OK", "NO", "BAD", "BYE", "PREAUTH":
+		return true
+	}
+	return false
+}
+
+func (c *imapConn) readValue() (any, error) {
+	b, err := c.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch b {
+	case '(':
+		list := []any{}
+		for {
+			b, err := c.r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch b {
+			case ' ':
+				continue
+			case ')':
+				return list, nil
+			case '\r', '\n':
+				return nil, fmt.Errorf("unterminated list in IMAP response")
+			}
+			c.r.UnreadByte()
+			v, err := c.readValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+
+	case '"':
+		var sb strings.Builder
+		for {
+			b, err := c.r.ReadByte()
+			if err != nil {
+				return nil, err
+			}
+			switch b {
+			case '\\':
+				if b, err = c.r.ReadByte(); err != nil {
+					return nil, err
+				}
+			case '"':
+				return sb.String(), nil
+			}
+			sb.WriteByte(b)
+		}
+
+	case '{':
+		size, err := c.r.ReadString('}')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(size, "}"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid literal size in IMAP response: %q", size)
+		}
+		if _, err := c.r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		data := make([]byte, n)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+
+	// Atoms end at a space or parenthesis, except inside a section such as
+	// BODY[HEADER.FIELDS (REFERENCES)].
+	c.r.UnreadByte()
+	var sb strings.Builder
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		switch b {
+		case ' ', '(', ')', '\r', '\n':
+			c.r.UnreadByte()
+			atom := sb.String()
+			if atom == "" {
+				return nil, fmt.Errorf("unexpected %q in IMAP response", b)
+			}
+			if strings.EqualFold(atom, "NIL") {
+				return nil, nil
+			}
+			return atom, nil
+		case '[':
+			section, err := c.r.ReadString(']')
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteByte('[')
+			sb.WriteString(section)
+			continue
+		}
+		sb.WriteByte(b)
+	}
+}
+
+// fetchAttributes returns the attribute list of a "* n FETCH (...)" response.
+func fetchAttributes(fields []any) ([]any, bool) {
+	if len(fields) != 4 || imapString(fields[2]) != "FETCH" {
+		return nil, false
+	}
+	attrs, ok := fields[3].([]any)
+	return attrs, ok
+}
+
+func parseIMAPMessage(folder, label string, attrs []any) *imapMessage {
+	msg := &imapMessage{folder: folder}
+	msg.Labels = []string{label}
+	seen := false
+	var keywords []string
+
+	for i := 0; i+1 < len(attrs); i += 2 {
+		name := strings.ToUpper(imapString(attrs[i]))
+		value := attrs[i+1]
+		switch {
+		case name == "UID":
+			msg.uid = imapString(value)
+		case name == "FLAGS":
+			flags, _ := value.([]any)
+			for _, f := range flags {
+				switch flag := imapString(f); {
+				case strings.EqualFold(flag, `\Seen`):
+					seen = true
+				case strings.EqualFold(flag, `\Flagged`):
+					msg.starred = true
+				case !strings.HasPrefix(flag, `\`) && !strings.HasPrefix(flag, "$"):
+					keywords = append(keywords, flag)
+				}
+			}
+		case name == "INTERNALDATE":
+			msg.Date, _ = time.Parse("_2-Jan-2006 15:04:05 -0700", imapString(value))
+		case name == "ENVELOPE":
+			envelope, _ := value.([]any)
+			parseEnvelope(msg, envelope)
+		case name == "BODYSTRUCTURE":
+			structure, _ := value.([]any)
+			var parts []imapPart
+			collectParts(structure, "", &parts)
+			msg.text, _ = findTextPart(parts)
+			for _, part := range parts {
+				switch part.mimeType {
+				case "text/html":
+					if msg.html.section == "" {
+						msg.html = part
+					}
+				case "text/calendar", "application/ics":
+					msg.calendar = append(msg.calendar, part)
+				}
+			}
+		case strings.HasPrefix(name, "BODY[HEADER"):
+			header, err := textproto.NewReader(bufio.NewReader(strings.NewReader(imapString(value) + "\r\n"))).ReadMIMEHeader()
+			if err != nil && len(header) == 0 {
+				continue
+			}
+			for _, ref := range strings.Fields(header.Get("References")) {
+				msg.references = append(msg.references, trimMessageID(ref))
+			}
+			msg.listUnsubscribe = parseListUnsubscribe(header.Get("List-Unsubscribe"))
+			msg.listID = parseListID(header.Get("List-Id"))
+		}
+	}
+
+	if !seen {
+		msg.unread = true
+		msg.Labels = append(msg.Labels, "UNREAD")
+	}
+	if msg.starred {
+		msg.Labels = append(msg.Labels, "STARRED")
+	}
+	msg.Labels = append(msg.Labels, keywords...)
+
+	if msg.ID == "" {
+		msg.ID = folder + "/" + msg.uid
+	}
+	return msg
+}
+
+// parseEnvelope reads the RFC 3501 envelope: date, subject, from, sender,
+// reply-to, to, cc, bcc, in-reply-to and message-id.
+func parseEnvelope(msg *imapMessage, envelope []any) {
+	if len(envelope) < 10 {
+		return
+	}
+	msg.Subject = decodeMIMEHeader(imapString(envelope[1]))
+	if from := parseIMAPAddresses(envelope[2]); len(from) > 0 {
+		msg.From = from[0]
+	}
+	msg.To = parseIMAPAddresses(envelope[5])
+	msg.Cc = parseIMAPAddresses(envelope[6])
+	// In-Reply-To may list several IDs; the first is the parent.
+	if ids := strings.Fields(imapString(envelope[8])); len(ids) > 0 {
+		msg.inReplyTo = trimMessageID(ids[0])
+	}
+	msg.ID = trimMessageID(imapString(envelope[9]))
+}
+
+// parseIMAPAddresses reads an envelope address list of
+// (name route mailbox host) entries, skipping group markers.
+func parseIMAPAddresses(value any) []EmailAddress {
+	list, _ := value.([]any)
+	var result []EmailAddress
+	for _, item := range list {
+		parts, _ := item.([]any)
+		if len(parts) < 4 || parts[3] == nil {
+			continue
+		}
+		result = append(result, EmailAddress{
+			Name:  decodeMIMEHeader(imapString(parts[0])),
+			Email: strings.ToLower(imapString(parts[2]) + "@" + imapString(parts[3])),
+		})
+	}
+	return result
+}
+
+// findTextPart returns the first text/plain part, or the first text/html
+// part when there is no plain text.
+func findTextPart(parts []imapPart) (imapPart, bool) {
+	for _, mimeType := range []string{"text/plain", "text/html"} {
+		for _, part := range parts {
+			if part.mimeType == mimeType {
+				return part, true
+			}
+		}
+	}
+	return imapPart{}, false
+}
+
+// collectParts lists the single parts of a BODYSTRUCTURE with their section
+// numbers.
+func collectParts(structure []any, section string, parts *[]imapPart) {
+	if len(structure) == 0 {
+		return
+	}
+
+	// Multipart bodies start with their nested parts.
+	if _, ok := structure[0].([]any); ok {
+		for i, item := range structure {
+			child, ok := item.([]any)
+			if !ok {
+				break
+			}
+			childSection := strconv.Itoa(i + 1)
+			if section != "" {
+				childSection = section + "." + childSection
+			}
+			collectParts(child, childSection, parts)
+		}
+		return
+	}
+
+	if len(structure) < 6 {
+		return
+	}
+	if section == "" {
+		section = "1"
+	}
+
+	part := imapPart{
+		section:  section,
+		mimeType: strings.ToLower(imapString(structure[0]) + "/" + imapString(structure[1])),
+		encoding: strings.ToUpper(imapString(structure[5])),
+	}
+	params, _ := structure[2].([]any)
+	for i := 0; i+1 < len(params); i += 2 {
+		if strings.EqualFold(imapString(params[i]), "CHARSET") {
+			part.charset = strings.ToLower(imapString(params[i+1]))
+		}
+	}
+	*parts = append(*parts, part)
+}
+
+var htmlTagPattern = regexp.MustCompile(`(?s)<(?:style|script)[^>]*>.*?</(?:style|script)>|<[^>]*>`)
+
+// decodeSnippet turns the start of a body part into a one-line snippet.
+func decodeSnippet(part imapPart, raw string) string {
+	text := decodePart(part, raw)
+	if part.mimeType == "text/html" {
+		text = html.UnescapeString(htmlTagPattern.ReplaceAllString(text, " "))
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > imapSnippetLength {
+		text = string(runes[:imapSnippetLength])
+	}
+	return text
+}
+
+// decodePart undoes the transfer encoding and converts the charset to
+// UTF-8. The part may be cut off mid-encoding, so decoding errors are
+// ignored.
+func decodePart(part imapPart, raw string) string {
+	data := []byte(raw)
+	switch part.encoding {
+	case "BASE64":
+		clean := strings.Join(strings.Fields(raw), "")
+		data, _ = base64.StdEncoding.DecodeString(clean[:len(clean)/4*4])
+	case "QUOTED-PRINTABLE":
+		data, _ = io.ReadAll(quotedprintable.NewReader(strings.NewReader(raw)))
+	}
+
+	switch part.charset {
+	case "iso-8859-1", "latin1", "windows-1252":
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	}
+	return strings.ToValidUTF8(string(data), "")
+}
+
+func decodeMIMEHeader(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
+func trimMessageID(id string) string {
+	return strings.Trim(strings.TrimSpace(id), "<>")
+}
+
+func imapString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// imapQuote writes s as an IMAP quoted string.
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// imapAString writes s as a quoted string, or as a literal when it is not
+// 7-bit text, which quoted strings cannot carry.
+func imapAString(s string) string {
+	if isASCII(s) && !strings.ContainsAny(s, "\r\n") {
+		return imapQuote(s)
+	}
+	return "{" + strconv.Itoa(len(s)) + "}\r\n" + s
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// imapUTF7 is base64 with "," for "/" and no padding, as used by modified
+// UTF-7.
+var imapUTF7 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+,").WithPadding(base64.NoPadding)
+
+// imapMailbox quotes a folder name in modified UTF-7 (RFC 3501 section
+// 5.1.3): printable ASCII stands for itself, "&" is written "&-", and other
+// characters are written as UTF-16 in base64 between "&" and "-".
+func imapMailbox(name string) string {
+	var b strings.Builder
+	var run []rune
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		units := utf16.Encode(run)
+		buf := make([]byte, 0, 2*len(units))
+		for _, u := range units {
+			buf = append(buf, byte(u>>8), byte(u))
+		}
+		b.WriteString("&" + imapUTF7.EncodeToString(buf) + "-")
+		run = run[:0]
+	}
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e {
+			run = append(run, r)
+			continue
+		}
+		flush()
+		if r == '&' {
+			b.WriteString("&-")
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	flush()
+	return imapQuote(b.String())
+}
+
+func joinUIDs(uids []int) string {
+	parts := make([]string, len(uids))
+	for i, uid := range uids {
+		parts[i] = strconv.Itoa(uid)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,481 @@
+package platform
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIMAPMessage is a message served by fakeIMAPServer. Envelope and
+// structure are raw IMAP syntax.
+type fakeIMAPMessage struct {
+	uid       int
+	flags     string
+	date      string
+	envelope  string
+	structure string
+	headers   string
+	text      string
+	// parts holds the content of body sections other than the text.
+	parts map[string]string
+}
+
+// fakeIMAPServer is an in-process IMAP4rev1 server over TLS that supports
+// LOGIN, EXAMINE, UID SEARCH (filtering on FLAGGED, UNSEEN and MESSAGE-ID only),
+// UID FETCH and LOGOUT, and records the commands it receives.
+type fakeIMAPServer struct {
+	t        *testing.T
+	listener net.Listener
+	roots    *x509.CertPool
+	folders  map[string][]fakeIMAPMessage
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newFakeIMAPServer(t *testing.T, folders map[string][]fakeIMAPMessage) *fakeIMAPServer {
+	t.Helper()
+
+	// Borrow httptest's certificate for 127.0.0.1.
+	certServer := httptest.NewUnstartedServer(nil)
+	certServer.StartTLS()
+	cert := certServer.TLS.Certificates[0]
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+	certServer.Close()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeIMAPServer{t: t, listener: listener, roots: roots, folders: folders}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeIMAPServer) client() *IMAPClient {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	var p int
+	fmt.Sscan(port, &p)
+	client := NewIMAPClient(host, p, "me@example.com", `pa"ss`, "Sent")
+	client.tlsConfig = &tls.Config{RootCAs: s.roots}
+	client.now = func() time.Time { return time.Date(2026, 2, 10, 12, 0, 0, 0, time.UTC) }
+	return client
+}
+
+func (s *fakeIMAPServer) recorded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+var fakeIMAPLiteral = regexp.MustCompile(`\{(\d+)\}$`)
+
+// readCommand reads one command line, asking for each literal it announces
+// and keeping the literal's "{N}" and data in the returned command.
+func (s *fakeIMAPServer) readCommand(conn net.Conn, r *bufio.Reader) (string, error) {
+	var cmd strings.Builder
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd.WriteString(line)
+		m := fakeIMAPLiteral.FindStringSubmatch(line)
+		if m == nil {
+			return cmd.String(), nil
+		}
+		n, _ := strconv.Atoi(m[1])
+		fmt.Fprint(conn, "+ Ready for literal data\r\n")
+		literal := make([]byte, n)
+		if _, err := io.ReadFull(r, literal); err != nil {
+			return "", err
+		}
+		cmd.WriteString("\r\n" + string(literal))
+	}
+}
+
+func (s *fakeIMAPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	fmt.Fprint(conn, "* OK [CAPABILITY IMAP4rev1] fake ready\r\n")
+
+	folder := ""
+	for {
+		line, err := s.readCommand(conn, r)
+		if err != nil {
+			return
+		}
+		tag, cmd, _ := strings.Cut(line, " ")
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		s.mu.Unlock()
+
+		switch {
+		case strings.HasPrefix(cmd, "LOGIN "):
+			if cmd != `LOGIN "me@example.com" "pa\"ss"` {
+				fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] Invalid credentials (Failure)\r\n", tag)
+				continue
+			}
+			fmt.Fprintf(conn, "%s OK LOGIN completed\r\n", tag)
+		case strings.HasPrefix(cmd, "EXAMINE "):
+			name := strings.Trim(strings.TrimPrefix(cmd, "EXAMINE "), `"`)
+			if _, ok := s.folders[name]; !ok {
+				fmt.Fprintf(conn, "%s NO Mailbox doesn't exist: %s\r\n", tag, name)
+				continue
+			}
+			folder = name
+			fmt.Fprintf(conn, "* %d EXISTS\r\n* OK [UIDVALIDITY 1] UIDs valid\r\n%s OK [READ-ONLY] EXAMINE completed\r\n", len(s.folders[folder]), tag)
+		case strings.HasPrefix(cmd, "UID SEARCH "):
+			var uids []string
+			for _, m := range s.folders[folder] {
+				if strings.Contains(cmd, "FLAGGED") && !strings.Contains(m.flags, `\Flagged`) ||
+					strings.Contains(cmd, "UNSEEN") && strings.Contains(m.flags, `\Seen`) {
+					continue
+				}
+				if _, id, ok := strings.Cut(cmd, "HEADER MESSAGE-ID "); ok && !strings.Contains(m.envelope, id) {
+					continue
+				}
+				uids = append(uids, fmt.Sprint(m.uid))
+			}
+			fmt.Fprintf(conn, "* SEARCH %s\r\n%s OK SEARCH completed\r\n", strings.Join(uids, " "), tag)
+		case strings.HasPrefix(cmd, "UID FETCH "):
+			fields := strings.SplitN(cmd, " ", 4)
+			wanted := strings.Split(fields[2], ",")
+			for i, m := range s.folders[folder] {
+				if !slices.Contains(wanted, fmt.Sprint(m.uid)) {
+					continue
+				}
+				if strings.Contains(cmd, "ENVELOPE") {
+					fmt.Fprintf(conn, "* %d FETCH (UID %d FLAGS (%s) INTERNALDATE \"%s\" ENVELOPE %s BODYSTRUCTURE %s BODY[HEADER.FIELDS (REFERENCES LIST-UNSUBSCRIBE LIST-ID)] {%d}\r\n%s)\r\n",
+						i+1, m.uid, m.flags, m.date, m.envelope, m.structure, len(m.headers), m.headers)
+				} else {
+					section := cmd[strings.Index(cmd, "[")+1 : strings.Index(cmd, "]")]
+					body := m.text
+					if part, ok := m.parts[section]; ok {
+						body = part
+					}
+					fmt.Fprintf(conn, "* %d FETCH (UID %d BODY[%s]<0> {%d}\r\n%s)\r\n", i+1, m.uid, section, len(body), body)
+				}
+			}
+			fmt.Fprintf(conn, "%s OK FETCH completed\r\n", tag)
+		case cmd == "LOGOUT":
+			fmt.Fprintf(conn, "* BYE logging out\r\n%s OK LOGOUT completed\r\n", tag)
+			return
+		default:
+			fmt.Fprintf(conn, "%s BAD unknown command\r\n", tag)
+		}
+	}
+}
+
+const (
+	imapAda     = `(("Ada Lovelace" NIL "ada" "example.com"))`
+	imapGrace   = `((NIL NIL "grace" "example.com"))`
+	imapMe      = `((NIL NIL "me" "example.com"))`
+	imapPlainQP = `("TEXT" "PLAIN" ("CHARSET" "UTF-8") NIL NIL "QUOTED-PRINTABLE" 60 2 NIL NIL NIL)`
+)
+
+func imapEnvelope(subject, from, to, inReplyTo, messageID string) string {
+	return fmt.Sprintf(`("Mon, 09 Feb 2026 09:00:00 +0000" %q %s %s %s %s NIL NIL %s %q)`,
+		subject, from, from, from, to, inReplyTo, messageID)
+}
+
+func testIMAPFolders() map[string][]fakeIMAPMessage {
+	return map[string][]fakeIMAPMessage{
+		"INBOX": {
+			{
+				uid: 11, flags: `\Seen`, date: "09-Feb-2026 09:00:00 +0000",
+				envelope:  imapEnvelope("Q1 budget", imapAda, imapMe, "NIL", "<budget-1@example.com>"),
+				structure: imapPlainQP,
+				headers:   "\r\n",
+				text:      "Can you review the Q1 budget by Friday?=\r\nThanks",
+			},
+			{
+				uid: 12, flags: "", date: "10-Feb-2026 08:00:00 +0000",
+				envelope: imapEnvelope("=?UTF-8?Q?Go_Weekly_=E2=80=94_#600?=", `(("Go Weekly" NIL "editor" "golangweekly.com"))`, imapMe, "NIL", "<gw600@golangweekly.com>"),
+				structure: `(("TEXT" "PLAIN" ("CHARSET" "UTF-8") NIL NIL "BASE64" 40 1 NIL NIL NIL)` +
+					`("TEXT" "HTML" ("CHARSET" "UTF-8") NIL NIL "BASE64" 80 2 NIL NIL NIL) "ALTERNATIVE" ("BOUNDARY" "b1") NIL NIL)`,
+				headers: "List-Unsubscribe: <mailto:unsub@golangweekly.com>,\r\n <https://golangweekly.com/u/abc>\r\n" +
+					"List-Id: Go Weekly <weekly.golangweekly.com>\r\n\r\n",
+				text:  "VGhpcyB3ZWVrOiBnZW5lcmljcyBpbiBwcmFjdGljZS4=\r\n",
+				parts: map[string]string{"2": "PGgxPlRoaXMgd2VlazwvaDE+PGEgaHJlZj0iaHR0cHM6Ly9nby5kZXYvYmxvZyI+R2VuZXJpY3M8L2E+\r\n"},
+			},
+			{
+				uid: 13, flags: `\Seen \Flagged Work`, date: "08-Feb-2026 16:30:00 +0000",
+				envelope:  imapEnvelope("Re: Offsite", imapGrace, imapMe, "<offsite-1@example.com>", "<offsite-2@example.com>"),
+				structure: `("TEXT" "HTML" ("CHARSET" "ISO-8859-1") NIL NIL "7BIT" 60 2 NIL NIL NIL)`,
+				headers:   "References: <offsite-1@example.com>\r\n\r\n",
+				text:      "<p>Sounds good &amp; caf\xe9 works</p>",
+			},
+		},
+		"Sent": {
+			{
+				uid: 3, flags: `\Seen`, date: "05-Feb-2026 10:00:00 +0000",
+				envelope:  imapEnvelope("Offsite", imapMe, imapGrace, "NIL", "<offsite-1@example.com>"),
+				structure: imapPlainQP,
+				headers:   "\r\n",
+				text:      "Shall we meet in Lisbon?",
+			},
+			{
+				uid: 4, flags: `\Seen`, date: "09-Feb-2026 12:00:00 +0000",
+				envelope:  imapEnvelope("Re: Q1 budget", imapMe, imapAda, "<budget-1@example.com>", "<budget-2@example.com>"),
+				structure: imapPlainQP,
+				headers:   "References: <budget-1@example.com>\r\n\r\n",
+				text:      "Will do =E2=80=94 tomorrow.",
+			},
+		},
+	}
+}
+
+func TestIMAPClient_InboxThreads(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+
+	threads, err := server.client().InboxThreads("newer_than:7d")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	commands := server.recorded()
+	for _, want := range []string{`EXAMINE "INBOX"`, "UID SEARCH SINCE 3-Feb-2026", `EXAMINE "Sent"`, "UID FETCH 11,12,13 (BODY.PEEK[1]<0.1024>)"} {
+		if !slices.Contains(commands, want) {
+			t.Errorf("commands = %q, want %q", commands, want)
+		}
+	}
+
+	if len(threads) != 3 {
+		t.Fatalf("got %d threads, want 3: %+v", len(threads), threads)
+	}
+
+	newsletter := threads[0]
+	if newsletter.Subject != "Go Weekly — #600" || !newsletter.Unread {
+		t.Errorf("newsletter = %+v, want decoded subject and unread", newsletter)
+	}
+	if newsletter.Snippet != "This week: generics in practice." {
+		t.Errorf("snippet = %q, want decoded base64 text part", newsletter.Snippet)
+	}
+	if strings.Join(newsletter.ListUnsubscribe, " ") != "mailto:unsub@golangweekly.com https://golangweekly.com/u/abc" {
+		t.Errorf("list-unsubscribe = %v", newsletter.ListUnsubscribe)
+	}
+	if newsletter.ListID != "weekly.golangweekly.com" {
+		t.Errorf("list-id = %q, want weekly.golangweekly.com", newsletter.ListID)
+	}
+
+	budget := threads[1]
+	if budget.ID != "budget-1@example.com" || len(budget.Messages) != 2 {
+		t.Fatalf("budget thread = %+v, want reply from Sent threaded under the first message", budget)
+	}
+	if budget.From != (EmailAddress{Name: "Ada Lovelace", Email: "ada@example.com"}) || budget.Messages[1].From.Email != "me@example.com" {
+		t.Errorf("messages = %+v, want Ada then me", budget.Messages)
+	}
+	if budget.Snippet != "Will do — tomorrow." || budget.Messages[0].Snippet != "Can you review the Q1 budget by Friday?Thanks" {
+		t.Errorf("snippets = %q / %q, want quoted-printable decoded", budget.Messages[0].Snippet, budget.Snippet)
+	}
+	if want := time.Date(2026, 2, 9, 12, 0, 0, 0, time.UTC); !budget.Date.Equal(want) {
+		t.Errorf("date = %v, want %v", budget.Date, want)
+	}
+	if strings.Join(budget.Labels, ",") != "INBOX,SENT" || budget.Unread {
+		t.Errorf("labels = %v, unread = %v, want read thread in INBOX and SENT", budget.Labels, budget.Unread)
+	}
+
+	offsite := threads[2]
+	if offsite.ID != "offsite-1@example.com" || offsite.Subject != "Offsite" || !offsite.Starred {
+		t.Errorf("offsite thread = %+v, want starred thread started from Sent", offsite)
+	}
+	if offsite.Snippet != "Sounds good & café works" {
+		t.Errorf("snippet = %q, want text of latin-1 HTML part", offsite.Snippet)
+	}
+	if strings.Join(offsite.Labels, ",") != "INBOX,SENT,STARRED,Work" {
+		t.Errorf("labels = %v, want folders, flags and keywords", offsite.Labels)
+	}
+}
+
+func TestIMAPClient_SearchCriteria(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+
+	threads, err := server.client().Threads(`in:sent is:starred from:me@example.com older_than:1d report`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(threads) != 0 {
+		t.Errorf("got %d threads, want none since no sent message is flagged", len(threads))
+	}
+
+	commands := server.recorded()
+	want := `UID SEARCH FLAGGED FROM "me@example.com" TEXT "report" BEFORE 9-Feb-2026`
+	if !slices.Contains(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+	if !slices.Contains(commands, `EXAMINE "Sent"`) || !slices.Contains(commands, "UID SEARCH BEFORE 9-Feb-2026") {
+		t.Errorf("commands = %q, want Sent searched and INBOX read for context", commands)
+	}
+}
+
+func TestIMAPClient_SentThreadsIncludeReplies(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+
+	threads, err := server.client().Threads("in:sent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(threads) != 2 {
+		t.Fatalf("got %d threads, want the two conversations with sent mail", len(threads))
+	}
+	if threads[1].ID != "offsite-1@example.com" || threads[1].Messages[1].From.Email != "grace@example.com" {
+		t.Errorf("offsite thread = %+v, want Grace's reply from INBOX as latest message", threads[1])
+	}
+}
+
+func TestIMAPClient_LoginFailure(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+	client := server.client()
+	client.password = "wrong"
+
+	_, err := client.InboxThreads("")
+	if err == nil || !strings.Contains(err.Error(), "Invalid credentials") {
+		t.Errorf("error = %v, want server's login failure", err)
+	}
+}
+
+func TestIMAPClient_MissingFolder(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+
+	_, err := server.client().Threads("label:Receipts")
+	if err == nil || !strings.Contains(err.Error(), `"Receipts"`) {
+		t.Errorf("error = %v, want missing folder named", err)
+	}
+}
+
+func TestIMAPClient_QuotedFolder(t *testing.T) {
+	folders := testIMAPFolders()
+	folders["Action Needed"] = folders["INBOX"][:1]
+	server := newFakeIMAPServer(t, folders)
+
+	threads, err := server.client().Threads(`label:"Action Needed" "Q1 budget"`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(threads) != 1 {
+		t.Errorf("got %d threads, want the message in the folder", len(threads))
+	}
+
+	commands := server.recorded()
+	if !slices.Contains(commands, `EXAMINE "Action Needed"`) || !slices.Contains(commands, `UID SEARCH TEXT "Q1 budget"`) {
+		t.Errorf("commands = %q, want the quoted folder and phrase kept whole", commands)
+	}
+}
+
+func TestIMAPClient_NonASCIIFolderAndSearch(t *testing.T) {
+	folders := testIMAPFolders()
+	folders["B&APw-ro"] = folders["INBOX"][:1]
+	server := newFakeIMAPServer(t, folders)
+
+	threads, err := server.client().Threads("label:Büro Grüße")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(threads) != 1 || !slices.Contains(threads[0].Labels, "Büro") {
+		t.Errorf("threads = %+v, want the message labelled with the folder's name", threads)
+	}
+
+	commands := server.recorded()
+	if !slices.Contains(commands, `EXAMINE "B&APw-ro"`) {
+		t.Errorf("commands = %q, want the folder name in modified UTF-7", commands)
+	}
+	if want := "UID SEARCH CHARSET UTF-8 TEXT {7}\r\nGrüße"; !slices.Contains(commands, want) {
+		t.Errorf("commands = %q, want %q", commands, want)
+	}
+}
+
+func TestIMAPMailbox(t *testing.T) {
+	for name, want := range map[string]string{
+		"INBOX":       `"INBOX"`,
+		"Tom & Jerry": `"Tom &- Jerry"`,
+		"日本語":         `"&ZeVnLIqe-"`,
+		"Büro/Ärger":  `"B&APw-ro/&AMQ-rger"`,
+	} {
+		if got := imapMailbox(name); got != want {
+			t.Errorf("imapMailbox(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestIMAPClient_Invitations(t *testing.T) {
+	folders := testIMAPFolders()
+	invite := "BEGIN:VCALENDAR\r\nMETHOD:REQUEST\r\nBEGIN:VEVENT\r\nUID:retro-7@example.com\r\n" +
+		"SUMMARY:Sprint retro\r\nDTSTART:20260212T130000Z\r\nDTEND:20260212T140000Z\r\n" +
+		"ORGANIZER;CN=Grace Hopper:mailto:grace@example.com\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	folders["INBOX"] = append(folders["INBOX"], fakeIMAPMessage{
+		uid: 14, flags: "", date: "09-Feb-2026 17:00:00 +0000",
+		envelope: imapEnvelope("Invitation: Sprint retro", imapGrace, imapMe, "NIL", "<retro-invite@example.com>"),
+		structure: `(("TEXT" "PLAIN" ("CHARSET" "UTF-8") NIL NIL "7BIT" 20 1 NIL NIL NIL)` +
+			`("TEXT" "CALENDAR" ("CHARSET" "UTF-8" "METHOD" "REQUEST") NIL NIL "BASE64" 300 4 NIL NIL NIL) "MIXED" ("BOUNDARY" "b2") NIL NIL)`,
+		headers: "\r\n",
+		text:    "You have been invited",
+		parts:   map[string]string{"2": base64.StdEncoding.EncodeToString([]byte(invite))},
+	})
+	server := newFakeIMAPServer(t, folders)
+
+	invitations, err := server.client().Invitations("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Contains(server.recorded(), "UID FETCH 14 (BODY.PEEK[2])") {
+		t.Errorf("commands = %q, want full fetch of the calendar part", server.recorded())
+	}
+	if len(invitations) != 1 {
+		t.Fatalf("got %d invitations, want 1: %+v", len(invitations), invitations)
+	}
+	got := invitations[0]
+	if got.UID != "retro-7@example.com" || got.Title != "Sprint retro" || got.ThreadID != "retro-invite@example.com" {
+		t.Errorf("invitation = %+v, want retro invite in its own thread", got)
+	}
+	if want := time.Date(2026, 2, 12, 13, 0, 0, 0, time.UTC); !got.Start.Equal(want) {
+		t.Errorf("start = %v, want %v", got.Start, want)
+	}
+	if got.Organizer != (EmailAddress{Name: "Grace Hopper", Email: "grace@example.com"}) {
+		t.Errorf("organizer = %+v", got.Organizer)
+	}
+}
+
+func TestIMAPClient_MessageBody(t *testing.T) {
+	server := newFakeIMAPServer(t, testIMAPFolders())
+
+	body, err := server.client().MessageBody("gw600@golangweekly.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !slices.Contains(server.recorded(), `UID SEARCH HEADER MESSAGE-ID "<gw600@golangweekly.com>"`) {
+		t.Errorf("commands = %q, want search by Message-ID", server.recorded())
+	}
+	if body.HTML != `<h1>This week</h1><a href="https://go.dev/blog">Generics</a>` {
+		t.Errorf("html = %q, want decoded HTML part", body.HTML)
+	}
+	if body.Text != "This week: generics in practice." {
+		t.Errorf("text = %q, want decoded plain text part", body.Text)
+	}
+}
@@ -0,0 +1,34 @@
+package platform
+
+import "strings"
+
+// WorkIssue is an issue in a work tracker such as Jira or Linear.
+type WorkIssue struct {
+	Key      string // human-readable identifier, e.g. "OPS-42"
+	Title    string
+	Status   string
+	URL      string
+	Priority int // mapped to Todoist's scale: 1 (normal) to 4 (urgent)
+}
+
+// IssueReader lists the work issues assigned to the current user in the
+// active sprint (Jira) or cycle (Linear).
+type IssueReader interface {
+	AssignedIssues() ([]WorkIssue, error)
+}
+
+// issueDescription combines the description with the due time and recurrence,
+// which Jira and Linear have no native fields for.
+func issueDescription(task TodoistTask) string {
+	var parts []string
+	if task.Description != "" {
+		parts = append(parts, task.Description)
+	}
+	if task.DueDateTime != nil {
+		parts = append(parts, "Starts at "+task.DueDateTime.Local().Format("15:04 MST"))
+	}
+	if task.DueString != "" && task.DueDateTime == nil {
+		parts = append(parts, "Repeats "+task.DueString)
+	}
+	return strings.Join(parts, "\n\n")
+}
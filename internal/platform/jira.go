@@ -0,0 +1,263 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// jiraAssignedJQL selects unfinished issues assigned to the authenticated
+// user in any open sprint.
+const jiraAssignedJQL = "assignee = currentUser() AND sprint in openSprints() AND statusCategory != Done ORDER BY priority DESC"
+
+// JiraClient creates and searches issues through the Jira Cloud REST API v3.
+type JiraClient struct {
+	siteURL    string // e.g. https://example.atlassian.net
+	email      string
+	apiToken   string
+	projectKey string
+	issueType  string
+	httpClient *http.Client
+}
+
+// NewJiraClient creates a client for the Jira Cloud site at siteURL that
+// creates issues of issueType (default "Task") in the project projectKey.
+// It authenticates with the account email and an API token.
+func NewJiraClient(siteURL, email, apiToken, projectKey, issueType string) *JiraClient {
+	if issueType == "" {
+		issueType = "Task"
+	}
+	return &JiraClient{
+		siteURL:    strings.TrimRight(siteURL, "/"),
+		email:      email,
+		apiToken:   apiToken,
+		projectKey: projectKey,
+		issueType:  issueType,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateTask creates an issue and returns its key (e.g. "OPS-42"). Jira due
+// dates have no time of day, so a timed task's start time is kept in the
+// description. A ParentID makes the issue a child of that issue key.
+func (c *JiraClient) CreateTask(task TodoistTask) (string, error) {
+	fields := jiraIssueFields{
+		Project:     jiraKey{Key: c.projectKey},
+		Summary:     task.Title,
+		IssueType:   jiraName{Name: c.issueType},
+		Description: jiraDocument(issueDescription(task)),
+		Labels:      task.Labels,
+	}
+	if task.DueDateTime != nil {
+		fields.DueDate = task.DueDateTime.Local().Format(time.DateOnly)
+	} else if task.DueDate != nil {
+		fields.DueDate = task.DueDate.Format(time.DateOnly)
+	}
+	if name := jiraPriorityName(task.Priority); name != "" {
+		fields.Priority = &jiraName{Name: name}
+	}
+	if task.ParentID != "" {
+		fields.Parent = &jiraKey{Key: task.ParentID}
+	}
+
+	var created struct {
+		Key string `json:"key"`
+	}
+	if err := c.do(http.MethodPost, "/rest/api/3/issue", nil, jiraCreateIssueRequest{Fields: fields}, &created); err != nil {
+		return "", fmt.Errorf("creating jira issue: %w", err)
+	}
+	return created.Key, nil
+}
+
+// AssignedIssues returns unfinished issues assigned to the authenticated user
+// in open sprints, highest priority first.
+func (c *JiraClient) AssignedIssues() ([]WorkIssue, error) {
+	return c.Search(jiraAssignedJQL)
+}
+
+// Search returns the issues matching a JQL query, following pagination.
+func (c *JiraClient) Search(jql string) ([]WorkIssue, error) {
+	var issues []WorkIssue
+	pageToken := ""
+	for {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {"summary,status,priority"},
+			"maxResults": {"100"},
+		}
+		if pageToken != "" {
+			query.Set("nextPageToken", pageToken)
+		}
+
+		var page jiraSearchResponse
+		if err := c.do(http.MethodGet, "/rest/api/3/search/jql", query, nil, &page); err != nil {
+			return nil, fmt.Errorf("searching jira issues: %w", err)
+		}
+
+		for _, issue := range page.Issues {
+			issues = append(issues, WorkIssue{
+				Key:      issue.Key,
+				Title:    issue.Fields.Summary,
+				Status:   issue.Fields.Status.Name,
+				URL:      c.siteURL + "/browse/" + issue.Key,
+				Priority: jiraPriorityLevel(issue.Fields.Priority.Name),
+			})
+		}
+
+		if page.IsLast || page.NextPageToken == "" {
+			return issues, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+func (c *JiraClient) do(method, path string, query url.Values, payload, v any) error {
+	endpoint := c.siteURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("marshalling request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Jira API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	return nil
+}
+
+// jiraDocument wraps plain text in an Atlassian Document Format document,
+// one paragraph per blank-line-separated block, with single newlines as
+// hard breaks. ADF rejects empty text nodes, so blank blocks are skipped and
+// text with nothing but whitespace returns nil.
+func jiraDocument(text string) *jiraDoc {
+	doc := &jiraDoc{Type: "doc", Version: 1}
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		para = strings.Trim(para, "\n")
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		var nodes []jiraNode
+		for i, line := range strings.Split(para, "\n") {
+			if i > 0 {
+				nodes = append(nodes, jiraNode{Type: "hardBreak"})
+			}
+			if line != "" {
+				nodes = append(nodes, jiraNode{Type: "text", Text: line})
+			}
+		}
+		doc.Content = append(doc.Content, jiraNode{Type: "paragraph", Content: nodes})
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	return doc
+}
+
+// jiraPriorityName maps Todoist priorities onto Jira's default scheme.
+// Normal priority is left to the project default.
+func jiraPriorityName(priority int) string {
+	switch priority {
+	case 4:
+		return "Highest"
+	case 3:
+		return "High"
+	case 2:
+		return "Medium"
+	}
+	return ""
+}
+
+// jiraPriorityLevel maps Jira's default priority names back to Todoist's scale.
+func jiraPriorityLevel(name string) int {
+	switch name {
+	case "Highest", "Blocker", "Critical":
+		return 4
+	case "High", "Major":
+		return 3
+	case "Medium":
+		return 2
+	}
+	return 1
+}
+
+type jiraCreateIssueRequest struct {
+	Fields jiraIssueFields `json:"fields"`
+}
+
+type jiraIssueFields struct {
+	Project     jiraKey   `json:"project"`
+	Summary     string    `json:"summary"`
+	IssueType   jiraName  `json:"issuetype"`
+	Description *jiraDoc  `json:"description,omitempty"`
+	DueDate     string    `json:"duedate,omitempty"`
+	Priority    *jiraName `json:"priority,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+	Parent      *jiraKey  `json:"parent,omitempty"`
+}
+
+type jiraKey struct {
+	Key string `json:"key"`
+}
+
+type jiraName struct {
+	Name string `json:"name"`
+}
+
+type jiraDoc struct {
+	Type    string     `json:"type"`
+	Version int        `json:"version"`
+	Content []jiraNode `json:"content"`
+}
+
+type jiraNode struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text,omitempty"`
+	Content []jiraNode `json:"content,omitempty"`
+}
+
+type jiraSearchResponse struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string   `json:"summary"`
+			Status  jiraName `json:"status"`
+			// Priority is null when the field is hidden; Name is then empty.
+			Priority jiraName `json:"priority"`
+		} `json:"fields"`
+	} `json:"issues"`
+	NextPageToken string `json:"nextPageToken"`
+	IsLast        bool   `json:"isLast"`
+}
@@ -0,0 +1,158 @@
+package platform
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJiraClient_CreateTask(t *testing.T) {
+	var received jiraCreateIssueRequest
+	var receivedPath string
+	var user, pass string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedPath = r.URL.Path
+		user, pass, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "10001", "key": "OPS-42"}`))
+	}))
+	defer server.Close()
+
+	client := NewJiraClient(server.URL+"/", "me@example.com", "jira-token", "OPS", "")
+	client.httpClient = server.Client()
+
+	due := time.Date(2026, 2, 6, 10, 0, 0, 0, time.Local)
+	key, err := client.CreateTask(TodoistTask{
+		Title:       "Standup",
+		Description: "https://meet.google.com/abc",
+		DueDateTime: &due,
+		Priority:    3,
+		Labels:      []string{"meeting"},
+		ParentID:    "OPS-1",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if key != "OPS-42" {
+		t.Errorf("key = %q, want OPS-42", key)
+	}
+	if receivedPath != "/rest/api/3/issue" {
+		t.Errorf("path = %q, want /rest/api/3/issue", receivedPath)
+	}
+	if user != "me@example.com" || pass != "jira-token" {
+		t.Errorf("basic auth = %q/%q, want me@example.com/jira-token", user, pass)
+	}
+
+	f := received.Fields
+	if f.Project.Key != "OPS" || f.IssueType.Name != "Task" || f.Summary != "Standup" {
+		t.Errorf("project/type/summary = %q/%q/%q, want OPS/Task/Standup", f.Project.Key, f.IssueType.Name, f.Summary)
+	}
+	if f.DueDate != "2026-02-06" {
+		t.Errorf("duedate = %q, want 2026-02-06", f.DueDate)
+	}
+	if f.Priority == nil || f.Priority.Name != "High" {
+		t.Errorf("priority = %v, want High", f.Priority)
+	}
+	if f.Parent == nil || f.Parent.Key != "OPS-1" {
+		t.Errorf("parent = %v, want OPS-1", f.Parent)
+	}
+	if f.Description == nil || len(f.Description.Content) != 2 {
+		t.Fatalf("description = %+v, want 2 paragraphs", f.Description)
+	}
+	if got := f.Description.Content[1].Content[0].Text; !strings.HasPrefix(got, "Starts at 10:00") {
+		t.Errorf("second paragraph = %q, want start time", got)
+	}
+}
+
+func TestJiraDocument(t *testing.T) {
+	doc := jiraDocument("Agenda:\nbudget\n\n\n\nNotes\n\n  \n")
+	want := []jiraNode{
+		{Type: "paragraph", Content: []jiraNode{{Type: "text", Text: "Agenda:"}, {Type: "hardBreak"}, {Type: "text", Text: "budget"}}},
+		{Type: "paragraph", Content: []jiraNode{{Type: "text", Text: "Notes"}}},
+	}
+	if doc == nil || !reflect.DeepEqual(doc.Content, want) {
+		t.Errorf("document = %+v, want %+v", doc, want)
+	}
+
+	if doc := jiraDocument("\n\n \n"); doc != nil {
+		t.Errorf("document = %+v, want nil for blank text", doc)
+	}
+}
+
+func TestJiraClient_AssignedIssues(t *testing.T) {
+	var queries []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/search/jql" {
+			t.Errorf("path = %q, want /rest/api/3/search/jql", r.URL.Path)
+		}
+		queries = append(queries, r.URL.Query().Get("jql"))
+
+		if r.URL.Query().Get("nextPageToken") == "" {
+			w.Write([]byte(`{
+				"issues": [{"key": "OPS-1", "fields": {"summary": "Fix deploys", "status": {"name": "In Progress"}, "priority": {"name": "Highest"}}}],
+				"nextPageToken": "page-2"
+			}`))
+			return
+		}
+		w.Write([]byte(`{
+			"issues": [{"key": "OPS-2", "fields": {"summary": "Write runbook", "status": {"name": "To Do"}, "priority": null}}],
+			"isLast": true
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewJiraClient(server.URL, "me@example.com", "jira-token", "OPS", "")
+	client.httpClient = server.Client()
+
+	issues, err := client.AssignedIssues()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("requests = %d, want 2 (paginated)", len(queries))
+	}
+	if !strings.Contains(queries[0], "sprint in openSprints()") || !strings.Contains(queries[0], "currentUser()") {
+		t.Errorf("jql = %q, want current user's open sprint issues", queries[0])
+	}
+
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	want := WorkIssue{Key: "OPS-1", Title: "Fix deploys", Status: "In Progress", URL: server.URL + "/browse/OPS-1", Priority: 4}
+	if issues[0] != want {
+		t.Errorf("issues[0] = %+v, want %+v", issues[0], want)
+	}
+	if issues[1].Priority != 1 {
+		t.Errorf("issue without priority = %d, want 1", issues[1].Priority)
+	}
+}
+
+func TestJiraClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorMessages": [], "errors": {"project": "valid project is required"}}`))
+	}))
+	defer server.Close()
+
+	client := NewJiraClient(server.URL, "me@example.com", "jira-token", "NOPE", "")
+	client.httpClient = server.Client()
+
+	_, err := client.CreateTask(TodoistTask{Title: "Test"})
+	if err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if !strings.Contains(err.Error(), "400") {
+		t.Errorf("error should mention status code, got: %v", err)
+	}
+}
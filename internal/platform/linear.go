@@ -0,0 +1,214 @@
+package platform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const linearCreateIssueMutation = `mutation IssueCreate($input: IssueCreateInput!) {
+  issueCreate(input: $input) { success issue { id identifier } }
+}`
+
+// linearAssignedIssuesQuery selects unfinished issues assigned to the
+// authenticated user in their team's active cycle.
+const linearAssignedIssuesQuery = `query AssignedIssues($after: String) {
+  viewer {
+    assignedIssues(first: 100, after: $after, filter: {
+      cycle: { isActive: { eq: true } }
+      state: { type: { nin: ["completed", "canceled"] } }
+    }) {
+      nodes { identifier title url priority state { name } }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+
+// LinearClient creates and lists issues through the Linear GraphQL API.
+type LinearClient struct {
+	apiKey     string
+	teamID     string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewLinearClient creates a client that creates issues in the team teamID,
+// authenticating with a personal API key.
+func NewLinearClient(apiKey, teamID string) *LinearClient {
+	return &LinearClient{
+		apiKey:     apiKey,
+		teamID:     teamID,
+		endpoint:   "https://api.linear.app/graphql",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreateTask creates an issue and returns its ID. Linear due dates have no
+// time of day, so a timed task's start time is kept in the description.
+// Labels are not applied because Linear only accepts label IDs.
+func (c *LinearClient) CreateTask(task TodoistTask) (string, error) {
+	input := linearIssueInput{
+		TeamID:      c.teamID,
+		Title:       task.Title,
+		Description: issueDescription(task),
+		Priority:    linearPriority(task.Priority),
+		ParentID:    task.ParentID,
+	}
+	if task.DueDateTime != nil {
+		input.DueDate = task.DueDateTime.Local().Format(time.DateOnly)
+	} else if task.DueDate != nil {
+		input.DueDate = task.DueDate.Format(time.DateOnly)
+	}
+
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID string `json:"id"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	if err := c.query(linearCreateIssueMutation, map[string]any{"input": input}, &data); err != nil {
+		return "", fmt.Errorf("creating linear issue: %w", err)
+	}
+	if !data.IssueCreate.Success {
+		return "", fmt.Errorf("creating linear issue: not successful")
+	}
+	return data.IssueCreate.Issue.ID, nil
+}
+
+// AssignedIssues returns unfinished issues assigned to the authenticated user
+// in the active cycle.
+func (c *LinearClient) AssignedIssues() ([]WorkIssue, error) {
+	var issues []WorkIssue
+	var after *string
+	for {
+		var data struct {
+			Viewer struct {
+				AssignedIssues struct {
+					Nodes []struct {
+						Identifier string `json:"identifier"`
+						Title      string `json:"title"`
+						URL        string `json:"url"`
+						Priority   int    `json:"priority"`
+						State      struct {
+							Name string `json:"name"`
+						} `json:"state"`
+					} `json:"nodes"`
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+				} `json:"assignedIssues"`
+			} `json:"viewer"`
+		}
+		if err := c.query(linearAssignedIssuesQuery, map[string]any{"after": after}, &data); err != nil {
+			return nil, fmt.Errorf("fetching linear issues: %w", err)
+		}
+
+		page := data.Viewer.AssignedIssues
+		for _, node := range page.Nodes {
+			issues = append(issues, WorkIssue{
+				Key:      node.Identifier,
+				Title:    node.Title,
+				Status:   node.State.Name,
+				URL:      node.URL,
+				Priority: linearPriorityLevel(node.Priority),
+			})
+		}
+
+		if !page.PageInfo.HasNextPage {
+			return issues, nil
+		}
+		cursor := page.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
+// query posts a GraphQL request and decodes its data into v.
+func (c *LinearClient) query(query string, variables map[string]any, v any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("marshalling request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Linear API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return fmt.Errorf("Linear API error: %s", strings.Join(messages, "; "))
+	}
+
+	if err := json.Unmarshal(result.Data, v); err != nil {
+		return fmt.Errorf("parsing response data: %w", err)
+	}
+	return nil
+}
+
+// linearPriority maps Todoist priorities to Linear's scale, where 1 is urgent,
+// 4 is low and 0 means no priority.
+func linearPriority(priority int) int {
+	switch priority {
+	case 4:
+		return 1
+	case 3:
+		return 2
+	case 2:
+		return 3
+	}
+	return 0
+}
+
+// linearPriorityLevel maps Linear priorities back to Todoist's scale.
+func linearPriorityLevel(priority int) int {
+	switch priority {
+	case 1:
+		return 4
+	case 2:
+		return 3
+	case 3:
+		return 2
+	}
+	return 1
+}
+
+type linearIssueInput struct {
+	TeamID      string `json:"teamId"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Priority    int    `json:"priority,omitempty"`
+	DueDate     string `json:"dueDate,omitempty"`
+	ParentID    string `json:"parentId,omitempty"`
+}
@@ -0,0 +1,128 @@
+package platform
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type linearTestRequest struct {
+	Query     string          `json:"query"`
+	Variables json.RawMessage `json:"variables"`
+}
+
+func TestLinearClient_CreateTask(t *testing.T) {
+	var received struct {
+		Input linearIssueInput `json:"input"`
+	}
+	var receivedAuth string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedAuth = r.Header.Get("Authorization")
+		var req linearTestRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !strings.Contains(req.Query, "issueCreate") {
+			t.Errorf("query = %q, want issueCreate mutation", req.Query)
+		}
+		json.Unmarshal(req.Variables, &received)
+		w.Write([]byte(`{"data": {"issueCreate": {"success": true, "issue": {"id": "uuid-1", "identifier": "ENG-7"}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewLinearClient("lin_api_key", "team-1")
+	client.endpoint = server.URL
+	client.httpClient = server.Client()
+
+	due := time.Date(2026, 2, 6, 0, 0, 0, 0, time.Local)
+	id, err := client.CreateTask(TodoistTask{Title: "Review PR", DueDate: &due, Priority: 4, ParentID: "uuid-0"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if id != "uuid-1" {
+		t.Errorf("id = %q, want uuid-1", id)
+	}
+	if receivedAuth != "lin_api_key" {
+		t.Errorf("auth = %q, want lin_api_key", receivedAuth)
+	}
+	want := linearIssueInput{TeamID: "team-1", Title: "Review PR", Priority: 1, DueDate: "2026-02-06", ParentID: "uuid-0"}
+	if received.Input != want {
+		t.Errorf("input = %+v, want %+v", received.Input, want)
+	}
+}
+
+func TestLinearClient_AssignedIssues(t *testing.T) {
+	var cursors []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req linearTestRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !strings.Contains(req.Query, "isActive") {
+			t.Errorf("query should filter on the active cycle, got %q", req.Query)
+		}
+		var vars struct {
+			After *string `json:"after"`
+		}
+		json.Unmarshal(req.Variables, &vars)
+
+		if vars.After == nil {
+			cursors = append(cursors, "")
+			w.Write([]byte(`{"data": {"viewer": {"assignedIssues": {
+				"nodes": [{"identifier": "ENG-1", "title": "Ship search", "url": "https://linear.app/x/issue/ENG-1", "priority": 2, "state": {"name": "In Progress"}}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}
+			}}}}`))
+			return
+		}
+		cursors = append(cursors, *vars.After)
+		w.Write([]byte(`{"data": {"viewer": {"assignedIssues": {
+			"nodes": [{"identifier": "ENG-2", "title": "Triage bugs", "url": "https://linear.app/x/issue/ENG-2", "priority": 0, "state": {"name": "Todo"}}],
+			"pageInfo": {"hasNextPage": false}
+		}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewLinearClient("lin_api_key", "")
+	client.endpoint = server.URL
+	client.httpClient = server.Client()
+
+	issues, err := client.AssignedIssues()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(cursors, ",") != ",c1" {
+		t.Errorf("cursors = %v, want [\"\" c1]", cursors)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("got %d issues, want 2", len(issues))
+	}
+	want := WorkIssue{Key: "ENG-1", Title: "Ship search", Status: "In Progress", URL: "https://linear.app/x/issue/ENG-1", Priority: 3}
+	if issues[0] != want {
+		t.Errorf("issues[0] = %+v, want %+v", issues[0], want)
+	}
+	if issues[1].Priority != 1 {
+		t.Errorf("issue without priority = %d, want 1", issues[1].Priority)
+	}
+}
+
+func TestLinearClient_GraphQLError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": [{"message": "Entity not found: Team"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewLinearClient("lin_api_key", "missing")
+	client.endpoint = server.URL
+	client.httpClient = server.Client()
+
+	_, err := client.CreateTask(TodoistTask{Title: "Test"})
+	if err == nil {
+		t.Fatal("expected error for GraphQL errors")
+	}
+	if !strings.Contains(err.Error(), "Entity not found") {
+		t.Errorf("error should include the GraphQL message, got: %v", err)
+	}
+}
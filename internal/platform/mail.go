@@ -0,0 +1,88 @@
+package platform
+
+import "time"
+
+// EmailAddress is a parsed mailbox such as "Ada Lovelace <ada@example.com>".
+type EmailAddress struct {
+	Name  string
+	Email string
+}
+
+// EmailMessage is a single message within a thread.
+type EmailMessage struct {
+	ID      string
+	From    EmailAddress
+	To      []EmailAddress
+	Cc      []EmailAddress
+	Subject string
+	Snippet string
+	Date    time.Time
+	Labels  []string
+}
+
+// EmailThread is a conversation, with its messages in chronological order.
+type EmailThread struct {
+	ID      string
+	Subject string       // subject of the first message
+	From    EmailAddress // sender of the first message
+	Snippet string       // snippet of the latest message
+	Date    time.Time    // date of the latest message
+	// Labels is the union of the messages' labels, by name
+	// (e.g., "INBOX", "UNREAD", "Receipts").
+	Labels  []string
+	Unread  bool
+	Starred bool
+	// ListUnsubscribe holds the mailto: and https: targets from the
+	// List-Unsubscribe header, if any message in the thread carries one.
+	ListUnsubscribe []string
+	// ListID identifies the mailing list from the List-Id header, e.g.
+	// "weekly.golangweekly.com", if any message in the thread carries one.
+	ListID   string
+	Messages []EmailMessage
+}
+
+// MailReader lists mail threads. Queries use Gmail search syntax; other
+// backends translate the parts they support.
+type MailReader interface {
+	// InboxThreads returns non-archived threads, newest first, narrowed by an
+	// optional Gmail search query (e.g., "is:unread newer_than:7d").
+	InboxThreads(query string) ([]EmailThread, error)
+	// Threads returns threads matching a Gmail search query anywhere in the
+	// mailbox (e.g., "in:sent newer_than:14d").
+	Threads(query string) ([]EmailThread, error)
+}
+
+// MessageBody is the content of a message. Either part may be empty.
+type MessageBody struct {
+	HTML string
+	Text string
+}
+
+// MessageBodyReader fetches the full content of messages, which MailReader
+// only summarises with a snippet.
+type MessageBodyReader interface {
+	// MessageBody returns the body of the message with the ID of an
+	// EmailMessage.
+	MessageBody(messageID string) (MessageBody, error)
+}
+
+// EmailInvitation is a calendar invitation attached to an email.
+type EmailInvitation struct {
+	Invitation
+	ThreadID string
+	Received time.Time
+}
+
+// InvitationReader finds calendar invitations in the inbox.
+type InvitationReader interface {
+	// Invitations returns the METHOD:REQUEST invitations attached to inbox
+	// mail, narrowed by an optional search query, newest first.
+	Invitations(query string) ([]EmailInvitation, error)
+}
+
+// GmailModifier changes labels on Gmail threads. Archiving removes the
+// INBOX label and marking as read removes UNREAD.
+type GmailModifier interface {
+	RemoveLabel(threadID, label string) error
+	ModifyLabels(threadID string, add, remove []string) error
+}
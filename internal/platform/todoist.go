@@ -2,16 +2,90 @@ package platform
 
 import "time"
 
-// TodoistTask represents a task to be created in Todoist.
+// TodoistTask represents a task in Todoist.
 type TodoistTask struct {
+	ID          string // set on tasks read from Todoist, empty when creating
 	Title       string
 	Description string
 	ProjectID   string
 	DueDateTime *time.Time // nil for all-day events or tasks without a specific time
 	Priority    int        // Todoist priority: 1 (normal) to 4 (urgent)
+	Labels      []string
+	// DueDate is the calendar day the task is due on, in local time.
+	// It is set for tasks read from Todoist that have any due date.
+	DueDate     *time.Time
+	IsRecurring bool
+	CreatedAt   time.Time
+	Duration    time.Duration // Todoist's planned duration; zero when unset
+	ParentID    string        // makes the task a sub-task of another task
+	// DueString is a natural-language due date such as "every friday",
+	// used to create recurring tasks. Ignored when DueDateTime is set.
+	DueString string
 }
 
-// TaskCreator creates tasks in Todoist.
+// TodoistProject is a project that groups Todoist tasks.
+type TodoistProject struct {
+	ID      string
+	Name    string
+	IsInbox bool
+}
+
+// TaskCreator creates tasks in Todoist or an alternative task backend
+// (local file, GitHub Issues, CalDAV).
 type TaskCreator interface {
-	CreateTask(task TodoistTask) error
+	// CreateTask creates the task and returns its ID.
+	CreateTask(task TodoistTask) (string, error)
+}
+
+// TaskReader queries active tasks and projects from Todoist.
+type TaskReader interface {
+	// Tasks returns active tasks matching a Todoist filter query
+	// (e.g., "overdue | today"). An empty filter returns all active tasks.
+	Tasks(filter string) ([]TodoistTask, error)
+	Projects() ([]TodoistProject, error)
+}
+
+// CompletedTask is a task that was checked off in Todoist.
+type CompletedTask struct {
+	ID          string
+	Title       string
+	ProjectID   string
+	Description string
+	CompletedAt time.Time
+}
+
+// CompletedTaskReader lists tasks completed since a point in time.
+type CompletedTaskReader interface {
+	CompletedTasks(since time.Time) ([]CompletedTask, error)
+}
+
+// TaskActivityReader reads Todoist's activity log, which needs a Pro plan.
+type TaskActivityReader interface {
+	// Reschedules counts, by task ID, the due date changes since a point in
+	// time.
+	Reschedules(since time.Time) (map[string]int, error)
+}
+
+// TaskComment is a comment attached to a Todoist task.
+type TaskComment struct {
+	ID      string
+	TaskID  string
+	Content string
+}
+
+// TaskCommenter reads and writes comments on Todoist tasks.
+type TaskCommenter interface {
+	Comments(taskID string) ([]TaskComment, error)
+	AddComment(taskID, content string) (string, error)
+	UpdateComment(commentID, content string) error
+}
+
+// TaskCloser completes Todoist tasks.
+type TaskCloser interface {
+	CloseTask(taskID string) error
+}
+
+// TaskRescheduler moves existing Todoist tasks to another day.
+type TaskRescheduler interface {
+	RescheduleTask(task TodoistTask, due time.Time) error
 }
@@ -6,44 +6,291 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"time"
 )
 
-// TodoistClient creates tasks via the Todoist REST API v2.
+// TodoistClient reads and creates tasks via the Todoist REST API v2.
+// Completed tasks and the activity log are only available from the Sync
+// API v9.
 type TodoistClient struct {
-	apiToken   string
-	baseURL    string
-	httpClient *http.Client
+	apiToken    string
+	baseURL     string
+	syncBaseURL string
+	httpClient  *http.Client
 }
 
 // NewTodoistClient creates a client with the given API token.
 func NewTodoistClient(apiToken string) *TodoistClient {
 	return &TodoistClient{
-		apiToken:   apiToken,
-		baseURL:    "https://api.todoist.com/rest/v2",
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiToken:    apiToken,
+		baseURL:     "https://api.todoist.com/rest/v2",
+		syncBaseURL: "https://api.todoist.com/sync/v9",
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
 	}
 }
 
-func (c *TodoistClient) CreateTask(task TodoistTask) error {
+func (c *TodoistClient) CreateTask(task TodoistTask) (string, error) {
 	payload := todoistCreateTaskRequest{
 		Content:     task.Title,
 		Description: task.Description,
 		ProjectID:   task.ProjectID,
+		ParentID:    task.ParentID,
 		Priority:    task.Priority,
+		Labels:      task.Labels,
 	}
 
 	if task.DueDateTime != nil {
 		s := task.DueDateTime.Format(time.RFC3339)
 		payload.DueDatetime = &s
+	} else if task.DueDate != nil {
+		s := task.DueDate.Format(time.DateOnly)
+		payload.DueDate = &s
+	} else if task.DueString != "" {
+		payload.DueString = task.DueString
 	}
 
+	var created todoistTaskItem
+	if err := c.post("/tasks", payload, &created); err != nil {
+		return "", fmt.Errorf("creating todoist task: %w", err)
+	}
+
+	return created.ID, nil
+}
+
+// RescheduleTask moves a task to a new due date. A task with a due time keeps
+// its time of day; a date-only task stays date-only. Recurring tasks are
+// refused, since a plain due date replaces their recurrence.
+func (c *TodoistClient) RescheduleTask(task TodoistTask, due time.Time) error {
+	if task.IsRecurring {
+		return fmt.Errorf("rescheduling todoist task %s: recurring tasks cannot be moved without losing their recurrence", task.ID)
+	}
+
+	payload := todoistUpdateTaskRequest{}
+	if task.DueDateTime != nil {
+		local := task.DueDateTime.Local()
+		moved := time.Date(due.Year(), due.Month(), due.Day(), local.Hour(), local.Minute(), 0, 0, time.Local)
+		s := moved.Format(time.RFC3339)
+		payload.DueDatetime = &s
+	} else {
+		s := due.Format(time.DateOnly)
+		payload.DueDate = &s
+	}
+
+	if err := c.post("/tasks/"+url.PathEscape(task.ID), payload, nil); err != nil {
+		return fmt.Errorf("rescheduling todoist task %s: %w", task.ID, err)
+	}
+
+	return nil
+}
+
+type todoistCreateTaskRequest struct {
+	Content     string   `json:"content"`
+	Description string   `json:"description,omitempty"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	ParentID    string   `json:"parent_id,omitempty"`
+	Priority    int      `json:"priority"`
+	Labels      []string `json:"labels,omitempty"`
+	DueDate     *string  `json:"due_date,omitempty"`
+	DueDatetime *string  `json:"due_datetime,omitempty"`
+	DueString   string   `json:"due_string,omitempty"`
+}
+
+type todoistUpdateTaskRequest struct {
+	DueDate     *string `json:"due_date,omitempty"`
+	DueDatetime *string `json:"due_datetime,omitempty"`
+}
+
+func (c *TodoistClient) Tasks(filter string) ([]TodoistTask, error) {
+	query := url.Values{}
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+
+	var items []todoistTaskItem
+	if err := c.get("/tasks", query, &items); err != nil {
+		return nil, fmt.Errorf("listing todoist tasks: %w", err)
+	}
+
+	return parseTodoistTasks(items), nil
+}
+
+func (c *TodoistClient) Projects() ([]TodoistProject, error) {
+	var items []todoistProjectItem
+	if err := c.get("/projects", nil, &items); err != nil {
+		return nil, fmt.Errorf("listing todoist projects: %w", err)
+	}
+
+	projects := make([]TodoistProject, 0, len(items))
+	for _, item := range items {
+		projects = append(projects, TodoistProject{
+			ID:      item.ID,
+			Name:    item.Name,
+			IsInbox: item.IsInboxProject,
+		})
+	}
+	return projects, nil
+}
+
+func (c *TodoistClient) Comments(taskID string) ([]TaskComment, error) {
+	var items []todoistCommentItem
+	if err := c.get("/comments", url.Values{"task_id": {taskID}}, &items); err != nil {
+		return nil, fmt.Errorf("listing comments on todoist task %s: %w", taskID, err)
+	}
+
+	comments := make([]TaskComment, 0, len(items))
+	for _, item := range items {
+		comments = append(comments, TaskComment{ID: item.ID, TaskID: item.TaskID, Content: item.Content})
+	}
+	return comments, nil
+}
+
+func (c *TodoistClient) AddComment(taskID, content string) (string, error) {
+	var created todoistCommentItem
+	payload := todoistCommentItem{TaskID: taskID, Content: content}
+	if err := c.post("/comments", payload, &created); err != nil {
+		return "", fmt.Errorf("commenting on todoist task %s: %w", taskID, err)
+	}
+	return created.ID, nil
+}
+
+func (c *TodoistClient) UpdateComment(commentID, content string) error {
+	payload := todoistCommentItem{Content: content}
+	if err := c.post("/comments/"+url.PathEscape(commentID), payload, nil); err != nil {
+		return fmt.Errorf("updating todoist comment %s: %w", commentID, err)
+	}
+	return nil
+}
+
+func (c *TodoistClient) CloseTask(taskID string) error {
+	if err := c.post("/tasks/"+url.PathEscape(taskID)+"/close", struct{}{}, nil); err != nil {
+		return fmt.Errorf("closing todoist task %s: %w", taskID, err)
+	}
+	return nil
+}
+
+// todoistCompletedLimit is the most tasks completed/get_all returns at once.
+const todoistCompletedLimit = 200
+
+// CompletedTasks pages through the tasks completed since since until the
+// server returns a short page.
+func (c *TodoistClient) CompletedTasks(since time.Time) ([]CompletedTask, error) {
+	var tasks []CompletedTask
+	for offset := 0; ; offset += todoistCompletedLimit {
+		query := url.Values{
+			"since":  {since.UTC().Format("2006-01-02T15:04:05")},
+			"limit":  {strconv.Itoa(todoistCompletedLimit)},
+			"offset": {strconv.Itoa(offset)},
+			// Annotated items carry the task's description.
+			"annotate_items": {"true"},
+		}
+
+		var result todoistCompletedResponse
+		if err := c.getFrom(c.syncBaseURL, "/completed/get_all", query, &result); err != nil {
+			return nil, fmt.Errorf("listing completed todoist tasks: %w", err)
+		}
+
+		for _, item := range result.Items {
+			task := CompletedTask{
+				ID:        item.TaskID,
+				Title:     item.Content,
+				ProjectID: item.ProjectID,
+			}
+			if item.ItemObject != nil {
+				task.Description = item.ItemObject.Description
+			}
+			if t, err := time.Parse(time.RFC3339, item.CompletedAt); err == nil {
+				task.CompletedAt = t
+			}
+			tasks = append(tasks, task)
+		}
+		if len(result.Items) < todoistCompletedLimit {
+			return tasks, nil
+		}
+	}
+}
+
+// todoistActivityLimit is the most events activity/get returns at once.
+const todoistActivityLimit = 100
+
+// Reschedules reads the task updates in the activity log, a page per week
+// back to since, and counts those that moved a due date.
+func (c *TodoistClient) Reschedules(since time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	weeks := int(time.Since(since).Hours()/(24*7)) + 1
+	for page := 0; page < weeks; page++ {
+		for offset := 0; ; offset += todoistActivityLimit {
+			query := url.Values{
+				"object_type": {"item"},
+				"event_type":  {"updated"},
+				"page":        {strconv.Itoa(page)},
+				"limit":       {strconv.Itoa(todoistActivityLimit)},
+				"offset":      {strconv.Itoa(offset)},
+			}
+			var result todoistActivityResponse
+			if err := c.getFrom(c.syncBaseURL, "/activity/get", query, &result); err != nil {
+				return nil, fmt.Errorf("reading todoist activity: %w", err)
+			}
+
+			for _, event := range result.Events {
+				if t, err := time.Parse(time.RFC3339, event.EventDate); err == nil && t.Before(since) {
+					continue
+				}
+				last, due := event.ExtraData.LastDueDate, event.ExtraData.DueDate
+				if last != nil && (due == nil || *due != *last) {
+					counts[event.ObjectID]++
+				}
+			}
+			if len(result.Events) < todoistActivityLimit {
+				break
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (c *TodoistClient) get(path string, query url.Values, v any) error {
+	return c.getFrom(c.baseURL, path, query, v)
+}
+
+func (c *TodoistClient) getFrom(baseURL, path string, query url.Values, v any) error {
+	endpoint := baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("creating todoist request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Todoist API returned %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("parsing todoist response: %w", err)
+	}
+	return nil
+}
+
+// post sends a JSON payload and decodes the response into v, if non-nil.
+func (c *TodoistClient) post(path string, payload, v any) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshalling task: %w", err)
+		return fmt.Errorf("marshalling todoist request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/tasks", bytes.NewReader(body))
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+path, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("creating todoist request: %w", err)
 	}
@@ -52,22 +299,141 @@ func (c *TodoistClient) CreateTask(task TodoistTask) error {
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("creating todoist task: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
 		respBody, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("Todoist API returned %d: %s", resp.StatusCode, string(respBody))
 	}
 
+	if v == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+		return fmt.Errorf("parsing todoist response: %w", err)
+	}
 	return nil
 }
 
-type todoistCreateTaskRequest struct {
-	Content     string  `json:"content"`
-	Description string  `json:"description,omitempty"`
-	ProjectID   string  `json:"project_id"`
-	Priority    int     `json:"priority"`
-	DueDatetime *string `json:"due_datetime,omitempty"`
+// Todoist REST API response types
+
+type todoistTaskItem struct {
+	ID          string           `json:"id"`
+	ProjectID   string           `json:"project_id"`
+	ParentID    string           `json:"parent_id"`
+	Content     string           `json:"content"`
+	Description string           `json:"description"`
+	Priority    int              `json:"priority"`
+	Labels      []string         `json:"labels"`
+	Due         *todoistDue      `json:"due"`
+	Duration    *todoistDuration `json:"duration"`
+	CreatedAt   string           `json:"created_at"`
+}
+
+type todoistDue struct {
+	Date        string `json:"date"`
+	Datetime    string `json:"datetime"`
+	IsRecurring bool   `json:"is_recurring"`
+}
+
+type todoistDuration struct {
+	Amount int    `json:"amount"`
+	Unit   string `json:"unit"` // "minute" or "day"
+}
+
+type todoistCommentItem struct {
+	ID      string `json:"id,omitempty"`
+	TaskID  string `json:"task_id,omitempty"`
+	Content string `json:"content"`
+}
+
+type todoistCompletedResponse struct {
+	Items []todoistCompletedItem `json:"items"`
+}
+
+type todoistCompletedItem struct {
+	TaskID      string `json:"task_id"`
+	Content     string `json:"content"`
+	ProjectID   string `json:"project_id"`
+	CompletedAt string `json:"completed_at"`
+	ItemObject  *struct {
+		Description string `json:"description"`
+	} `json:"item_object"`
+}
+
+type todoistActivityResponse struct {
+	Events []todoistActivityEvent `json:"events"`
+}
+
+type todoistActivityEvent struct {
+	ObjectID  string `json:"object_id"`
+	EventDate string `json:"event_date"`
+	// ExtraData carries the previous due date when an update moved it.
+	ExtraData struct {
+		DueDate     *string `json:"due_date"`
+		LastDueDate *string `json:"last_due_date"`
+	} `json:"extra_data"`
+}
+
+type todoistProjectItem struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	IsInboxProject bool   `json:"is_inbox_project"`
+}
+
+func parseTodoistTasks(items []todoistTaskItem) []TodoistTask {
+	tasks := make([]TodoistTask, 0, len(items))
+	for _, item := range items {
+		task := TodoistTask{
+			ID:          item.ID,
+			Title:       item.Content,
+			Description: item.Description,
+			ProjectID:   item.ProjectID,
+			ParentID:    item.ParentID,
+			Priority:    item.Priority,
+			Labels:      item.Labels,
+		}
+
+		if item.Duration != nil {
+			unit := time.Minute
+			if item.Duration.Unit == "day" {
+				unit = 24 * time.Hour
+			}
+			task.Duration = time.Duration(item.Duration.Amount) * unit
+		}
+
+		if t, err := time.Parse(time.RFC3339, item.CreatedAt); err == nil {
+			task.CreatedAt = t
+		}
+
+		if item.Due != nil {
+			task.IsRecurring = item.Due.IsRecurring
+			if d, err := time.ParseInLocation(time.DateOnly, item.Due.Date, time.Local); err == nil {
+				task.DueDate = &d
+			}
+			if dt, ok := parseTodoistDatetime(item.Due.Datetime); ok {
+				task.DueDateTime = &dt
+			}
+		}
+
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// parseTodoistDatetime handles both fixed (UTC) and floating due datetimes.
+// Floating datetimes have no offset and are interpreted in local time.
+func parseTodoistDatetime(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", s, time.Local); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
 }
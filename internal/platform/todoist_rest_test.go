@@ -2,9 +2,12 @@ package platform
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -28,7 +31,7 @@ func TestTodoistClient_CreateTask(t *testing.T) {
 	}
 
 	dueTime := time.Date(2026, 2, 6, 10, 0, 0, 0, time.UTC)
-	err := client.CreateTask(TodoistTask{
+	_, err := client.CreateTask(TodoistTask{
 		Title:       "Standup",
 		Description: "https://meet.google.com/abc",
 		ProjectID:   "12345",
@@ -75,7 +78,7 @@ func TestTodoistClient_CreateTask_NoDueTime(t *testing.T) {
 		httpClient: server.Client(),
 	}
 
-	err := client.CreateTask(TodoistTask{
+	_, err := client.CreateTask(TodoistTask{
 		Title:     "Company Holiday",
 		ProjectID: "12345",
 		Priority:  3,
@@ -89,6 +92,81 @@ func TestTodoistClient_CreateTask_NoDueTime(t *testing.T) {
 	}
 }
 
+func TestTodoistClient_CreateTask_DueDate(t *testing.T) {
+	var received todoistCreateTaskRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	due := time.Date(2026, 2, 13, 0, 0, 0, 0, time.Local)
+	if _, err := client.CreateTask(TodoistTask{Title: "Reply to Ada", DueDate: &due, DueString: "tomorrow"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if received.DueDate == nil || *received.DueDate != "2026-02-13" {
+		t.Errorf("due_date = %v, want 2026-02-13", received.DueDate)
+	}
+	if received.DueString != "" {
+		t.Errorf("due_string = %q, want empty when a due date is set", received.DueString)
+	}
+}
+
+func TestTodoistClient_RescheduleTask(t *testing.T) {
+	var paths []string
+	var bodies []map[string]any
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	due := time.Date(2026, 2, 10, 0, 0, 0, 0, time.Local)
+	at := time.Date(2026, 2, 10, 14, 30, 0, 0, time.Local)
+	moveTo := time.Date(2026, 2, 12, 0, 0, 0, 0, time.Local)
+
+	if err := client.RescheduleTask(TodoistTask{ID: "101", DueDate: &due}, moveTo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.RescheduleTask(TodoistTask{ID: "102", DueDate: &due, DueDateTime: &at}, moveTo); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(paths) != 2 || paths[0] != "POST /tasks/101" || paths[1] != "POST /tasks/102" {
+		t.Fatalf("requests = %v, want a POST per task", paths)
+	}
+	if len(bodies[0]) != 1 || bodies[0]["due_date"] != "2026-02-12" {
+		t.Errorf("date-only body = %v, want only due_date", bodies[0])
+	}
+	want := time.Date(2026, 2, 12, 14, 30, 0, 0, time.Local).Format(time.RFC3339)
+	if len(bodies[1]) != 1 || bodies[1]["due_datetime"] != want {
+		t.Errorf("timed body = %v, want only due_datetime %s", bodies[1], want)
+	}
+
+	err := client.RescheduleTask(TodoistTask{ID: "103", DueDate: &due, IsRecurring: true}, moveTo)
+	if err == nil || len(paths) != 2 {
+		t.Errorf("error = %v after %d requests, want recurring tasks refused without a request", err, len(paths))
+	}
+}
+
 func TestTodoistClient_APIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusForbidden)
@@ -102,7 +180,7 @@ func TestTodoistClient_APIError(t *testing.T) {
 		httpClient: server.Client(),
 	}
 
-	err := client.CreateTask(TodoistTask{
+	_, err := client.CreateTask(TodoistTask{
 		Title:     "Test",
 		ProjectID: "12345",
 		Priority:  3,
@@ -112,3 +190,356 @@ func TestTodoistClient_APIError(t *testing.T) {
 		t.Fatal("expected error for 403 response")
 	}
 }
+
+func TestTodoistClient_Tasks(t *testing.T) {
+	var receivedFilter string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tasks" {
+			t.Errorf("path = %q, want /tasks", r.URL.Path)
+		}
+		receivedFilter = r.URL.Query().Get("filter")
+		w.Write([]byte(`[
+			{
+				"id": "101",
+				"project_id": "p1",
+				"content": "Write report",
+				"description": "Quarterly numbers",
+				"priority": 4,
+				"labels": ["work"],
+				"created_at": "2026-01-10T08:00:00.000000Z",
+				"due": {"date": "2026-02-05", "is_recurring": false}
+			},
+			{
+				"id": "102",
+				"project_id": "p2",
+				"content": "Call dentist",
+				"priority": 1,
+				"created_at": "2026-02-01T08:00:00.000000Z",
+				"due": {"date": "2026-02-06", "datetime": "2026-02-06T09:30:00Z", "is_recurring": true}
+			},
+			{
+				"id": "103",
+				"project_id": "p2",
+				"content": "Someday",
+				"priority": 1,
+				"created_at": "2026-02-01T08:00:00.000000Z",
+				"due": null
+			}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	tasks, err := client.Tasks("overdue | today")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedFilter != "overdue | today" {
+		t.Errorf("filter = %q, want %q", receivedFilter, "overdue | today")
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("got %d tasks, want 3", len(tasks))
+	}
+
+	report := tasks[0]
+	if report.ID != "101" || report.Title != "Write report" || report.Priority != 4 {
+		t.Errorf("unexpected task: %+v", report)
+	}
+	if report.DueDate == nil || report.DueDate.Day() != 5 {
+		t.Errorf("DueDate = %v, want 2026-02-05", report.DueDate)
+	}
+	if report.DueDateTime != nil {
+		t.Error("DueDateTime should be nil for date-only due")
+	}
+	if report.CreatedAt.Month() != time.January {
+		t.Errorf("CreatedAt = %v, want January", report.CreatedAt)
+	}
+
+	dentist := tasks[1]
+	if dentist.DueDateTime == nil || dentist.DueDateTime.UTC().Hour() != 9 {
+		t.Errorf("DueDateTime = %v, want 09:30 UTC", dentist.DueDateTime)
+	}
+	if !dentist.IsRecurring {
+		t.Error("expected IsRecurring = true")
+	}
+
+	if tasks[2].DueDate != nil {
+		t.Error("DueDate should be nil for task without due date")
+	}
+}
+
+func TestTodoistClient_Projects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": "p1", "name": "Inbox", "is_inbox_project": true},
+			{"id": "p2", "name": "Work"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	projects, err := client.Projects()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("got %d projects, want 2", len(projects))
+	}
+	if !projects[0].IsInbox || projects[1].Name != "Work" {
+		t.Errorf("unexpected projects: %+v", projects)
+	}
+}
+
+func TestParseTodoistDatetime_Floating(t *testing.T) {
+	got, ok := parseTodoistDatetime("2026-02-06T14:00:00")
+	if !ok {
+		t.Fatal("expected floating datetime to parse")
+	}
+	if got.Hour() != 14 || got.Location() != time.Local {
+		t.Errorf("got %v, want 14:00 local", got)
+	}
+}
+
+func TestTodoistClient_CreateTask_ReturnsIDAndSubtaskFields(t *testing.T) {
+	var received todoistCreateTaskRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.Write([]byte(`{"id": "7001", "content": "Process inbox"}`))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	id, err := client.CreateTask(TodoistTask{
+		Title:     "Process inbox",
+		ParentID:  "7000",
+		DueString: "every friday",
+		Priority:  1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "7001" {
+		t.Errorf("id = %q, want %q", id, "7001")
+	}
+	if received.ParentID != "7000" {
+		t.Errorf("parent_id = %q, want %q", received.ParentID, "7000")
+	}
+	if received.DueString != "every friday" {
+		t.Errorf("due_string = %q, want %q", received.DueString, "every friday")
+	}
+}
+
+func TestTodoistClient_CompletedTasks(t *testing.T) {
+	var receivedSince, receivedAnnotate string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/completed/get_all" {
+			t.Errorf("path = %q, want /completed/get_all", r.URL.Path)
+		}
+		receivedSince = r.URL.Query().Get("since")
+		receivedAnnotate = r.URL.Query().Get("annotate_items")
+		w.Write([]byte(`{
+			"items": [
+				{"id": "c1", "task_id": "101", "content": "Ship release", "project_id": "p1", "completed_at": "2026-02-09T16:20:00.000000Z",
+				 "item_object": {"id": "101", "description": "sam-thread-id: t1"}}
+			],
+			"projects": {}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:    "test-token",
+		syncBaseURL: server.URL,
+		httpClient:  server.Client(),
+	}
+
+	tasks, err := client.CompletedTasks(time.Date(2026, 2, 9, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receivedSince != "2026-02-09T00:00:00" {
+		t.Errorf("since = %q, want %q", receivedSince, "2026-02-09T00:00:00")
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("got %d tasks, want 1", len(tasks))
+	}
+	if tasks[0].ID != "101" || tasks[0].Title != "Ship release" || tasks[0].CompletedAt.Hour() != 16 {
+		t.Errorf("unexpected completed task: %+v", tasks[0])
+	}
+	if receivedAnnotate != "true" || tasks[0].Description != "sam-thread-id: t1" {
+		t.Errorf("annotate_items = %q, description = %q, want the task description", receivedAnnotate, tasks[0].Description)
+	}
+}
+
+func TestTodoistClient_CompletedTasks_Pages(t *testing.T) {
+	var offsets []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+		count := todoistCompletedLimit
+		if offset != "0" {
+			count = 3
+		}
+		items := make([]string, count)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"task_id": "%s-%d", "content": "Done", "completed_at": "2026-02-09T16:20:00Z"}`, offset, i)
+		}
+		fmt.Fprintf(w, `{"items": [%s], "projects": {}}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:    "test-token",
+		syncBaseURL: server.URL,
+		httpClient:  server.Client(),
+	}
+
+	tasks, err := client.CompletedTasks(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tasks) != todoistCompletedLimit+3 {
+		t.Errorf("got %d tasks, want both pages", len(tasks))
+	}
+	if want := []string{"0", "200"}; !slices.Equal(offsets, want) {
+		t.Errorf("offsets = %q, want %q", offsets, want)
+	}
+}
+
+func TestTodoistClient_Reschedules(t *testing.T) {
+	var pages []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/activity/get" || r.URL.Query().Get("event_type") != "updated" {
+			t.Errorf("request = %s, want updated events from /activity/get", r.URL)
+		}
+		pages = append(pages, r.URL.Query().Get("page"))
+		if r.URL.Query().Get("page") != "0" {
+			w.Write([]byte(`{"events": [], "count": 0}`))
+			return
+		}
+		w.Write([]byte(`{"events": [
+			{"object_id": "101", "event_date": "2099-01-02T08:00:00Z", "extra_data": {"due_date": "2099-01-03T00:00:00Z", "last_due_date": "2099-01-02T00:00:00Z"}},
+			{"object_id": "101", "event_date": "2099-01-01T08:00:00Z", "extra_data": {"due_date": "2099-01-02T00:00:00Z", "last_due_date": "2099-01-01T00:00:00Z"}},
+			{"object_id": "102", "event_date": "2099-01-01T08:00:00Z", "extra_data": {"content": "Renamed"}},
+			{"object_id": "103", "event_date": "2099-01-01T08:00:00Z", "extra_data": {"due_date": "2099-01-01T00:00:00Z", "last_due_date": "2099-01-01T00:00:00Z"}},
+			{"object_id": "104", "event_date": "2000-01-01T08:00:00Z", "extra_data": {"due_date": "2000-01-02T00:00:00Z", "last_due_date": "2000-01-01T00:00:00Z"}}
+		], "count": 5}`))
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:    "test-token",
+		syncBaseURL: server.URL,
+		httpClient:  server.Client(),
+	}
+
+	counts, err := client.Reschedules(time.Now().Add(-10 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts) != 1 || counts["101"] != 2 {
+		t.Errorf("counts = %v, want only 101 moved twice", counts)
+	}
+	if strings.Join(pages, ",") != "0,1" {
+		t.Errorf("pages = %v, want the current and previous week", pages)
+	}
+}
+
+func TestTodoistClient_CloseTask(t *testing.T) {
+	var method, path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	if err := client.CloseTask("101"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != http.MethodPost || path != "/tasks/101/close" {
+		t.Errorf("request = %s %s, want POST /tasks/101/close", method, path)
+	}
+}
+
+func TestTodoistClient_Comments(t *testing.T) {
+	var posted []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/comments":
+			if r.URL.Query().Get("task_id") != "101" {
+				t.Errorf("task_id = %q, want 101", r.URL.Query().Get("task_id"))
+			}
+			w.Write([]byte(`[{"id": "c1", "task_id": "101", "content": "Agenda"}]`))
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			posted = append(posted, r.URL.Path+" "+string(body))
+			w.Write([]byte(`{"id": "c2", "task_id": "101", "content": "New"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &TodoistClient{
+		apiToken:   "test-token",
+		baseURL:    server.URL,
+		httpClient: server.Client(),
+	}
+
+	comments, err := client.Comments("101")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != "c1" || comments[0].Content != "Agenda" {
+		t.Errorf("unexpected comments: %+v", comments)
+	}
+
+	id, err := client.AddComment("101", "New")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != "c2" {
+		t.Errorf("id = %q, want c2", id)
+	}
+
+	if err := client.UpdateComment("c1", "Changed"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		`/comments {"task_id":"101","content":"New"}`,
+		`/comments/c1 {"content":"Changed"}`,
+	}
+	if len(posted) != 2 || posted[0] != want[0] || posted[1] != want[1] {
+		t.Errorf("posted = %q, want %q", posted, want)
+	}
+}